@@ -0,0 +1,40 @@
+package node
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gorilla/websocket"
+
+	"github.com/filecoin-project/venus/venus-shared/api/permission"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+// BlockstoreRPCPath is the websocket endpoint serving the blockstore of the node over NetRPC.
+const BlockstoreRPCPath = "/rpc/blockstore/v0"
+
+// blockstoreRPCHandler serves the blockstore over the NetRPC protocol, the callers need the read
+// permission, and the admin permission to write or delete objects.
+func (node *Node) blockstoreRPCHandler(ctx context.Context) http.Handler {
+	upgrader := websocket.Upgrader{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.HasPerm(r.Context(), permission.DefaultPerms, permission.PermRead) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		bs := node.blockstore.Blockstore
+		if !auth.HasPerm(r.Context(), permission.DefaultPerms, permission.PermAdmin) {
+			bs = blockstoreutil.NewReadOnlyBlockstore(bs)
+		}
+
+		wc, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Warnf("failed to upgrade blockstore rpc connection: %v", err)
+			return
+		}
+		// the connection outlives the request
+		blockstoreutil.HandleNetBstoreWS(ctx, bs, wc)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus-auth/jwtclient"
 	"github.com/filecoin-project/venus/app/submodule/dagservice"
 	"github.com/filecoin-project/venus/app/submodule/eth"
@@ -27,6 +28,7 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/wallet"
 	chain2 "github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/consensusfault"
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/paychmgr"
 	"github.com/filecoin-project/venus/pkg/repo"
@@ -50,6 +52,10 @@ type Builder struct {
 	genBlk         types.BlockHeader
 	walletPassword []byte
 	authURL        string
+	// fastSyncFrom is the trusted tipset a fast sync starts from, the headers are fetched
+	// fastSyncDepth epochs below it
+	fastSyncFrom  types.TipSetKey
+	fastSyncDepth abi.ChainEpoch
 }
 
 // New creates a new node.
@@ -121,6 +127,7 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.Network")
 	}
+	nd.chain.BlockFetcher = nd.network.Bitswap
 
 	nd.blockservice, err = dagservice.NewDagserviceSubmodule(ctx, (*builder)(b), nd.network)
 	if err != nil {
@@ -142,6 +149,10 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		return nil, errors.Wrap(err, "failed to build node.mpool")
 	}
 
+	if cfg := b.repo.Config().FaultReporter; nd.syncer.FaultDetector != nil && !cfg.ReportFrom.Empty() {
+		nd.syncer.FaultDetector.SetReporter(consensusfault.NewMessageReporter(cfg.ReportFrom, cfg.MaxFee, nd.chain.API(), nd.mpool.API()))
+	}
+
 	nd.storageNetworking, err = storagenetworking.NewStorgeNetworkingSubmodule(ctx, nd.network)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.storageNetworking")
@@ -160,7 +171,7 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 	nd.market = market.NewMarketModule(nd.chain.API(), nd.syncer.Stmgr)
 
 	blockDelay := b.repo.Config().NetworkParams.BlockDelay
-	nd.common = common.NewCommonModule(nd.chain, nd.network, blockDelay)
+	nd.common = common.NewCommonModule(nd.chain, nd.network, b.repo, blockDelay)
 
 	sqlitePath, err := b.repo.SqlitePath()
 	if err != nil {
@@ -3,10 +3,12 @@ package node
 import (
 	"time"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
+	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p"
 )
@@ -56,6 +58,11 @@ func (b builder) Journal() journal.Journal {
 	return b.journal
 }
 
+// FastSync get the trusted tipset and the depth of the fast sync, the tipset key is empty without fast sync
+func (b builder) FastSync() (types.TipSetKey, abi.ChainEpoch) {
+	return b.fastSyncFrom, b.fastSyncDepth
+}
+
 // Libp2pOpts get libp2p option
 func (b builder) Libp2pOpts() []libp2p.Option {
 	return b.libp2pOpts
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
 	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/libp2p/go-libp2p"
 )
 
@@ -93,6 +94,16 @@ func ChainClockConfigOption(clk clock.ChainEpochClock) BuilderOpt {
 	}
 }
 
+// FastSyncOption returns a function that makes the node fast sync from the trusted tipset tsk:
+// only the headers are fetched, down to depth epochs below tsk or to the genesis if depth is 0.
+func FastSyncOption(tsk types.TipSetKey, depth abi.ChainEpoch) BuilderOpt {
+	return func(c *Builder) error {
+		c.fastSyncFrom = tsk
+		c.fastSyncDepth = depth
+		return nil
+	}
+}
+
 // JournalConfigOption returns a function that sets the journal to use in the node.
 func JournalConfigOption(jrl journal.Journal) BuilderOpt {
 	return func(c *Builder) error {
@@ -26,6 +26,9 @@ type Env struct {
 	PaychAPI  v1api.IPaychan
 	CommonAPI v1api.ICommon
 	EthAPI    v1api.IETH
+
+	// closer releases the resources of a local environment
+	closer func() error
 }
 
 var _ cmds.Environment = (*Env)(nil)
@@ -36,6 +39,14 @@ func NewClientEnv(ctx context.Context) *Env {
 	return &Env{ctx: ctx}
 }
 
+// Close releases the resources of the environment.
+func (ce *Env) Close() error {
+	if ce.closer == nil {
+		return nil
+	}
+	return ce.closer()
+}
+
 // Context returns the context of the environment.
 func (ce *Env) Context() context.Context {
 	return ce.ctx
@@ -266,6 +266,7 @@ func (node *Node) RunRPCAndWait(ctx context.Context, rootCmdDaemon *cmds.Command
 	if err != nil {
 		return err
 	}
+	mux.Handle(BlockstoreRPCPath, node.blockstoreRPCHandler(ctx))
 
 	localVerifer, token, err := jwtclient.NewLocalAuthClient()
 	if err != nil {
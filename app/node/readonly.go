@@ -0,0 +1,111 @@
+package node
+
+import (
+	"context"
+
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	chain2 "github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/journal"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/statemanger"
+	"github.com/filecoin-project/venus/pkg/util/ffiwrapper/impl"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+)
+
+// NewReadonlyEnv returns an environment serving the chain and state APIs from the repo at
+// repoPath opened read-only, so the inspection commands can run against the repo of a stopped
+// node or a copy of it. Only the ChainAPI is set, the environment must be closed after use.
+func NewReadonlyEnv(ctx context.Context, repoPath string) (*Env, error) {
+	r, err := repo.OpenFSRepoReadOnly(repoPath, repo.LatestVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open repo")
+	}
+
+	chn, err := newReadonlyChain(ctx, r)
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+
+	return &Env{
+		ctx:      ctx,
+		ChainAPI: chn.API(),
+		closer: func() error {
+			chn.Stop(ctx)
+			return r.Close()
+		},
+	}, nil
+}
+
+// newReadonlyChain builds the chain submodule and the state manager without the network.
+func newReadonlyChain(ctx context.Context, r repo.Repo) (*chain.ChainSubmodule, error) {
+	cfg := r.Config()
+	// the message index is written on head changes
+	if cfg.Index != nil {
+		cfg.Index.EnableMsgIndex = false
+	}
+
+	b := &Builder{
+		blockTime:   clock.DefaultEpochDuration,
+		offlineMode: true,
+		verifier:    impl.ProofVerifier,
+		repo:        r,
+		journal:     journal.NewNoopJournal(),
+	}
+	genBlk, err := chain2.GenesisBlock(ctx, r.ChainDatastore(), r.Datastore())
+	if err != nil {
+		return nil, err
+	}
+	b.genBlk = genBlk
+
+	circulatingSupplyCalculator := chain2.NewCirculatingSupplyCalculator(r.Datastore(), genBlk.ParentStateRoot, cfg.NetworkParams.ForkUpgradeParam)
+	chn, err := chain.NewChainSubmodule(ctx, (*builder)(b), circulatingSupplyCalculator)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build chain")
+	}
+
+	gasPriceSchedule := gas.NewPricesSchedule(cfg.NetworkParams.ForkUpgradeParam)
+	cborStore := cbor.NewCborStore(r.Datastore())
+	stateViewer := consensus.AsDefaultStateViewer(state.NewViewer(cborStore))
+	blkValid := consensus.NewBlockValidator(consensus.NewTicketMachine(chn.ChainReader),
+		r.Datastore(),
+		chn.MessageStore,
+		chn.Drand,
+		cborStore,
+		b.verifier,
+		&stateViewer,
+		chn.ChainReader,
+		consensus.NewChainSelector(cborStore, &stateViewer),
+		chn.Fork,
+		cfg.NetworkParams,
+		gasPriceSchedule)
+
+	rnd := chn.API()
+	nodeConsensus := consensus.NewExpected(cborStore,
+		r.Datastore(),
+		chn.ChainReader,
+		rnd,
+		chn.MessageStore,
+		chn.Fork,
+		gasPriceSchedule,
+		blkValid,
+		chn.SystemCall,
+		circulatingSupplyCalculator,
+		cfg.NetworkParams,
+		cfg.FevmConfig.EnableEthRPC,
+	)
+
+	stmgr := statemanger.NewStateManger(chn.ChainReader, chn.MessageStore, nodeConsensus, rnd,
+		chn.Fork, gasPriceSchedule, chn.SystemCall, cfg.NetworkParams.ActorDebugging)
+	blkValid.Stmgr = stmgr
+	chn.Stmgr = stmgr
+	chn.Waiter.Stmgr = stmgr
+
+	return chn, nil
+}
@@ -0,0 +1,43 @@
+package node_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	gengen "github.com/filecoin-project/venus/tools/gengen/util"
+)
+
+func TestReadonlyEnv(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, repo.InitFSRepo(repoPath, repo.LatestVersion, config.NewDefaultConfig()))
+
+	r, err := repo.OpenFSRepo(repoPath, repo.LatestVersion)
+	require.NoError(t, err)
+	require.NoError(t, node.Init(ctx, r, gengen.MakeGenesisFunc(gengen.NetworkName("gfctest"))))
+	require.NoError(t, r.Close())
+
+	env, err := node.NewReadonlyEnv(ctx, repoPath)
+	require.NoError(t, err)
+	head, err := env.ChainAPI.ChainHead(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, head.Height())
+	_, err = env.ChainAPI.StateGetActor(ctx, builtin.SystemActorAddr, head.Key())
+	require.NoError(t, err)
+	require.NoError(t, env.Close())
+
+	// the repo is still usable after being opened read-only
+	r, err = repo.OpenFSRepo(repoPath, repo.LatestVersion)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+}
@@ -2,6 +2,7 @@ package chain
 
 import (
 	"context"
+	"path/filepath"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -10,9 +11,12 @@ import (
 	apiwrapper "github.com/filecoin-project/venus/app/submodule/chain/v0api"
 	"github.com/filecoin-project/venus/pkg/beacon"
 	"github.com/filecoin-project/venus/pkg/chain"
+	pconfig "github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/consensusfault"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/journal"
+	"github.com/filecoin-project/venus/pkg/msgindex"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/statemanger"
 	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
@@ -20,6 +24,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/vmsupport"
 	v0api "github.com/filecoin-project/venus/venus-shared/api/chain/v0"
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
 
@@ -39,6 +44,13 @@ type ChainSubmodule struct { //nolint
 	Stmgr *statemanger.Stmgr
 	// Wait for confirm message
 	Waiter *chain.Waiter
+	// BlockFetcher fetches the objects repaired by ChainCheck from the network
+	BlockFetcher chain.BlockFetcher
+
+	// compactor is only set when the blockstore is a hot/cold split store
+	compactor *chain.SplitStoreCompactor
+	// msgIndex is only set when the message index is enabled
+	msgIndex *msgindex.MsgIndex
 }
 
 type chainConfig interface {
@@ -46,6 +58,7 @@ type chainConfig interface {
 	BlockTime() time.Duration
 	Repo() repo.Repo
 	Verifier() ffiwrapper.Verifier
+	Journal() journal.Journal
 }
 
 // NewChainSubmodule creates a new chain submodule.
@@ -56,6 +69,7 @@ func NewChainSubmodule(ctx context.Context,
 	repo := config.Repo()
 	// initialize chain store
 	chainStore := chain.NewStore(repo.ChainDatastore(), repo.Datastore(), config.GenesisCid(), circulatiingSupplyCalculator)
+	chainStore.SetJournal(config.Journal())
 	// drand
 	genBlk, err := chainStore.GetGenesisBlock(context.TODO())
 	if err != nil {
@@ -89,10 +103,34 @@ func NewChainSubmodule(ctx context.Context,
 		Waiter:       waiter,
 		CheckPoint:   chainStore.GetCheckPoint(),
 	}
+	if ss, ok := repo.Datastore().(*blockstoreutil.SplitStore); ok {
+		cfg := repo.Config().Datastore.SplitStore
+		if cfg == nil {
+			cfg = pconfig.NewDefaultSplitStoreConfig()
+		}
+		store.compactor = chain.NewSplitStoreCompactor(chainStore, ss, cfg.HotStoreFinalities)
+	}
+	if cfg := repo.Config().Index; cfg != nil && cfg.EnableMsgIndex {
+		sqlitePath, err := repo.SqlitePath()
+		if err != nil {
+			return nil, err
+		}
+		store.msgIndex, err = msgindex.NewMsgIndex(filepath.Join(sqlitePath, "msgindex.db"), chainStore, messageStore)
+		if err != nil {
+			return nil, err
+		}
+		waiter.SetMessageIndex(store.msgIndex)
+	}
 	err = store.ChainReader.Load(context.TODO())
 	if err != nil {
 		return nil, err
 	}
+	if store.compactor != nil {
+		store.compactor.Start()
+	}
+	if store.msgIndex != nil {
+		chainStore.SubscribeHeadChanges(store.msgIndex.HeadChange)
+	}
 	return store, nil
 }
 
@@ -103,7 +141,15 @@ func (chain *ChainSubmodule) Start(ctx context.Context) error {
 
 // Stop stop the chain head event
 func (chain *ChainSubmodule) Stop(ctx context.Context) {
+	if chain.compactor != nil {
+		chain.compactor.Stop()
+	}
 	chain.ChainReader.Stop()
+	if chain.msgIndex != nil {
+		if err := chain.msgIndex.Close(); err != nil {
+			log.Warnf("failed to close message index: %v", err)
+		}
+	}
 }
 
 // API chain module api implement
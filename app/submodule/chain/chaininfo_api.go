@@ -28,6 +28,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/fork"
 	"github.com/filecoin-project/venus/pkg/statemanger"
 	"github.com/filecoin-project/venus/venus-shared/actors"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/filecoin-project/venus/venus-shared/utils"
@@ -570,12 +571,39 @@ func (cia *chainInfoAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch,
 	if err != nil {
 		return nil, fmt.Errorf("loading tipset %s: %v", tsk, err)
 	}
+	return exportStream(ctx, func(w io.Writer) error {
+		return cia.chain.ChainReader.Export(ctx, ts, nroots, skipoldmsgs, w)
+	}), nil
+}
+
+// ChainExportDiff streams a differential snapshot holding the objects reachable from the chain
+// of tsk above base which are not reachable from base
+func (cia *chainInfoAPI) ChainExportDiff(ctx context.Context, base, tsk types.TipSetKey) (<-chan []byte, error) {
+	baseTS, err := cia.chain.ChainReader.GetTipSet(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("loading base tipset %s: %v", base, err)
+	}
+	ts, err := cia.chain.ChainReader.GetTipSet(ctx, tsk)
+	if err != nil {
+		return nil, fmt.Errorf("loading tipset %s: %v", tsk, err)
+	}
+	if baseTS.Height() >= ts.Height() {
+		return nil, fmt.Errorf("base tipset %d must be below the exported tipset %d", baseTS.Height(), ts.Height())
+	}
+
+	return exportStream(ctx, func(w io.Writer) error {
+		return cia.chain.ChainReader.ExportDiff(ctx, baseTS, ts, w)
+	}), nil
+}
+
+// exportStream streams the output of export in chunks, an empty chunk is sent once export succeeded
+func exportStream(ctx context.Context, export func(w io.Writer) error) <-chan []byte {
 	r, w := io.Pipe()
 	out := make(chan []byte)
 	go func() {
 		bw := bufio.NewWriterSize(w, 1<<20)
 
-		err := cia.chain.ChainReader.Export(ctx, ts, nroots, skipoldmsgs, bw)
+		err := export(bw)
 		bw.Flush()            //nolint:errcheck // it is a write to a pipe
 		w.CloseWithError(err) //nolint:errcheck // it is a pipe
 	}()
@@ -611,9 +639,129 @@ func (cia *chainInfoAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch,
 		}
 	}()
 
+	return out
+}
+
+// ChainPrune deletes from the blockstore the state older than the last opts.RetainState epochs which
+// is not reachable from the head, keeping all the headers and messages.
+func (cia *chainInfoAPI) ChainPrune(ctx context.Context, opts types.ChainPruneOpts) (<-chan types.ChainPruneProgress, error) {
+	if opts.RetainState < policy.ChainFinality {
+		return nil, fmt.Errorf("retained state must be at least %d epochs", policy.ChainFinality)
+	}
+
+	head := cia.chain.ChainReader.GetHead()
+	out := make(chan types.ChainPruneProgress, 16)
+	go func() {
+		defer close(out)
+
+		send := func(p types.ChainPruneProgress) {
+			select {
+			case out <- p:
+			case <-ctx.Done():
+			}
+		}
+
+		var last types.ChainPruneProgress
+		err := cia.chain.ChainReader.Prune(ctx, head, opts, func(p types.ChainPruneProgress) {
+			last = p
+			send(p)
+		})
+		if err != nil {
+			log.Errorf("chain prune failed: %v", err)
+			last.Err = err.Error()
+			last.Done = true
+			send(last)
+		}
+	}()
+
+	return out, nil
+}
+
+// ChainBackfillMsgIndex indexes the messages of the canonical chain from the epoch from down to the epoch to
+func (cia *chainInfoAPI) ChainBackfillMsgIndex(ctx context.Context, from, to abi.ChainEpoch) (int, error) {
+	if cia.chain.msgIndex == nil {
+		return 0, fmt.Errorf("message index is disabled, enable it with Index.EnableMsgIndex")
+	}
+	if from < to {
+		return 0, fmt.Errorf("from %d is lower than to %d", from, to)
+	}
+
+	head := cia.chain.ChainReader.GetHead()
+	if from > head.Height() {
+		from = head.Height()
+	}
+	ts, err := cia.chain.ChainReader.GetTipSetByHeight(ctx, head, from, true)
+	if err != nil {
+		return 0, fmt.Errorf("loading tipset at %d: %w", from, err)
+	}
+
+	return cia.chain.msgIndex.Backfill(ctx, ts, to)
+}
+
+// ChainCheck verifies that the objects referenced by the chain of tsk in the last opts.Epochs epochs are intact
+func (cia *chainInfoAPI) ChainCheck(ctx context.Context, tsk types.TipSetKey, opts types.ChainCheckOpts) (*types.ChainCheckResult, error) {
+	if opts.Epochs < 0 {
+		return nil, fmt.Errorf("epochs must not be negative")
+	}
+
+	var fetcher chain.BlockFetcher
+	if opts.Repair {
+		if cia.chain.BlockFetcher == nil {
+			return nil, fmt.Errorf("no block fetcher to repair the blockstore")
+		}
+		fetcher = cia.chain.BlockFetcher
+	}
+
+	ts, err := cia.chain.ChainReader.GetTipSet(ctx, tsk)
+	if err != nil {
+		return nil, fmt.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	return cia.chain.ChainReader.CheckChain(ctx, ts, opts.Epochs, fetcher)
+}
+
+// ChainVerifyRange re-executes the tipsets of the canonical chain between the epochs and compares the results with the chain
+func (cia *chainInfoAPI) ChainVerifyRange(ctx context.Context, opts types.ChainVerifyRangeOpts) (<-chan types.ChainVerifyRangeProgress, error) {
+	head := cia.chain.ChainReader.GetHead()
+	if opts.From < 0 || opts.From > opts.To {
+		return nil, fmt.Errorf("invalid range from %d to %d", opts.From, opts.To)
+	}
+	if opts.To >= head.Height() {
+		return nil, fmt.Errorf("to must be lower than the head height %d", head.Height())
+	}
+
+	total := int(opts.To - opts.From + 1)
+	out := make(chan types.ChainVerifyRangeProgress, 16)
+	go func() {
+		defer close(out)
+
+		send := func(p types.ChainVerifyRangeProgress) {
+			select {
+			case out <- p:
+			case <-ctx.Done():
+			}
+		}
+
+		var verified int
+		div, err := cia.chain.Stmgr.VerifyRange(ctx, head, opts.From, opts.To, opts.Workers, func(n int) {
+			verified = n
+			send(types.ChainVerifyRangeProgress{Verified: n, Total: total})
+		})
+		last := types.ChainVerifyRangeProgress{Verified: verified, Total: total, Divergence: div, Done: true}
+		if err != nil {
+			log.Errorf("chain verify range failed: %v", err)
+			last.Err = err.Error()
+		}
+		send(last)
+	}()
+
 	return out, nil
 }
 
+// ChainReorgHistory returns the last limit reorgs of the chain recorded by the node, the latest first
+func (cia *chainInfoAPI) ChainReorgHistory(ctx context.Context, limit int) ([]*types.ReorgEvent, error) {
+	return cia.chain.ChainReader.ReorgHistory(ctx, limit)
+}
+
 // ChainGetPath returns a set of revert/apply operations needed to get from
 // one tipset to another, for example:
 // ```
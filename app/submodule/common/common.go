@@ -2,6 +2,8 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -11,6 +13,7 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/network"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/net"
+	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/venus-shared/api/chain"
 	v0api "github.com/filecoin-project/venus/venus-shared/api/chain/v0"
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
@@ -22,14 +25,16 @@ var _ v1api.ICommon = (*CommonModule)(nil)
 type CommonModule struct { // nolint
 	chainModule    *chain2.ChainSubmodule
 	netModule      *network.NetworkSubmodule
+	repo           repo.Repo
 	blockDelaySecs uint64
 	start          time.Time
 }
 
-func NewCommonModule(chainModule *chain2.ChainSubmodule, netModule *network.NetworkSubmodule, blockDelaySecs uint64) *CommonModule {
+func NewCommonModule(chainModule *chain2.ChainSubmodule, netModule *network.NetworkSubmodule, r repo.Repo, blockDelaySecs uint64) *CommonModule {
 	return &CommonModule{
 		chainModule:    chainModule,
 		netModule:      netModule,
+		repo:           r,
 		blockDelaySecs: blockDelaySecs,
 		start:          time.Now(),
 	}
@@ -119,6 +124,17 @@ func (cm *CommonModule) StartTime(ctx context.Context) (time.Time, error) {
 	return cm.start, nil
 }
 
+func (cm *CommonModule) CreateBackup(ctx context.Context, fpath string) error {
+	if !filepath.IsAbs(fpath) {
+		return fmt.Errorf("backup path must be absolute: %s", fpath)
+	}
+	return repo.BackupToFile(ctx, cm.repo, fpath)
+}
+
+func (cm *CommonModule) ConvertBlockstore(ctx context.Context, engine string) error {
+	return repo.ConvertDatastore(ctx, cm.repo, engine)
+}
+
 func (cm *CommonModule) API() v1api.ICommon {
 	return cm
 }
@@ -51,6 +51,9 @@ func (a *MessagePoolAPI) MpoolGetConfig(context.Context) (*types.MpoolConfig, er
 		ReplaceByFeeRatio:      cfg.ReplaceByFeeRatio,
 		PruneCooldown:          cfg.PruneCooldown,
 		GasLimitOverestimation: cfg.GasLimitOverestimation,
+		Policy:                 cfg.Policy,
+		FeeBump:                cfg.FeeBump,
+		GasPremiumEstimator:    cfg.GasPremiumEstimator,
 	}, nil
 }
 
@@ -63,6 +66,9 @@ func (a *MessagePoolAPI) MpoolSetConfig(ctx context.Context, cfg *types.MpoolCon
 		ReplaceByFeeRatio:      cfg.ReplaceByFeeRatio,
 		PruneCooldown:          cfg.PruneCooldown,
 		GasLimitOverestimation: cfg.GasLimitOverestimation,
+		Policy:                 cfg.Policy,
+		FeeBump:                cfg.FeeBump,
+		GasPremiumEstimator:    cfg.GasPremiumEstimator,
 	})
 }
 
@@ -76,6 +82,17 @@ func (a *MessagePoolAPI) MpoolSelect(ctx context.Context, tsk types.TipSetKey, t
 	return a.mp.MPool.SelectMessages(ctx, ts, ticketQuality)
 }
 
+// MpoolSelectExplain runs the message selection of MpoolSelect, and explains for each pending message whether it
+// was selected or why not, with the gas performance and the effective performance of its chain
+func (a *MessagePoolAPI) MpoolSelectExplain(ctx context.Context, tsk types.TipSetKey, ticketQuality float64) (*types.MpoolSelectExplain, error) {
+	ts, err := a.mp.chain.API().ChainGetTipSet(ctx, tsk)
+	if err != nil {
+		return nil, fmt.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	return a.mp.MPool.SelectMessagesExplain(ctx, ts, ticketQuality)
+}
+
 // MpoolSelects The batch selection message is used when multiple blocks need to select messages at the same time
 func (a *MessagePoolAPI) MpoolSelects(ctx context.Context, tsk types.TipSetKey, ticketQualitys []float64) ([][]*types.SignedMessage, error) {
 	ts, err := a.mp.chain.API().ChainGetTipSet(ctx, tsk)
@@ -314,6 +331,17 @@ func (a *MessagePoolAPI) GasEstimateGasPremium(ctx context.Context, nblocksincl
 	return a.mp.MPool.GasEstimateGasPremium(ctx, nblocksincl, sender, gaslimit, tsk, a.mp.MPool.PriceCache)
 }
 
+// GasBacktestPremium replays the last epochs tipsets with each of the gas premium estimators, and reports how
+// long the premiums estimated for an inclusion within nblocksincl epochs waited and overpaid. epochs is capped
+// to three finalities and nblocksincl to a tenth of a finality.
+func (a *MessagePoolAPI) GasBacktestPremium(ctx context.Context, estimators []string, nblocksincl uint64, epochs int) ([]*types.GasBacktestResult, error) {
+	ts, err := a.mp.chain.API().ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.mp.MPool.BacktestGasPremium(ctx, ts, estimators, nblocksincl, epochs)
+}
+
 func (a *MessagePoolAPI) MpoolCheckMessages(ctx context.Context, protos []*types.MessagePrototype) ([][]types.MessageCheckStatus, error) {
 	return a.mp.MPool.CheckMessages(ctx, protos)
 }
@@ -325,3 +353,71 @@ func (a *MessagePoolAPI) MpoolCheckPendingMessages(ctx context.Context, addr add
 func (a *MessagePoolAPI) MpoolCheckReplaceMessages(ctx context.Context, msg []*types.Message) ([][]types.MessageCheckStatus, error) {
 	return a.mp.MPool.CheckReplaceMessages(ctx, msg)
 }
+
+// MpoolNonceGaps compares the nonces of an address in the state, the message pool, the local messages and the message signer
+func (a *MessagePoolAPI) MpoolNonceGaps(ctx context.Context, addr address.Address) (*types.NonceGapReport, error) {
+	fromA, err := a.mp.chain.API().StateAccountKey(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("getting key address: %w", err)
+	}
+
+	return a.nonceGaps(ctx, fromA)
+}
+
+// MpoolFixNonceGaps fills the nonce gaps of an address with zero value self-sends if fill is set, and drops the
+// messages stranded behind the gaps and the stale local messages if drop is set
+func (a *MessagePoolAPI) MpoolFixNonceGaps(ctx context.Context, addr address.Address, fill, drop bool) (*types.NonceGapFix, error) {
+	fromA, err := a.mp.chain.API().StateAccountKey(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("getting key address: %w", err)
+	}
+	// the pushed messages must not take the nonces being fixed
+	done, err := a.pushLocks.TakeLock(ctx, fromA)
+	if err != nil {
+		return nil, fmt.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	report, err := a.nonceGaps(ctx, fromA)
+	if err != nil {
+		return nil, err
+	}
+	fix := &types.NonceGapFix{
+		Report:  report,
+		Filled:  []cid.Cid{},
+		Dropped: []cid.Cid{},
+	}
+
+	if fill && len(report.Gaps) > 0 {
+		filled, err := a.mp.MPool.FillNonceGaps(ctx, fromA, report.Gaps, a.mp.walletAPI)
+		if err != nil {
+			return nil, fmt.Errorf("filled %d of %d gaps: %w", len(filled), len(report.Gaps), err)
+		}
+		for _, m := range filled {
+			fix.Filled = append(fix.Filled, m.Cid())
+		}
+	}
+
+	if drop {
+		dropped, err := a.mp.MPool.DropNonceOrphans(ctx, fromA)
+		if err != nil {
+			return nil, err
+		}
+		fix.Dropped = append(fix.Dropped, dropped...)
+		// the signer would assign the nonces of the dropped messages again after a gap
+		if err := a.mp.msgSigner.ResetNonce(ctx, fromA); err != nil {
+			return nil, err
+		}
+	}
+
+	return fix, nil
+}
+
+func (a *MessagePoolAPI) nonceGaps(ctx context.Context, fromA address.Address) (*types.NonceGapReport, error) {
+	signerNonce, err := a.mp.msgSigner.NextNonce(ctx, fromA)
+	if err != nil {
+		return nil, fmt.Errorf("getting signer nonce: %w", err)
+	}
+
+	return a.mp.MPool.NonceGaps(ctx, fromA, signerNonce)
+}
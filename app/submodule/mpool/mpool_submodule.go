@@ -138,6 +138,9 @@ func (mp *MessagePoolSubmodule) Validate(ctx context.Context, pid peer.ID, msg *
 			fallthrough
 		case errors.Is(err, messagepool.ErrNonceTooLow):
 			return pubsub.ValidationIgnore
+		case messagepool.IsPolicyError(err):
+			// the policy is local to the node, the peer isn't at fault
+			return pubsub.ValidationIgnore
 		default:
 			return pubsub.ValidationReject
 		}
@@ -201,6 +204,9 @@ func (mp *MessagePoolSubmodule) Start(ctx context.Context) error {
 	// wait until we are synced within 10 epochs
 	go mp.waitForSync(pubsubMsgsSyncEpochs, subscribe)
 
+	// replace the stuck local messages once the fee bump is configured
+	go messagepool.NewFeeBumper(mp.MPool, mp.walletAPI).Run(ctx)
+
 	return nil
 }
 
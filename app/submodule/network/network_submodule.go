@@ -49,6 +49,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/net/peermgr"
 	"github.com/filecoin-project/venus/pkg/repo"
 	appstate "github.com/filecoin-project/venus/pkg/state"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
 	"github.com/filecoin-project/venus/venus-shared/types"
 
 	v0api "github.com/filecoin-project/venus/venus-shared/api/chain/v0"
@@ -172,14 +173,25 @@ func NewNetworkSubmodule(ctx context.Context, chainStore *chain.Store,
 		return nil, errors.Wrap(err, "failed to set up network")
 	}
 
+	// the objects missing from a lazy blockstore are fetched with bitswap, the peers are only
+	// served the objects of the local store
+	bs := config.Repo().Datastore()
+	lazyStore, lazy := bs.(*blockstoreutil.LazyStore)
+	if lazy {
+		bs = lazyStore.Local()
+	}
+
 	// set up bitswap
 	nwork := bsnet.NewFromIpfsHost(peerHost, router, bsnet.Prefix("/chain"))
 	bitswapOptions := []bitswap.Option{bitswap.ProvideEnabled(false)}
-	bswap := bitswap.New(ctx, nwork, config.Repo().Datastore(), bitswapOptions...)
+	bswap := bitswap.New(ctx, nwork, bs, bitswapOptions...)
+	if lazy {
+		lazyStore.SetGetter(bswap)
+	}
 
 	// set up graphsync
 	graphsyncNetwork := gsnet.NewFromLibp2pHost(peerHost)
-	lsys := storeutil.LinkSystemForBlockstore(config.Repo().Datastore())
+	lsys := storeutil.LinkSystemForBlockstore(bs)
 	gsync := graphsyncimpl.New(ctx, graphsyncNetwork, lsys, graphsyncimpl.RejectAllRequestsByDefault())
 
 	// dataTransger
@@ -6,11 +6,14 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	"github.com/filecoin-project/venus/pkg/fvm"
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 )
 
@@ -43,8 +46,9 @@ func (sa *syncerAPI) SyncerTracker(ctx context.Context) *types.TargetTracker {
 	for _, target := range tracker.History() {
 		tt.History = append(tt.History, convertTarget(target))
 	}
-	for _, target := range tracker.Buckets() {
-		tt.Buckets = append(tt.Buckets, convertTarget(target))
+	buckets := tracker.Buckets()
+	for i := range buckets {
+		tt.Buckets = append(tt.Buckets, convertTarget(&buckets[i]))
 	}
 
 	return tt
@@ -66,6 +70,51 @@ func convertSyncStateStage(srtState syncTypes.SyncStateStage) types.SyncStateSta
 	return state
 }
 
+// SyncTargets returns the candidate heads of the syncer, the heaviest first, followed by the targets whose
+// sync failed recently. Each target is compared to the current head by the fork-choice rule.
+func (sa *syncerAPI) SyncTargets(ctx context.Context) ([]*types.SyncTarget, error) {
+	tracker := sa.syncer.ChainSyncManager.BlockProposer().SyncTracker()
+	head := sa.syncer.ChainModule.ChainReader.GetHead()
+
+	targets := tracker.Buckets()
+	for _, t := range tracker.History() {
+		if t.State == syncTypes.StageSyncErrored {
+			targets = append(targets, *t)
+		}
+	}
+
+	out := make([]*types.SyncTarget, 0, len(targets))
+	for _, t := range targets {
+		st := &types.SyncTarget{
+			Head:         t.Head.Key(),
+			Height:       t.Head.Height(),
+			ParentWeight: t.Head.ParentWeight(),
+			Weight:       big.Zero(),
+			Source:       t.Source,
+			Sender:       t.Sender,
+			Stage:        convertSyncStateStage(t.State),
+		}
+		if t.Err != nil {
+			st.Err = t.Err.Error()
+		}
+		st.BadReason, _ = sa.syncer.BadTipSets.Reason(st.Head)
+
+		weight, err := sa.syncer.ChainSelector.Weight(ctx, t.Head)
+		if err != nil {
+			st.Reason = fmt.Sprintf("weight unknown until the parent state is synced: %v", err)
+		} else {
+			st.Weight = weight
+			st.Heavier, st.Reason, err = sa.syncer.ChainSelector.ExplainHeavier(ctx, t.Head, head)
+			if err != nil {
+				return nil, fmt.Errorf("comparing %s to the head %s: %w", st.Head, head.Key(), err)
+			}
+		}
+		out = append(out, st)
+	}
+
+	return out, nil
+}
+
 // SetConcurrent set the syncer worker(go-routine) number of chain syncing
 func (sa *syncerAPI) SetConcurrent(ctx context.Context, concurrent int64) error {
 	sa.syncer.ChainSyncManager.BlockProposer().SetConcurrent(concurrent)
@@ -190,9 +239,10 @@ func (sa *syncerAPI) SyncState(ctx context.Context) (*types.SyncState, error) {
 		return activeSync
 	}
 	// current
-	for _, t := range tracker.Buckets() {
-		if t.State != syncTypes.StageSyncErrored {
-			syncState.ActiveSyncs = append(syncState.ActiveSyncs, toActiveSync(t))
+	buckets := tracker.Buckets()
+	for i := range buckets {
+		if buckets[i].State != syncTypes.StageSyncErrored {
+			syncState.ActiveSyncs = append(syncState.ActiveSyncs, toActiveSync(&buckets[i]))
 		}
 	}
 	// history
@@ -204,3 +254,84 @@ func (sa *syncerAPI) SyncState(ctx context.Context) (*types.SyncState, error) {
 
 	return syncState, nil
 }
+
+// SyncMarkBad marks a tipset bad for the given reason, the syncer refuses to sync to it and to its descendants.
+func (sa *syncerAPI) SyncMarkBad(ctx context.Context, tsk types.TipSetKey, reason string) error {
+	if tsk.IsEmpty() {
+		return fmt.Errorf("empty tipset key")
+	}
+	if reason == "" {
+		return fmt.Errorf("a reason is required to mark a tipset bad")
+	}
+	syncAPILog.Warnf("marking tipset %s bad: %s", tsk, reason)
+	return sa.syncer.BadTipSets.Add(ctx, tsk, reason)
+}
+
+// SyncUnmarkBad removes a tipset from the bad tipsets.
+func (sa *syncerAPI) SyncUnmarkBad(ctx context.Context, tsk types.TipSetKey) error {
+	if !sa.syncer.BadTipSets.Has(tsk) {
+		return fmt.Errorf("tipset %s isn't marked bad", tsk)
+	}
+	syncAPILog.Warnf("unmarking bad tipset %s", tsk)
+	return sa.syncer.BadTipSets.Remove(ctx, tsk)
+}
+
+// SyncCheckBad returns the reason a tipset was marked bad, or an empty string if it isn't bad.
+func (sa *syncerAPI) SyncCheckBad(ctx context.Context, tsk types.TipSetKey) (string, error) {
+	reason, _ := sa.syncer.BadTipSets.Reason(tsk)
+	return reason, nil
+}
+
+// SyncCheckpoint pins a tipset as final: the syncer refuses any chain that doesn't include it,
+// and the head is switched to it if it isn't in the chain of the current head.
+func (sa *syncerAPI) SyncCheckpoint(ctx context.Context, tsk types.TipSetKey) error {
+	syncAPILog.Warnf("setting checkpoint %s", tsk)
+	return sa.syncer.ChainSyncManager.SyncCheckpoint(ctx, tsk)
+}
+
+// SyncValidateTipset runs the checks of the syncer on a tipset and its parent state transition and
+// reports the result, without changing the head or the bad tipsets.
+func (sa *syncerAPI) SyncValidateTipset(ctx context.Context, tsk types.TipSetKey) (*types.TipSetValidation, error) {
+	ts, err := sa.syncer.ChainModule.ChainReader.GetTipSet(ctx, tsk)
+	if err != nil {
+		return nil, fmt.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	if ts.Height() == 0 {
+		return nil, fmt.Errorf("the genesis tipset can't be validated")
+	}
+	return sa.syncer.BlockValidator.ValidateTipSet(ctx, ts)
+}
+
+// SyncConsensusFaults returns the consensus faults found in the block headers received from the network, most recent first.
+func (sa *syncerAPI) SyncConsensusFaults(ctx context.Context) ([]*types.ConsensusFault, error) {
+	if sa.syncer.FaultDetector == nil {
+		return nil, fmt.Errorf("the consensus fault detector is disabled, set faultReporter.enableDetector in the config")
+	}
+	return sa.syncer.FaultDetector.Faults(), nil
+}
+
+// SlashFilterListBlocks lists the mined blocks recorded by the slash filter
+func (sa *syncerAPI) SlashFilterListBlocks(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error) {
+	return sa.syncer.SlashFilter.ListMinedBlocks(ctx, miner, from, to)
+}
+
+// SlashFilterCheckBlock checks a block against the blocks recorded by the slash filter
+func (sa *syncerAPI) SlashFilterCheckBlock(ctx context.Context, blk cid.Cid) (string, error) {
+	chainReader := sa.syncer.ChainModule.ChainReader
+	bh, err := chainReader.GetBlock(ctx, blk)
+	if err != nil {
+		return "", fmt.Errorf("loading block: %v", err)
+	}
+	if len(bh.Parents) == 0 {
+		return "", fmt.Errorf("block %s has no parents", blk)
+	}
+	parent, err := chainReader.GetBlock(ctx, bh.Parents[0])
+	if err != nil {
+		return "", fmt.Errorf("loading parent block: %v", err)
+	}
+
+	if err := sa.syncer.SlashFilter.CheckBlock(ctx, bh, parent.Height); err != nil {
+		return err.Error(), nil
+	}
+	return "", nil
+}
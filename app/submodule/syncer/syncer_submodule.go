@@ -15,6 +15,7 @@ import (
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	fbig "github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
@@ -26,19 +27,29 @@ import (
 	"github.com/filecoin-project/venus/pkg/beacon"
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/chainsync"
+	"github.com/filecoin-project/venus/pkg/chainsync/fastsync"
 	"github.com/filecoin-project/venus/pkg/chainsync/slashfilter"
+	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/consensusfault"
 	"github.com/filecoin-project/venus/pkg/net/blocksub"
 	"github.com/filecoin-project/venus/pkg/net/pubsub"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/vm/gas"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/ipfs/go-blockservice"
 )
 
 var log = logging.Logger("sync.module") // nolint: deadcode
 
+// the bounds of the delay between the attempts of the fast sync run on start
+const (
+	fastSyncMinBackoff = 10 * time.Second
+	fastSyncMaxBackoff = 10 * time.Minute
+)
+
 // SyncerSubmodule enhances the node with chain syncing capabilities
 type SyncerSubmodule struct { //nolint
 	BlockstoreModule *blockstore.BlockstoreSubmodule
@@ -57,9 +68,19 @@ type SyncerSubmodule struct { //nolint
 	SyncProvider     ChainSyncProvider
 	SlashFilter      slashfilter.ISlashFilter
 	BlockValidator   *consensus.BlockValidator
+	// BadTipSets holds the tipsets the syncer refuses to sync to
+	BadTipSets *syncTypes.BadTipSetCache
+	// FaultDetector detects the consensus faults in the block headers received from the network, the
+	// ones received with gossip and the ones synced, it is nil if the detector is disabled
+	FaultDetector *consensusfault.Detector
 
 	// cancelChainSync cancels the context for chain sync subscriptions and handlers.
 	CancelChainSync context.CancelFunc
+
+	// fastSyncFrom is the trusted tipset of the fast sync run on start, it is empty without fast sync
+	fastSyncFrom  types.TipSetKey
+	fastSyncDepth abi.ChainEpoch
+	metaDs        repo.Datastore
 }
 
 type syncerConfig interface {
@@ -68,11 +89,13 @@ type syncerConfig interface {
 	ChainClock() clock.ChainEpochClock
 	Repo() repo.Repo
 	Verifier() ffiwrapper.Verifier
+	FastSync() (types.TipSetKey, abi.ChainEpoch)
 }
 
 type nodeChainSelector interface {
 	Weight(context.Context, *types.TipSet) (fbig.Int, error)
 	IsHeavier(ctx context.Context, a, b *types.TipSet) (bool, error)
+	ExplainHeavier(ctx context.Context, a, b *types.TipSet) (bool, string, error)
 }
 
 // NewSyncerSubmodule creates a new chain submodule.
@@ -132,20 +155,32 @@ func NewSyncerSubmodule(ctx context.Context,
 	chn.Stmgr = stmgr
 	chn.Waiter.Stmgr = stmgr
 
+	badTipSets, err := syncTypes.LoadBadTipSetCache(ctx, config.Repo().MetaDatastore())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load bad tipsets")
+	}
+
 	chainSyncManager, err := chainsync.NewManager(stmgr, blkValid, chn, nodeChainSelector,
-		blockstore.Blockstore, network.ExchangeClient, config.ChainClock(), chn.Fork)
+		blockstore.Blockstore, network.ExchangeClient, config.ChainClock(), chn.Fork, badTipSets)
 	if err != nil {
 		return nil, err
 	}
+	if cfg := config.Repo().Config().Sync; cfg != nil {
+		chainSyncManager.SetPipelineDepth(cfg.PipelineDepth)
+	}
 
-	var slashFilter slashfilter.ISlashFilter
-	if config.Repo().Config().SlashFilterDs.Type == "local" {
-		slashFilter = slashfilter.NewLocalSlashFilter(config.Repo().ChainDatastore())
-	} else {
-		slashFilter, err = slashfilter.NewMysqlSlashFilter(config.Repo().Config().SlashFilterDs.MySQL)
-		if err != nil {
-			return nil, err
-		}
+	slashFilter, err := slashfilter.NewSlashFilter(config.Repo().Config().SlashFilterDs.Type, config.Repo())
+	if err != nil {
+		return nil, err
+	}
+
+	var faultDetector *consensusfault.Detector
+	if cfg := config.Repo().Config().FaultReporter; cfg != nil && cfg.EnableDetector {
+		faultDetector = consensusfault.NewDetector(consensusfault.DefaultDetectorEpochs, config.ChainClock())
+		// the chains received with hello or the exchange protocol are recorded once validated
+		chainSyncManager.OnValidatedBlock(func(blk *types.BlockHeader) {
+			faultDetector.AddBlock(blk)
+		})
 	}
 
 	network.HelloHandler.Register(func(ci *types.ChainInfo) {
@@ -156,6 +191,7 @@ func NewSyncerSubmodule(ctx context.Context,
 		}
 	})
 
+	fastSyncFrom, fastSyncDepth := config.FastSync()
 	return &SyncerSubmodule{
 		Stmgr:            stmgr,
 		BlockstoreModule: blockstore,
@@ -167,6 +203,11 @@ func NewSyncerSubmodule(ctx context.Context,
 		Drand:            chn.Drand,
 		SyncProvider:     *NewChainSyncProvider(&chainSyncManager),
 		BlockValidator:   blkValid,
+		BadTipSets:       badTipSets,
+		FaultDetector:    faultDetector,
+		fastSyncFrom:     fastSyncFrom,
+		fastSyncDepth:    fastSyncDepth,
+		metaDs:           config.Repo().MetaDatastore(),
 	}, nil
 }
 
@@ -195,6 +236,10 @@ func (syncer *SyncerSubmodule) handleIncomingBlocks(ctx context.Context, msg pub
 	if err != nil {
 		log.Errorf("failed to save block %s", err)
 	}
+	// the block passed the validation of the block topic, its signature is checked
+	if syncer.FaultDetector != nil {
+		syncer.FaultDetector.AddBlock(header)
+	}
 	go func() {
 		start := time.Now()
 
@@ -271,7 +316,66 @@ func (syncer *SyncerSubmodule) Start(ctx context.Context) error {
 		return err
 	}
 
-	return syncer.ChainSyncManager.Start(ctx)
+	if syncer.fastSyncFrom.IsEmpty() {
+		syncer.startPrefetch(ctx, syncer.ChainModule.ChainReader.GetHead())
+		return syncer.ChainSyncManager.Start(ctx)
+	}
+
+	// the chains received are synced once the headers of the trusted tipset are fetched
+	ready := make(chan struct{})
+	syncer.ChainSyncManager.StartAfter(ctx, ready)
+	go func() {
+		chainReader := syncer.ChainModule.ChainReader
+		if !chainReader.GetCheckPoint().Equals(syncer.fastSyncFrom) {
+			if err := syncer.metaDs.Delete(ctx, fastsync.PrefetchedKey); err != nil {
+				log.Warnf("failed to reset the state prefetch: %v", err)
+			}
+		}
+		// the fast sync is retried until it succeeds, the chain can't be synced without the headers
+		backoff := fastSyncMinBackoff
+		for {
+			ts, err := fastsync.Sync(ctx, chainReader, syncer.NetworkModule.ExchangeClient, syncer.fastSyncFrom, syncer.fastSyncDepth)
+			if err == nil {
+				close(ready)
+				syncer.startPrefetch(ctx, ts)
+				return
+			}
+			log.Errorf("fast sync to %s failed, retrying in %s: %v", syncer.fastSyncFrom, backoff, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > fastSyncMaxBackoff {
+				backoff = fastSyncMaxBackoff
+			}
+		}
+	}()
+	return nil
+}
+
+// startPrefetch fetches in background the state of ts missing from a lazy blockstore, until
+// a prefetch finished once. Only that state is made local: the older states, and the messages and
+// receipts below the trusted tipset of the fast sync, are still read from the network as needed.
+func (syncer *SyncerSubmodule) startPrefetch(ctx context.Context, ts *types.TipSet) {
+	lazy, ok := syncer.BlockstoreModule.Blockstore.(*blockstoreutil.LazyStore)
+	if !ok {
+		return
+	}
+	if done, err := syncer.metaDs.Has(ctx, fastsync.PrefetchedKey); err != nil || done {
+		return
+	}
+
+	root := ts.At(0).ParentStateRoot
+	go func() {
+		if err := fastsync.NewPrefetcher(lazy, fastsync.DefaultPrefetchConcurrency).Run(ctx, root); err != nil {
+			return
+		}
+		if err := syncer.metaDs.Put(ctx, fastsync.PrefetchedKey, []byte(root.String())); err != nil {
+			log.Warnf("failed to record the state prefetch: %v", err)
+		}
+	}()
 }
 
 func (syncer *SyncerSubmodule) Stop(ctx context.Context) {
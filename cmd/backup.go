@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/paths"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+var backupCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Backup and restore the repo metadata",
+		ShortDescription: `
+The backup holds the config and the wallet, chain, meta and paych datastores of the repo,
+the blockstore is not included and has to be synced again or imported from a snapshot.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create":  backupCreateCmd,
+		"restore": backupRestoreCmd,
+	},
+}
+
+var backupCreateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Write a backup of the repo metadata of the running daemon to a new file",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("path", true, false, "path of the backup file"),
+	},
+	// the backup file is written by the daemon, resolve the path in the local process
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		fpath, err := filepath.Abs(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		req.Arguments[0] = fpath
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fpath := req.Arguments[0]
+		if err := env.(*node.Env).CommonAPI.CreateBackup(req.Context, fpath); err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("backup written to %s", fpath))
+	},
+}
+
+var backupRestoreCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Create a repo from a backup file",
+		ShortDescription: `
+Create the repo given by --repo from a backup, the repo must not exist. The chain has to be
+imported from a snapshot or synced again before the daemon is started.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("path", true, false, "path of the backup file"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		repoDir, _ := req.Options[OptionRepoDir].(string)
+		repoDir, err := paths.GetRepoPath(repoDir)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(req.Arguments[0])
+		if err != nil {
+			return fmt.Errorf("failed to open backup file: %w", err)
+		}
+		defer f.Close() // nolint: errcheck
+
+		if err := repo.RestoreBackup(req.Context, f, repoDir); err != nil {
+			return fmt.Errorf("failed to restore backup: %w", err)
+		}
+		return printOneString(re, fmt.Sprintf("repo restored to %s", repoDir))
+	},
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
@@ -18,7 +19,9 @@ import (
 	cmds "github.com/ipfs/go-ipfs-cmds"
 
 	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/paths"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/repo"
 	v1api "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
@@ -36,7 +39,15 @@ var chainCmd = &cmds.Command{
 		"get-block-messages": chainGetBlockMessagesCmd,
 		"get-receipts":       chainGetReceiptsCmd,
 		"disputer":           chainDisputeSetCmd,
+		"fault-reporter":     chainFaultReporterCmd,
 		"export":             chainExportCmd,
+		"export-diff":        chainExportDiffCmd,
+		"import-diff":        chainImportDiffCmd,
+		"prune":              chainPruneCmd,
+		"backfill-msgindex":  chainBackfillMsgIndexCmd,
+		"check":              chainCheckCmd,
+		"verify-range":       chainVerifyRangeCmd,
+		"reorgs":             chainReorgsCmd,
 	},
 }
 
@@ -383,6 +394,368 @@ var chainExportCmd = &cmds.Command{
 	},
 }
 
+var chainExportDiffCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "export the chain between two tipsets to a differential snapshot",
+		ShortDescription: `Write to a car file the objects reachable from the chain of --tipset above --base
+which are not reachable from --base. The snapshot is imported with 'venus chain import-diff'
+on top of a repo whose head is the base tipset.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("outputPath", true, false, ""),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("base", "the tipset the snapshot applies to, a tipset key or @height"),
+		cmds.StringOption("tipset", "the head of the exported chain, defaults to the head").WithDefault(""),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		chainAPI := env.(*node.Env).ChainAPI
+
+		baseStr, _ := req.Options["base"].(string)
+		if baseStr == "" {
+			return errors.New("must specify the base tipset")
+		}
+		base, err := ParseTipSetRef(req.Context, chainAPI, baseStr)
+		if err != nil {
+			return err
+		}
+		ts, err := LoadTipSet(req.Context, req, chainAPI)
+		if err != nil {
+			return err
+		}
+
+		stream, err := chainAPI.ChainExportDiff(req.Context, base.Key(), ts.Key())
+		if err != nil {
+			return err
+		}
+
+		fi, err := os.Create(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err := fi.Close()
+			if err != nil {
+				fmt.Printf("error closing output file: %+v", err)
+			}
+		}()
+
+		var last bool
+		for b := range stream {
+			last = len(b) == 0
+
+			_, err := fi.Write(b)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !last {
+			return fmt.Errorf("incomplete export (remote connection lost?)")
+		}
+
+		return printOneString(re, fmt.Sprintf("exported the chain from %d to %d", base.Height(), ts.Height()))
+	},
+}
+
+var chainImportDiffCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "import a differential snapshot on top of the repo",
+		ShortDescription: `Import a snapshot written by 'venus chain export-diff' into the repo given by --repo,
+whose head must be the base tipset of the snapshot. The daemon must be stopped.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("path", true, false, "path or url of the snapshot file"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		repoDir, _ := req.Options[OptionRepoDir].(string)
+		repoDir, err := paths.GetRepoPath(repoDir)
+		if err != nil {
+			return err
+		}
+
+		rep, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rep.Close(); err != nil {
+				fmt.Printf("error closing repo: %+v", err)
+			}
+		}()
+
+		if err := ImportDiff(req.Context, rep, req.Arguments[0]); err != nil {
+			return err
+		}
+		return printOneString(re, "differential snapshot imported")
+	},
+}
+
+var chainPruneCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Delete the old state which is no longer needed from the blockstore",
+		ShortDescription: `Delete from the blockstore the state trees and receipts older than the last
+recent-stateroots epochs which are not reachable from the head, keeping all the block headers and
+messages, then run the garbage collection of the blockstore to reclaim the disk space. The objects
+which aren't part of the old state of the chain, like the pending messages or the headers and messages
+of the side forks, are kept. The state computations wait while the old state is deleted.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("recent-stateroots", "specify the number of recent state roots to keep").WithDefault(int64(2 * constants.Finality)),
+		cmds.BoolOption("dry-run", "only report the objects and bytes that would be pruned").WithDefault(false),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		rsrs := abi.ChainEpoch(req.Options["recent-stateroots"].(int64))
+		if rsrs < constants.Finality {
+			return fmt.Errorf("\"recent-stateroots\" has to be greater than %d", constants.Finality)
+		}
+		dryRun := req.Options["dry-run"].(bool)
+
+		progress, err := env.(*node.Env).ChainAPI.ChainPrune(req.Context, types.ChainPruneOpts{
+			RetainState: rsrs,
+			DryRun:      dryRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		var last types.ChainPruneProgress
+		for p := range progress {
+			last = p
+			if p.Done {
+				break
+			}
+			fmt.Printf("%s: scanned %d, marked %d, pruned %d (%s)\n", p.Stage, p.Scanned, p.Marked, p.Pruned,
+				types.SizeStr(types.NewInt(p.PrunedBytes)))
+		}
+
+		if !last.Done {
+			return fmt.Errorf("incomplete prune (remote connection lost?)")
+		}
+		if last.Err != "" {
+			return errors.New(last.Err)
+		}
+
+		action := "pruned"
+		if dryRun {
+			action = "can be pruned"
+		}
+		return printOneString(re, fmt.Sprintf("%d of %d objects %s, %s reclaimed", last.Pruned, last.Scanned, action,
+			types.SizeStr(types.NewInt(last.PrunedBytes))))
+	},
+}
+
+var chainBackfillMsgIndexCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Index the messages of the chain",
+		ShortDescription: `Index the messages of the canonical chain from the epoch from down to the epoch to,
+the message index must be enabled with Index.EnableMsgIndex.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "the epoch to start indexing from, defaults to the head"),
+		cmds.Int64Option("to", "the lowest epoch to index").WithDefault(int64(0)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		chainAPI := env.(*node.Env).ChainAPI
+
+		from, ok := req.Options["from"].(int64)
+		if !ok {
+			head, err := chainAPI.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			from = int64(head.Height())
+		}
+		to := req.Options["to"].(int64)
+
+		count, err := chainAPI.ChainBackfillMsgIndex(ctx, abi.ChainEpoch(from), abi.ChainEpoch(to))
+		if err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("indexed the messages of %d tipsets", count))
+	},
+}
+
+var chainCheckCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check the integrity of the chain objects in the blockstore",
+		ShortDescription: `Walk the chain back from a tipset and verify that every header, message, receipt and
+state tree object is present in the blockstore and matches its cid. With --repair the missing
+or corrupt objects are fetched from the network and written back to the blockstore.`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("tipset", "the tipset to check from, defaults to the head").WithDefault(""),
+		cmds.Int64Option("epochs", "the number of epochs to check").WithDefault(int64(constants.Finality)),
+		cmds.BoolOption("repair", "fetch the missing or corrupt objects from the network").WithDefault(false),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		chainAPI := env.(*node.Env).ChainAPI
+
+		ts, err := LoadTipSet(ctx, req, chainAPI)
+		if err != nil {
+			return err
+		}
+
+		res, err := chainAPI.ChainCheck(ctx, ts.Key(), types.ChainCheckOpts{
+			Epochs: abi.ChainEpoch(req.Options["epochs"].(int64)),
+			Repair: req.Options["repair"].(bool),
+		})
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		repaired := 0
+		for _, obj := range res.Bad {
+			status := ""
+			if obj.Repaired {
+				status = " (repaired)"
+				repaired++
+			} else if obj.Err != "" {
+				status = fmt.Sprintf(" (repair failed: %s)", obj.Err)
+			}
+			fmt.Fprintf(buf, "%s %s%s\n", obj.Problem, obj.Cid, status)
+		}
+		fmt.Fprintf(buf, "checked %d objects from %d to %d, %d bad, %d repaired", res.Checked, res.From, res.To, len(res.Bad), repaired)
+
+		return printOneString(re, buf.String())
+	},
+}
+
+var chainVerifyRangeCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Re-execute a range of tipsets and verify their state roots",
+		ShortDescription: `Re-execute every tipset of the canonical chain between the epochs from and to in a scratch
+blockstore, and compare the computed state roots and receipts with the ones recorded in the chain.
+The workers re-execute chunks of the range in parallel, each tipset from the parent state recorded
+in the chain. The lowest diverging tipset is printed with the messages whose receipts differ.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "the lowest epoch to re-execute"),
+		cmds.Int64Option("to", "the highest epoch to re-execute, defaults to the parent of the head"),
+		cmds.IntOption("workers", "the number of tipsets re-executed in parallel").WithDefault(runtime.NumCPU()),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		chainAPI := env.(*node.Env).ChainAPI
+
+		from, ok := req.Options["from"].(int64)
+		if !ok {
+			return fmt.Errorf("--from is required")
+		}
+		to, ok := req.Options["to"].(int64)
+		if !ok {
+			head, err := chainAPI.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			to = int64(head.Height()) - 1
+		}
+		workers, _ := req.Options["workers"].(int)
+
+		progress, err := chainAPI.ChainVerifyRange(ctx, types.ChainVerifyRangeOpts{
+			From:    abi.ChainEpoch(from),
+			To:      abi.ChainEpoch(to),
+			Workers: workers,
+		})
+		if err != nil {
+			return err
+		}
+
+		var last types.ChainVerifyRangeProgress
+		for p := range progress {
+			last = p
+			if p.Done {
+				break
+			}
+			fmt.Printf("verified %d of %d epochs\n", p.Verified, p.Total)
+		}
+
+		if !last.Done {
+			return fmt.Errorf("incomplete verification (remote connection lost?)")
+		}
+		if last.Err != "" {
+			return errors.New(last.Err)
+		}
+
+		div := last.Divergence
+		if div == nil {
+			return printOneString(re, fmt.Sprintf("the execution of the epochs %d to %d matches the chain", from, to))
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("first divergence at %d: tipset %s, child %s\n", div.Height, div.TipSet, div.Child)
+		if div.Error != "" {
+			writer.Println("execution failed:", div.Error)
+			return re.Emit(buf)
+		}
+		writer.Printf("state root: computed %s, chain %s\n", div.ComputedStateRoot, div.ClaimedStateRoot)
+		writer.Printf("receipts: computed %s, chain %s\n", div.ComputedReceipts, div.ClaimedReceipts)
+		for _, diff := range div.ReceiptDiffs {
+			writer.Printf("message %d %s:\n", diff.Index, diff.Message)
+			writer.Println("\tcomputed:", formatReceipt(diff.Computed))
+			writer.Println("\tchain:   ", formatReceipt(diff.Claimed))
+		}
+		return re.Emit(buf)
+	},
+}
+
+var chainReorgsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the reorgs of the chain recorded by the node",
+		ShortDescription: `List the last reorgs of the chain recorded by the node, the latest first: when they happened,
+their depth in epochs from the common ancestor to the old head, the old and new heads and the
+common ancestor. The dropped and added tipsets are listed with --verbose.`,
+	},
+	Options: []cmds.Option{
+		cmds.IntOption("limit", "the number of reorgs to list, all the recorded reorgs if not positive").WithDefault(20),
+		cmds.BoolOption("verbose", "v", "list the dropped and added tipsets"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		limit, _ := req.Options["limit"].(int)
+		verbose, _ := req.Options["verbose"].(bool)
+
+		reorgs, err := env.(*node.Env).ChainAPI.ChainReorgHistory(req.Context, limit)
+		if err != nil {
+			return err
+		}
+		if len(reorgs) == 0 {
+			return printOneString(re, "no reorg recorded")
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, evt := range reorgs {
+			writer.Printf("%s\tdepth %d\told head %d %s\tnew head %d %s\tcommon ancestor %d %s\n",
+				evt.Time.Format(time.RFC3339), evt.Depth, evt.OldHeight, evt.OldHead,
+				evt.NewHeight, evt.NewHead, evt.CommonAncestorHeight, evt.CommonAncestor)
+			if verbose {
+				for _, tsk := range evt.Dropped {
+					writer.Println("\tdropped:", tsk)
+				}
+				for _, tsk := range evt.Added {
+					writer.Println("\tadded:  ", tsk)
+				}
+			}
+		}
+		return re.Emit(buf)
+	},
+}
+
+func formatReceipt(rct *types.MessageReceipt) string {
+	if rct == nil {
+		return "missing"
+	}
+	events := "none"
+	if rct.EventsRoot != nil {
+		events = rct.EventsRoot.String()
+	}
+	return fmt.Sprintf("exit code %d, gas used %d, return %x, events %s", rct.ExitCode, rct.GasUsed, rct.Return, events)
+}
+
 // LoadTipSet gets the tipset from the context, or the head from the API.
 //
 // It always gets the head from the API so commands use a consistent tipset even if time pases.
@@ -13,6 +13,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/util/ulimit"
 
 	paramfetch "github.com/filecoin-project/go-paramfetch"
+	"github.com/filecoin-project/go-state-types/abi"
 
 	_ "net/http/pprof" // nolint: golint
 
@@ -26,6 +27,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/migration"
 	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/venus-shared/types"
 )
 
 var log = logging.Logger("daemon")
@@ -52,6 +54,8 @@ var daemonCmd = &cmds.Command{
 		cmds.StringsOption(BootstrapPeers, "set the bootstrap peers"),
 		cmds.BoolOption(IsRelay, "advertise and allow venus network traffic to be relayed through this node"),
 		cmds.StringOption(ImportSnapshot, "import chain state from a given chain export file or url"),
+		cmds.StringOption(FastSyncFrom, "fast sync from a trusted tipset, cids of its blocks separated by commas: only the headers are fetched and the state is read from the network as needed"),
+		cmds.Int64Option(FastSyncDepth, "the number of epochs of headers fetched below the trusted tipset of the fast sync, all of them down to the genesis if 0").WithDefault(int64(0)),
 		cmds.StringOption(GenesisFile, "path of file or HTTP(S) URL containing archive of genesis block DAG data"),
 		cmds.StringOption(Network, "when set, populates config with network specific parameters, eg. mainnet,2k,calibrationnet,interopnet,butterflynet").WithDefault("mainnet"),
 		cmds.StringOption(Password, "set wallet password"),
@@ -176,6 +180,24 @@ func daemonRun(req *cmds.Request, re cmds.ResponseEmitter) error {
 		return err
 	}
 
+	var fastSyncTsk types.TipSetKey
+	if fastSyncFrom, _ := req.Options[FastSyncFrom].(string); len(fastSyncFrom) != 0 {
+		cids, err := ParseTipSetString(fastSyncFrom)
+		if err != nil {
+			return fmt.Errorf("invalid fast sync tipset %q: %w", fastSyncFrom, err)
+		}
+		fastSyncTsk = types.NewTipSetKey(cids...)
+		if importPath, _ := req.Options[ImportSnapshot].(string); len(importPath) != 0 {
+			return fmt.Errorf("--%s and --%s can't be used together", FastSyncFrom, ImportSnapshot)
+		}
+		if offline, _ := req.Options[OfflineMode].(bool); offline {
+			return fmt.Errorf("--%s needs the network", FastSyncFrom)
+		}
+		if rep, err = enableLazyState(req, rep); err != nil {
+			return err
+		}
+	}
+
 	config := rep.Config()
 	if err := networks.SetConfigFromNetworkType(config, config.NetworkParams.NetworkType); err != nil {
 		return fmt.Errorf("set config failed %v %v", config.NetworkParams.NetworkType, err)
@@ -234,6 +256,10 @@ func daemonRun(req *cmds.Request, re cmds.ResponseEmitter) error {
 	if isRelay, ok := req.Options[IsRelay].(bool); ok && isRelay {
 		opts = append(opts, node.IsRelay())
 	}
+	if !fastSyncTsk.IsEmpty() {
+		depth, _ := req.Options[FastSyncDepth].(int64)
+		opts = append(opts, node.FastSyncOption(fastSyncTsk, abi.ChainEpoch(depth)))
+	}
 	importPath, _ := req.Options[ImportSnapshot].(string)
 	if len(importPath) != 0 {
 		err := Import(req.Context, rep, importPath)
@@ -296,6 +322,24 @@ func daemonRun(req *cmds.Request, re cmds.ResponseEmitter) error {
 	return fcn.RunRPCAndWait(req.Context, RootCmdDaemon, ready)
 }
 
+// enableLazyState makes the blockstore of the repo read the missing objects from the network, which
+// a fast sync needs as it doesn't fetch the state. The repo is reopened if the config is changed.
+func enableLazyState(req *cmds.Request, rep repo.Repo) (repo.Repo, error) {
+	cfg := rep.Config()
+	if cfg.Datastore.LazyState {
+		return rep, nil
+	}
+
+	cfg.Datastore.LazyState = true
+	if err := rep.ReplaceConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := rep.Close(); err != nil {
+		return nil, err
+	}
+	return getRepo(req)
+}
+
 func getRepo(req *cmds.Request) (repo.Repo, error) {
 	repoDir, _ := req.Options[OptionRepoDir].(string)
 	repoDir, err := paths.GetRepoPath(repoDir)
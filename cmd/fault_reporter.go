@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/consensusfault"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var chainFaultReporterCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Interact with the consensus fault detector",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"list":   faultReporterListCmd,
+		"report": faultReporterReportCmd,
+	},
+}
+
+var faultReporterListCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the consensus faults found in the blocks received from the network",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		faults, err := env.(*node.Env).SyncerAPI.SyncConsensusFaults(req.Context)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		if len(faults) == 0 {
+			writer.Println("no consensus fault detected")
+		}
+		for _, f := range faults {
+			writer.Println("Fault:", f.Type, "miner", f.Miner, "epoch", f.Epoch)
+			writer.Println("\tBlocks:", f.Block1.Cid(), f.Block2.Cid())
+			writer.Println("\tDetected:", f.Detected.Format("2006-01-02 15:04:05"))
+			switch {
+			case f.Report != nil:
+				writer.Println("\tReport:", f.Report)
+			case f.ReportError != "":
+				writer.Println("\tReportError:", f.ReportError)
+			}
+		}
+		return re.Emit(buf)
+	},
+}
+
+var faultReporterReportCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "Send a ReportConsensusFault message",
+		ShortDescription: `[block1 block2 <extra>]`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("block1", true, false, "cid of the first block of the fault"),
+		cmds.StringArg("block2", true, false, "cid of the second block of the fault"),
+		cmds.StringArg("extra", false, false, "cid of the sibling of the first block included by the second one, for a parent-grinding fault"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("max-fee", "Spend up to X FIL for the ReportConsensusFault message"),
+		cmds.StringOption("from", "optionally specify the account to send the message from"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		chainAPI := env.(*node.Env).ChainAPI
+
+		blks := make([]*types.BlockHeader, len(req.Arguments))
+		for i, arg := range req.Arguments {
+			c, err := cid.Decode(arg)
+			if err != nil {
+				return fmt.Errorf("invalid block cid %q: %w", arg, err)
+			}
+			if blks[i], err = chainAPI.ChainGetBlock(ctx, c); err != nil {
+				return fmt.Errorf("failed to load block %s: %w", c, err)
+			}
+		}
+		if blks[1].Height < blks[0].Height {
+			blks[0], blks[1] = blks[1], blks[0]
+		}
+
+		fault := &types.ConsensusFault{
+			Miner:  blks[0].Miner,
+			Epoch:  blks[0].Height,
+			Block1: blks[0],
+			Block2: blks[1],
+		}
+		if len(blks) > 2 {
+			fault.Extra = blks[2]
+		}
+		switch {
+		case blks[0].Miner != blks[1].Miner:
+			return fmt.Errorf("the blocks were mined by different miners")
+		case blks[0].Cid() == blks[1].Cid():
+			return fmt.Errorf("the blocks are identical")
+		case blks[0].Height == blks[1].Height:
+			fault.Type = consensusfault.FaultDoubleForkMining
+		case types.NewTipSetKey(blks[0].Parents...).Equals(types.NewTipSetKey(blks[1].Parents...)):
+			fault.Type = consensusfault.FaultTimeOffsetMining
+		case fault.Extra != nil && consensusfault.IsParentGrinding(blks[0], blks[1], fault.Extra):
+			fault.Type = consensusfault.FaultParentGrinding
+		default:
+			return fmt.Errorf("the blocks don't prove a consensus fault")
+		}
+
+		fromStr, _ := req.Options["from"].(string)
+		fromAddr, err := getSender(ctx, env.(*node.Env).WalletAPI, fromStr)
+		if err != nil {
+			return err
+		}
+		msg, err := consensusfault.NewReportMessage(fromAddr, fault)
+		if err != nil {
+			return err
+		}
+
+		rslt, err := chainAPI.StateCall(ctx, msg, types.EmptyTSK)
+		if err != nil {
+			return fmt.Errorf("failed to simulate report: %w", err)
+		}
+		if rslt.MsgRct.ExitCode.IsError() {
+			return fmt.Errorf("report is unsuccessful: %s", rslt.Error)
+		}
+
+		maxFee, _ := req.Options["max-fee"].(string)
+		mss, err := getMaxFee(maxFee)
+		if err != nil {
+			return err
+		}
+		sm, err := env.(*node.Env).MessagePoolAPI.MpoolPushMessage(ctx, msg, mss)
+		if err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("%s report message %s", fault.Type, sm.Cid()))
+	},
+}
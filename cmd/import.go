@@ -27,51 +27,119 @@ func Import(ctx context.Context, r repo.Repo, fileName string) error {
 }
 
 func importChain(ctx context.Context, r repo.Repo, fname string) error {
-	var rd io.Reader
+	rd, closer, err := openChainFile(fname)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	bs := r.Datastore()
+	// setup a ipldCbor on top of the local store
+	chainStore := chain.NewStore(r.ChainDatastore(), bs, cid.Undef, chain.NewMockCirculatingSupplyCalculator())
+
+	tip, err := chainStore.Import(ctx, rd)
+	if err != nil {
+		return fmt.Errorf("importing chain failed: %s", err)
+	}
+
+	err = chainStore.SetHead(context.TODO(), tip)
+	if err != nil {
+		return fmt.Errorf("importing chain failed: %s", err)
+	}
+	logImport.Infof("accepting %s as new head", tip.Key().String())
+
+	genesis, err := chainStore.GetTipSetByHeight(ctx, tip, 0, false)
+	if err != nil {
+		return fmt.Errorf("got genesis failed: %v", err)
+	}
+	if err := chainStore.PersistGenesisCID(ctx, genesis.Blocks()[0]); err != nil {
+		return fmt.Errorf("persist genesis failed: %v", err)
+	}
+
+	err = chainStore.WriteCheckPoint(context.TODO(), tip.Key())
+	if err != nil {
+		logImport.Errorf("set check point error: %s", err.Error())
+	}
+
+	return err
+}
+
+// ImportDiff imports a differential snapshot on top of the head of the repo, which must be
+// the base tipset of the snapshot.
+func ImportDiff(ctx context.Context, r repo.Repo, fname string) error {
+	rd, closer, err := openChainFile(fname)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	chainStore := chain.NewStore(r.ChainDatastore(), r.Datastore(), cid.Undef, chain.NewMockCirculatingSupplyCalculator())
+	defer chainStore.Stop()
+	if err := chainStore.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load chain: %w", err)
+	}
+
+	tip, err := chainStore.ImportDiff(ctx, rd)
+	if err != nil {
+		return fmt.Errorf("importing differential snapshot failed: %w", err)
+	}
+	if err := chainStore.SetHead(ctx, tip); err != nil {
+		return fmt.Errorf("importing differential snapshot failed: %w", err)
+	}
+	logImport.Infof("accepting %s as new head", tip.Key().String())
+
+	if err := chainStore.WriteCheckPoint(ctx, tip.Key()); err != nil {
+		logImport.Errorf("set check point error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// openChainFile opens the chain file at fname, which may be a local path or a http(s) url, and
+// decompresses it if needed. The progress of the reads is shown until the returned closer is called.
+func openChainFile(fname string) (io.Reader, func(), error) {
+	var rc io.ReadCloser
 	var l int64
 	if strings.HasPrefix(fname, "http://") || strings.HasPrefix(fname, "https://") {
 		resp, err := http.Get(fname) //nolint:gosec
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
-		defer resp.Body.Close() //nolint:errcheck
 
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("non-200 response: %d", resp.StatusCode)
+			_ = resp.Body.Close()
+			return nil, nil, fmt.Errorf("non-200 response: %d", resp.StatusCode)
 		}
 
-		rd = resp.Body
+		rc = resp.Body
 		l = resp.ContentLength
 	} else {
 		fname, err := homedir.Expand(fname)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		fi, err := os.Open(fname)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
-		defer fi.Close() //nolint:errcheck
 
 		st, err := os.Stat(fname)
 		if err != nil {
-			return err
+			_ = fi.Close()
+			return nil, nil, err
 		}
 
-		rd = fi
+		rc = fi
 		l = st.Size()
 	}
 
-	bs := r.Datastore()
-	// setup a ipldCbor on top of the local store
-	chainStore := chain.NewStore(r.ChainDatastore(), bs, cid.Undef, chain.NewMockCirculatingSupplyCalculator())
-
-	bufr := bufio.NewReaderSize(rd, 1<<20)
+	bufr := bufio.NewReaderSize(rc, 1<<20)
 
 	header, err := bufr.Peek(4)
 	if err != nil {
-		return fmt.Errorf("peek header: %w", err)
+		_ = rc.Close()
+		return nil, nil, fmt.Errorf("peek header: %w", err)
 	}
 
 	bar := pb.New64(l)
@@ -82,41 +150,20 @@ func importChain(ctx context.Context, r repo.Repo, fname string) error {
 	bar.Units = pb.U_BYTES
 
 	var ir io.Reader = br
+	var zr io.ReadCloser
 	if string(header[1:]) == "\xB5\x2F\xFD" { // zstd
-		zr := zstd.NewReader(br)
-		defer func() {
-			if err := zr.Close(); err != nil {
-				log.Errorw("closing zstd reader", "error", err)
-			}
-		}()
+		zr = zstd.NewReader(br)
 		ir = zr
 	}
 
 	bar.Start()
-	tip, err := chainStore.Import(ctx, ir)
-	if err != nil {
-		return fmt.Errorf("importing chain failed: %s", err)
-	}
-	bar.Finish()
-
-	err = chainStore.SetHead(context.TODO(), tip)
-	if err != nil {
-		return fmt.Errorf("importing chain failed: %s", err)
-	}
-	logImport.Infof("accepting %s as new head", tip.Key().String())
-
-	genesis, err := chainStore.GetTipSetByHeight(ctx, tip, 0, false)
-	if err != nil {
-		return fmt.Errorf("got genesis failed: %v", err)
-	}
-	if err := chainStore.PersistGenesisCID(ctx, genesis.Blocks()[0]); err != nil {
-		return fmt.Errorf("persist genesis failed: %v", err)
-	}
-
-	err = chainStore.WriteCheckPoint(context.TODO(), tip.Key())
-	if err != nil {
-		logImport.Errorf("set check point error: %s", err.Error())
-	}
-
-	return err
+	return ir, func() {
+		bar.Finish()
+		if zr != nil {
+			if err := zr.Close(); err != nil {
+				log.Errorw("closing zstd reader", "error", err)
+			}
+		}
+		_ = rc.Close()
+	}, nil
 }
@@ -30,6 +30,9 @@ const (
 
 	OptionLegacyRepoDir = "repodir"
 
+	// OptionRepoReadonly runs the command against the repo opened read-only instead of the daemon.
+	OptionRepoReadonly = "repo-readonly"
+
 	// OptionSectorDir is the name of the option for specifying the directory into which staged and sealed sectors will be written.
 	// OptionSectorDir = "sectordir"
 
@@ -73,6 +76,11 @@ const (
 
 	ImportSnapshot = "import-snapshot"
 
+	// FastSyncFrom is the trusted tipset a fast sync starts from, only the headers of its chain are
+	// fetched and the state is read lazily from the network
+	FastSyncFrom  = "fast-sync-from"
+	FastSyncDepth = "fast-sync-depth"
+
 	// wallet password
 	Password = "password"
 
@@ -117,6 +125,7 @@ MESSAGE COMMANDS
 
 MINER COMMANDS
   miner                  - Interact with actors
+  slashfilter            - Inspect the mined blocks recorded by the slash filter
 
 State COMMANDS
   state                  - query states of the filecoin network
@@ -134,6 +143,8 @@ TOOL COMMANDS
   inspect                - Show info about the venus node
   log                    - Interact with the daemon event log output
   version                - Show venus version information
+  backup                 - Backup and restore the repo metadata
+  repo                   - Manage the repo
   seed                   - Seal sectors for genesis miner
   fetch                  - Fetch proving parameters
 `,
@@ -142,6 +153,7 @@ TOOL COMMANDS
 		cmds.StringsOption(OptionToken, "set the auth token to use"),
 		cmds.StringOption(OptionAPI, "set the api port to use"),
 		cmds.StringOption(OptionRepoDir, OptionLegacyRepoDir, "set the repo directory, defaults to ~/.venus"),
+		cmds.BoolOption(OptionRepoReadonly, "run the chain and state commands against the repo opened read-only, without a daemon"),
 		cmds.StringOption(cmds.EncLong, cmds.EncShort, "The encoding type the output should be encoded with (pretty-json or json)").WithDefault("pretty-json"),
 		cmds.BoolOption("help", "Show the full command help text."),
 		cmds.BoolOption("h", "Show a short version of the command help text."),
@@ -165,21 +177,36 @@ var rootSubcmdsLocal = map[string]*cmds.Command{
 
 // all top level commands, available on daemon. set during init() to avoid configuration loops.
 var rootSubcmdsDaemon = map[string]*cmds.Command{
-	"chain":   chainCmd,
-	"sync":    syncCmd,
-	"drand":   drandCmd,
-	"inspect": inspectCmd,
-	"log":     logCmd,
-	"send":    msgSendCmd,
-	"mpool":   mpoolCmd,
-	"swarm":   swarmCmd,
-	"wallet":  walletCmd,
-	"version": versionCmd,
-	"state":   stateCmd,
-	"miner":   minerCmd,
-	"paych":   paychCmd,
-	"info":    infoCmd,
-	"evm":     evmCmd,
+	"chain":       chainCmd,
+	"sync":        syncCmd,
+	"drand":       drandCmd,
+	"inspect":     inspectCmd,
+	"log":         logCmd,
+	"send":        msgSendCmd,
+	"mpool":       mpoolCmd,
+	"swarm":       swarmCmd,
+	"wallet":      walletCmd,
+	"version":     versionCmd,
+	"state":       stateCmd,
+	"miner":       minerCmd,
+	"paych":       paychCmd,
+	"info":        infoCmd,
+	"evm":         evmCmd,
+	"backup":      backupCmd,
+	"repo":        repoCmd,
+	"slashfilter": slashFilterCmd,
+}
+
+// subcommands of the daemon commands running in the local process
+var localSubcmdPaths = map[string]struct{}{
+	"backup restore":      {},
+	"chain import-diff":   {},
+	"slashfilter migrate": {},
+}
+
+// subcommands of the daemon commands running in the local process when their offline option is set
+var offlineSubcmdPaths = map[string]struct{}{
+	"repo convert": {},
 }
 
 func init() {
@@ -205,8 +232,31 @@ func Run(ctx context.Context, args []string, stdin, stdout, stderr *os.File) (in
 	return 1, err
 }
 
-func buildEnv(ctx context.Context, _ *cmds.Request) (cmds.Environment, error) {
-	return node.NewClientEnv(ctx), nil
+// commands supported by the read-only mode, they only use the chain api
+var readonlyCmds = map[string]struct{}{
+	"chain": {},
+	"state": {},
+}
+
+func isRepoReadonly(req *cmds.Request) bool {
+	readonly, _ := req.Options[OptionRepoReadonly].(bool)
+	return readonly
+}
+
+func buildEnv(ctx context.Context, req *cmds.Request) (cmds.Environment, error) {
+	if !isRepoReadonly(req) || !requiresDaemon(req) || len(req.Path) == 0 {
+		return node.NewClientEnv(ctx), nil
+	}
+
+	if _, ok := readonlyCmds[req.Path[0]]; !ok || (len(req.Path) > 1 && req.Path[1] == "disputer") {
+		return nil, fmt.Errorf("--%s only supports the chain and state commands", OptionRepoReadonly)
+	}
+	repoDir, _ := req.Options[OptionRepoDir].(string)
+	repoDir, err := paths.GetRepoPath(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	return node.NewReadonlyEnv(ctx, repoDir)
 }
 
 type executor struct {
@@ -217,6 +267,13 @@ type executor struct {
 
 func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 	if e.api == "" {
+		if env, ok := env.(*node.Env); ok {
+			defer func() {
+				if err := env.Close(); err != nil {
+					fmt.Printf("error closing repo: %v\n", err)
+				}
+			}()
+		}
 		return e.exec.Execute(req, re, env)
 	}
 
@@ -226,7 +283,7 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 }
 
 func makeExecutor(req *cmds.Request, env interface{}) (cmds.Executor, error) {
-	isDaemonRequired := requiresDaemon(req)
+	isDaemonRequired := requiresDaemon(req) && !isRepoReadonly(req)
 	var (
 		apiInfo *APIInfo
 		err     error
@@ -315,6 +372,15 @@ func getAPIInfo(req *cmds.Request) (*APIInfo, error) {
 }
 
 func requiresDaemon(req *cmds.Request) bool {
+	path := strings.Join(req.Path, " ")
+	if _, ok := localSubcmdPaths[path]; ok {
+		return false
+	}
+	if offline, _ := req.Options[OfflineMode].(bool); offline {
+		if _, ok := offlineSubcmdPaths[path]; ok {
+			return false
+		}
+	}
 	for cmd := range rootSubcmdsLocal {
 		if len(req.Path) > 0 && req.Path[0] == cmd {
 			return false
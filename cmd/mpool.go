@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
 	"strconv"
+	"text/tabwriter"
 
 	stdbig "math/big"
 
@@ -28,17 +30,19 @@ var mpoolCmd = &cmds.Command{
 		Tagline: "Manage message pool",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"pending":  mpoolPending,
-		"clear":    mpoolClear,
-		"sub":      mpoolSub,
-		"stat":     mpoolStat,
-		"replace":  mpoolReplaceCmd,
-		"find":     mpoolFindCmd,
-		"config":   mpoolConfig,
-		"gas-perf": mpoolGasPerfCmd,
-		"publish":  mpoolPublish,
-		"delete":   mpoolDeleteAddress,
-		"select":   mpoolSelect,
+		"pending":      mpoolPending,
+		"clear":        mpoolClear,
+		"sub":          mpoolSub,
+		"stat":         mpoolStat,
+		"replace":      mpoolReplaceCmd,
+		"find":         mpoolFindCmd,
+		"config":       mpoolConfig,
+		"gas-perf":     mpoolGasPerfCmd,
+		"publish":      mpoolPublish,
+		"delete":       mpoolDeleteAddress,
+		"select":       mpoolSelect,
+		"fix-nonce":    mpoolFixNonceCmd,
+		"backtest-gas": mpoolBacktestGasCmd,
 	},
 }
 
@@ -79,6 +83,7 @@ var mpoolSelect = &cmds.Command{
 	},
 	Options: []cmds.Option{
 		cmds.FloatOption("quality", "optionally specify the wallet for publish message").WithDefault(float64(0.5)),
+		cmds.BoolOption("explain", "explain for each pending message whether it is selected or why not"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		ctx := context.TODO()
@@ -88,6 +93,13 @@ var mpoolSelect = &cmds.Command{
 		if err != nil {
 			return err
 		}
+		if explain, _ := req.Options["explain"].(bool); explain {
+			res, err := env.(*node.Env).MessagePoolAPI.MpoolSelectExplain(ctx, head.Key(), quality)
+			if err != nil {
+				return err
+			}
+			return re.Emit(res)
+		}
 		msgs, err := env.(*node.Env).MessagePoolAPI.MpoolSelect(ctx, head.Key(), quality)
 		if err != nil {
 			return err
@@ -614,6 +626,126 @@ Clear all pending messages from the mpool (USE WITH CARE)
 	},
 }
 
+var mpoolFixNonceCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Diagnose and repair the nonce gaps of an address",
+		ShortDescription: `
+Compare the state nonce, the pending messages, the local messages and the nonce of the message
+signer of an address, and report the gaps stranding the pending messages. With --fill the gaps
+are filled with zero value self-sends priced by the gas estimation, with --drop the stranded
+pending messages and the stale local messages are dropped, and the signer nonce is reset.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "the address to check"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("fill", "fill the gaps with self-sends"),
+		cmds.BoolOption("drop", "drop the stranded pending messages and the stale local messages"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		fill, _ := req.Options["fill"].(bool)
+		drop, _ := req.Options["drop"].(bool)
+
+		var fix *types.NonceGapFix
+		if fill || drop {
+			fix, err = env.(*node.Env).MessagePoolAPI.MpoolFixNonceGaps(req.Context, addr, fill, drop)
+		} else {
+			var report *types.NonceGapReport
+			report, err = env.(*node.Env).MessagePoolAPI.MpoolNonceGaps(req.Context, addr)
+			fix = &types.NonceGapFix{Report: report}
+		}
+		if err != nil {
+			return err
+		}
+
+		r := fix.Report
+		w := bytes.NewBufferString("")
+		writer := NewSilentWriter(w)
+		writer.Println("Address:", r.Address)
+		writer.Println("StateNonce:", r.StateNonce)
+		writer.Println("PoolNonce:", r.PoolNonce)
+		writer.Println("SignerNonce:", r.SignerNonce)
+		writer.Println("Pending:", r.Pending)
+		writer.Println("Local:", r.Local)
+		writer.Println("Gaps:", r.Gaps)
+		writer.Println("Stranded:", len(r.Stranded))
+		for _, c := range r.Stranded {
+			writer.Println("\t", c)
+		}
+		writer.Println("Stale:", len(r.Stale))
+		for _, c := range r.Stale {
+			writer.Println("\t", c)
+		}
+		if fill {
+			writer.Println("Filled:", len(fix.Filled))
+			for _, c := range fix.Filled {
+				writer.Println("\t", c)
+			}
+		}
+		if drop {
+			writer.Println("Dropped:", len(fix.Dropped))
+			for _, c := range fix.Dropped {
+				writer.Println("\t", c)
+			}
+		}
+		if !fill && !drop && (len(r.Gaps) > 0 || len(r.Stale) > 0) {
+			writer.Println()
+			writer.Println("use --fill to fill the gaps, or --drop to drop the stranded and stale messages")
+		}
+
+		return re.Emit(w)
+	},
+}
+
+var mpoolBacktestGasCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Compare the gas premium estimators on the recent tipsets",
+		ShortDescription: fmt.Sprintf(`
+Replay the recent tipsets, estimating on each of them the premium to be included within
+nblocksincl epochs with each of the gas premium estimators, and report how many epochs the
+premiums waited for a tipset including a premium as low, and how much they overpaid.
+The estimators are %v, with an optional parameter like percentile:75 or inclusion:0.95.
+At most three finalities of epochs are replayed, and nblocksincl is at most a tenth of a finality.
+`, messagepool.GasPremiumEstimators),
+	},
+	Options: []cmds.Option{
+		cmds.StringsOption("estimators", "the gas premium estimators to replay, all of them by default"),
+		cmds.Uint64Option("nblocksincl", "the number of epochs the premiums target an inclusion within").WithDefault(uint64(10)),
+		cmds.IntOption("epochs", "the number of tipsets to replay").WithDefault(200),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		estimators, _ := req.Options["estimators"].([]string)
+		nblocksincl, _ := req.Options["nblocksincl"].(uint64)
+		epochs, _ := req.Options["epochs"].(int)
+
+		res, err := env.(*node.Env).MessagePoolAPI.GasBacktestPremium(req.Context, estimators, nblocksincl, epochs)
+		if err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		tw := tabwriter.NewWriter(buf, 4, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Estimator\tSamples\tIncluded\tInTarget\tMeanLatency\tMeanPremium\tMeanOverpay\n")
+		for _, r := range res {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%s\t%s\n", r.Estimator, r.Samples, r.Included, r.InTarget,
+				r.MeanLatency, r.MeanPremium, r.MeanOverpay)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if len(res) > 0 {
+			fmt.Fprintf(buf, "\nIncluded within %d epochs, in target within %d epochs\n", res[0].MaxWait, nblocksincl)
+		}
+
+		return re.Emit(buf)
+	},
+}
+
 var mpoolSub = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "sub",
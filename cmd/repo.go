@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/paths"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+var repoCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Manage the repo",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"convert": repoConvertCmd,
+	},
+}
+
+var repoConvertCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Convert the blockstore to another engine",
+		ShortDescription: `
+Copy the blockstore into a new blockstore of the given engine, "badgerds" or "pebble", and
+switch the config to it. The copy is run by the daemon, which keeps using the current blockstore
+until it is restarted, the objects it writes meanwhile are copied when it starts again. With
+--offline the copy is run in the local process against the stopped repo given by --repo.
+
+The source blockstore is left in place and can be removed once the conversion is finished. A
+blockstore left at the path of the new one by a previous conversion is moved aside.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("engine", true, false, "engine of the new blockstore, badgerds or pebble"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption(OfflineMode, "convert the blockstore of the stopped repo without a daemon"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		engine := req.Arguments[0]
+		if offline, _ := req.Options[OfflineMode].(bool); !offline {
+			if err := env.(*node.Env).CommonAPI.ConvertBlockstore(req.Context, engine); err != nil {
+				return err
+			}
+			return printOneString(re, fmt.Sprintf("blockstore converted to %s, restart the daemon to use it", engine))
+		}
+
+		repoDir, _ := req.Options[OptionRepoDir].(string)
+		repoDir, err := paths.GetRepoPath(repoDir)
+		if err != nil {
+			return err
+		}
+		r, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+		if err != nil {
+			return err
+		}
+		if err := repo.ConvertDatastore(req.Context, r, engine); err != nil {
+			_ = r.Close()
+			return err
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+
+		// nothing was written to the source blockstore, opening the repo finishes the conversion
+		r, err = repo.OpenFSRepo(repoDir, repo.LatestVersion)
+		if err != nil {
+			return err
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("blockstore converted to %s", engine))
+	},
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/paths"
+	"github.com/filecoin-project/venus/pkg/chainsync/slashfilter"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+var slashFilterCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Inspect the mined blocks recorded by the slash filter",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"list":    slashFilterListCmd,
+		"check":   slashFilterCheckCmd,
+		"migrate": slashFilterMigrateCmd,
+	},
+}
+
+var slashFilterListCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the mined blocks recorded by the slash filter",
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("miner", "only list the blocks of the miner"),
+		cmds.Int64Option("from", "the lowest epoch to list").WithDefault(int64(0)),
+		cmds.Int64Option("to", "the highest epoch to list, defaults to the latest").WithDefault(int64(-1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		miner := address.Undef
+		if str, _ := req.Options["miner"].(string); str != "" {
+			var err error
+			if miner, err = address.NewFromString(str); err != nil {
+				return fmt.Errorf("invalid miner address %q: %w", str, err)
+			}
+		}
+		from, _ := req.Options["from"].(int64)
+		to, _ := req.Options["to"].(int64)
+
+		blks, err := env.(*node.Env).SyncerAPI.SlashFilterListBlocks(req.Context, miner, abi.ChainEpoch(from), abi.ChainEpoch(to))
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, blk := range blks {
+			writer.Printf("%d\t%s\t%s\tparents: %s (%d)\n", blk.Epoch, blk.Miner, blk.Cid, blk.ParentKey, blk.ParentEpoch)
+		}
+		return re.Emit(buf)
+	},
+}
+
+var slashFilterCheckCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check a block against the mined blocks recorded by the slash filter",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("block", true, false, "cid of the block"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		c, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return fmt.Errorf("invalid block cid %q: %w", req.Arguments[0], err)
+		}
+
+		fault, err := env.(*node.Env).SyncerAPI.SlashFilterCheckBlock(req.Context, c)
+		if err != nil {
+			return err
+		}
+		if fault == "" {
+			return printOneString(re, "no consensus fault")
+		}
+		return printOneString(re, fault)
+	},
+}
+
+var slashFilterMigrateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Copy the mined blocks recorded by a slash filter backend to another",
+		ShortDescription: `Copy the mined blocks recorded by the slash filter backend given by --from, "local", "mysql"
+or "sqlite", to the one given by --to, in the repo given by --repo. The mysql backend uses the
+connection of the slashFilter section of the config. The blocks already recorded by the
+destination for a miner at an epoch are kept. The daemon must be stopped, and the slashFilter
+type of the config changed to the destination to use it.`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("from", "the backend to copy the blocks from"),
+		cmds.StringOption("to", "the backend to copy the blocks to"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromType, _ := req.Options["from"].(string)
+		toType, _ := req.Options["to"].(string)
+		if fromType == "" || toType == "" {
+			return fmt.Errorf("both --from and --to are required")
+		}
+		if fromType == toType {
+			return fmt.Errorf("the source and destination backends are the same")
+		}
+		for _, typ := range []string{fromType, toType} {
+			if typ != slashfilter.TypeLocal && typ != slashfilter.TypeMySQL && typ != slashfilter.TypeSqlite {
+				return fmt.Errorf("unknown slash filter backend: %s", typ)
+			}
+		}
+
+		repoDir, _ := req.Options[OptionRepoDir].(string)
+		repoDir, err := paths.GetRepoPath(repoDir)
+		if err != nil {
+			return err
+		}
+		rep, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := rep.Close(); err != nil {
+				fmt.Printf("error closing repo: %+v", err)
+			}
+		}()
+
+		from, err := slashfilter.NewSlashFilter(fromType, rep)
+		if err != nil {
+			return err
+		}
+		to, err := slashfilter.NewSlashFilter(toType, rep)
+		if err != nil {
+			return err
+		}
+		n, err := slashfilter.Migrate(req.Context, from, to)
+		if err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("copied %d mined blocks from %s to %s", n, fromType, toType))
+	},
+}
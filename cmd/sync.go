@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 
 	"github.com/filecoin-project/venus/venus-shared/types"
@@ -22,6 +23,202 @@ var syncCmd = &cmds.Command{
 		"history":        historyCmd,
 		"concurrent":     getConcurrent,
 		"set-concurrent": setConcurrent,
+		"mark-bad":       syncMarkBadCmd,
+		"unmark-bad":     syncUnmarkBadCmd,
+		"check-bad":      syncCheckBadCmd,
+		"checkpoint":     syncCheckpointCmd,
+		"validate":       syncValidateCmd,
+		"targets":        syncTargetsCmd,
+	},
+}
+
+var syncTargetsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the candidate heads of the syncer and why they win or lose against the current head",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		head, err := env.(*node.Env).ChainAPI.ChainHead(req.Context)
+		if err != nil {
+			return err
+		}
+		headWeight, err := env.(*node.Env).SyncerAPI.ChainTipSetWeight(req.Context, head.Key())
+		if err != nil {
+			return err
+		}
+		targets, err := env.(*node.Env).SyncerAPI.SyncTargets(req.Context)
+		if err != nil {
+			return err
+		}
+
+		w := bytes.NewBufferString("")
+		writer := NewSilentWriter(w)
+		writer.Println("Head:", head.Height(), head.Key().String())
+		writer.Println("\tWeight:", headWeight)
+		writer.Println()
+		if len(targets) == 0 {
+			writer.Println("No target")
+		}
+		for i, t := range targets {
+			writer.Println("SyncTarget:", strconv.Itoa(i+1))
+			writer.Println("\tHead:", t.Height, t.Head.String())
+			writer.Println("\tParentWeight:", t.ParentWeight)
+			if t.Weight.IsZero() {
+				writer.Println("\tWeight: unknown")
+			} else {
+				writer.Println("\tWeight:", t.Weight)
+			}
+			writer.Println("\tSource:", t.Source)
+			writer.Println("\tSender:", t.Sender)
+			writer.Println("\tState:", syncTargetState(t))
+			if t.BadReason != "" {
+				writer.Println("\tBad:", t.BadReason)
+			}
+			if t.Err != "" {
+				writer.Println("\tErr:", t.Err)
+			}
+			writer.Println("\tHeavier:", t.Heavier)
+			writer.Println("\tReason:", t.Reason)
+			writer.Println()
+		}
+
+		return re.Emit(w)
+	},
+}
+
+func syncTargetState(t *types.SyncTarget) string {
+	switch {
+	case t.BadReason != "":
+		return "bad"
+	case t.Stage == types.StageSyncErrored:
+		return "failed"
+	case t.Stage == types.StageIdle:
+		return "waiting"
+	case t.Stage == types.StageSyncComplete:
+		return "complete"
+	default:
+		return "syncing"
+	}
+}
+
+var syncMarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Mark a tipset bad, the node refuses to sync to it and to its descendants",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "cids of the blocks of the tipset, separated by commas"),
+		cmds.StringArg("reason", false, false, "reason the tipset is bad"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cids, err := ParseTipSetString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		reason := "marked bad manually"
+		if len(req.Arguments) > 1 {
+			reason = req.Arguments[1]
+		}
+		return env.(*node.Env).SyncerAPI.SyncMarkBad(req.Context, types.NewTipSetKey(cids...), reason)
+	},
+}
+
+var syncUnmarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Remove a tipset from the bad tipsets",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "cids of the blocks of the tipset, separated by commas"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cids, err := ParseTipSetString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).SyncerAPI.SyncUnmarkBad(req.Context, types.NewTipSetKey(cids...))
+	},
+}
+
+var syncCheckBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check if a tipset is marked bad, and print the reason",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "cids of the blocks of the tipset, separated by commas"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cids, err := ParseTipSetString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		reason, err := env.(*node.Env).SyncerAPI.SyncCheckBad(req.Context, types.NewTipSetKey(cids...))
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			return printOneString(re, "tipset isn't marked bad")
+		}
+		return printOneString(re, fmt.Sprintf("tipset is marked bad: %s", reason))
+	},
+}
+
+var syncCheckpointCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Pin a tipset as final",
+		ShortDescription: `The syncer refuses any chain that doesn't include the checkpoint. If the
+checkpoint isn't in the chain of the current head, the head is switched to it.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "cids of the blocks of the tipset, separated by commas"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cids, err := ParseTipSetString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		tsk := types.NewTipSetKey(cids...)
+		if err := env.(*node.Env).SyncerAPI.SyncCheckpoint(req.Context, tsk); err != nil {
+			return err
+		}
+		return printOneString(re, fmt.Sprintf("checkpoint set to %s", tsk))
+	},
+}
+
+var syncValidateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Validate a tipset against its parent without changing the head",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("tipset", true, false, "cids of the blocks of the tipset, separated by commas"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cids, err := ParseTipSetString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		res, err := env.(*node.Env).SyncerAPI.SyncValidateTipset(req.Context, types.NewTipSetKey(cids...))
+		if err != nil {
+			return err
+		}
+
+		w := bytes.NewBufferString("")
+		writer := NewSilentWriter(w)
+		writer.Println("TipSet:", res.Height, res.TipSet.String())
+		writer.Println("Valid:", res.Valid)
+		if res.StateTransitionError != "" {
+			writer.Println("StateTransitionError:", res.StateTransitionError)
+		} else {
+			writer.Println("StateRoot:", "computed", res.ComputedStateRoot, "claimed", res.ClaimedStateRoot)
+			writer.Println("Receipts:", "computed", res.ComputedReceipts, "claimed", res.ClaimedReceipts)
+		}
+		for _, blk := range res.Blocks {
+			writer.Println("Block:", blk.Cid, "miner", blk.Miner)
+			if len(blk.Errors) == 0 {
+				writer.Println("\tOK")
+			}
+			for _, e := range blk.Errors {
+				writer.Println("\t*", e)
+			}
+		}
+		return re.Emit(w)
 	},
 }
 
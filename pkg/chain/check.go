@@ -0,0 +1,211 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// Problems reported by ChainCheckObject.Problem.
+const (
+	CheckProblemMissing = "missing"
+	CheckProblemCorrupt = "corrupt"
+)
+
+// checkFetchTimeout bounds the time spent fetching one object while repairing
+const checkFetchTimeout = 30 * time.Second
+
+// BlockFetcher fetches blocks from the network, it is used to repair the blockstore.
+type BlockFetcher interface {
+	GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error)
+}
+
+type chainChecker struct {
+	store   *Store
+	fetcher BlockFetcher
+	seen    *cid.Set
+	res     *types.ChainCheckResult
+}
+
+// CheckChain verifies that the headers, messages, receipts and state trees referenced by the
+// tipsets of the chain of ts in the last epochs epochs are present in the blockstore and
+// re-hash to their cid. If fetcher is set, the missing or corrupt objects are fetched from it
+// and written back to the blockstore. The objects only reachable through a missing or corrupt
+// object which could not be repaired are not checked.
+func (store *Store) CheckChain(ctx context.Context, ts *types.TipSet, epochs abi.ChainEpoch, fetcher BlockFetcher) (*types.ChainCheckResult, error) {
+	cc := &chainChecker{
+		store:   store,
+		fetcher: fetcher,
+		seen:    cid.NewSet(),
+		res:     &types.ChainCheckResult{From: ts.Height(), To: ts.Height()},
+	}
+	limit := ts.Height() - epochs
+
+	headers := ts.Cids()
+	for len(headers) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		c := headers[0]
+		headers = headers[1:]
+		if !cc.seen.Visit(c) {
+			continue
+		}
+
+		blk, err := cc.get(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if blk == nil {
+			continue
+		}
+
+		var b types.BlockHeader
+		if err := b.UnmarshalCBOR(bytes.NewReader(blk.RawData())); err != nil {
+			return nil, fmt.Errorf("unmarshaling block header (cid=%s): %w", c, err)
+		}
+		// the parents of the last checked tipsets are only checked to be present
+		if b.Height <= limit && b.Height != ts.Height() {
+			continue
+		}
+
+		if b.Height < cc.res.To {
+			cc.res.To = b.Height
+			if b.Height%builtin.EpochsInDay == 0 {
+				log.Infow("check", "height", b.Height, "checked", cc.res.Checked, "bad", len(cc.res.Bad))
+			}
+		}
+
+		for _, root := range []cid.Cid{b.Messages, b.ParentStateRoot, b.ParentMessageReceipts} {
+			if err := cc.walk(ctx, root); err != nil {
+				return nil, err
+			}
+		}
+
+		if b.Height > 0 {
+			headers = append(headers, b.Parents...)
+		}
+	}
+
+	log.Infow("chain check finished", "from", cc.res.From, "to", cc.res.To, "checked", cc.res.Checked, "bad", len(cc.res.Bad))
+	return cc.res, nil
+}
+
+// walk checks the dag of root.
+func (cc *chainChecker) walk(ctx context.Context, root cid.Cid) error {
+	stack := []cid.Cid{root}
+	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !c.Defined() || !cc.seen.Visit(c) {
+			continue
+		}
+
+		// only raw and dagcbor objects are part of the chain, see WalkSnapshot
+		prefix := c.Prefix()
+		if prefix.MhType == mh.IDENTITY {
+			continue
+		}
+		switch prefix.Codec {
+		case cid.Raw, cid.DagCBOR:
+		default:
+			continue
+		}
+
+		blk, err := cc.get(ctx, c)
+		if err != nil {
+			return err
+		}
+		if blk == nil || prefix.Codec != cid.DagCBOR {
+			continue
+		}
+
+		if err := cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+			stack = append(stack, link)
+		}); err != nil {
+			return fmt.Errorf("scanning for links of %s failed: %w", c, err)
+		}
+	}
+
+	return nil
+}
+
+// get returns the object c, repairing it if needed, or nil if it is missing or corrupt.
+func (cc *chainChecker) get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	cc.res.Checked++
+
+	var problem string
+	blk, err := cc.store.bsstore.Get(ctx, c)
+	switch {
+	case err == nil:
+		if !verifyObject(c, blk.RawData()) {
+			problem = CheckProblemCorrupt
+		}
+	case ipld.IsNotFound(err):
+		problem = CheckProblemMissing
+	default:
+		return nil, fmt.Errorf("getting object %s: %w", c, err)
+	}
+	if problem == "" {
+		return blk, nil
+	}
+
+	obj := types.ChainCheckObject{Cid: c, Problem: problem}
+	blk = nil
+	if cc.fetcher != nil {
+		if blk, err = cc.repair(ctx, c, problem == CheckProblemCorrupt); err != nil {
+			obj.Err = err.Error()
+		} else {
+			obj.Repaired = true
+		}
+	}
+	log.Warnw("bad object", "cid", c, "problem", problem, "repaired", obj.Repaired, "err", obj.Err)
+	cc.res.Bad = append(cc.res.Bad, obj)
+
+	return blk, nil
+}
+
+func (cc *chainChecker) repair(ctx context.Context, c cid.Cid, corrupt bool) (blocks.Block, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, checkFetchTimeout)
+	defer cancel()
+
+	blk, err := cc.fetcher.GetBlock(fetchCtx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch object: %w", err)
+	}
+	if !verifyObject(c, blk.RawData()) {
+		return nil, fmt.Errorf("fetched object doesn't match its cid")
+	}
+
+	// the blockstore may skip writing an object it believes it has
+	if corrupt {
+		if err := cc.store.bsstore.DeleteBlock(ctx, c); err != nil {
+			return nil, fmt.Errorf("failed to delete corrupt object: %w", err)
+		}
+	}
+	if err := cc.store.bsstore.Put(ctx, blk); err != nil {
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+	return blk, nil
+}
+
+func verifyObject(c cid.Cid, data []byte) bool {
+	sum, err := c.Prefix().Sum(data)
+	return err == nil && sum.Equals(c)
+}
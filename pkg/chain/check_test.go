@@ -0,0 +1,87 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+func TestCheckChain(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	head := builder.AppendManyOn(ctx, 5, builder.Genesis())
+	store := builder.Store()
+	bs := builder.BlockStore()
+
+	res, err := store.CheckChain(ctx, head, 3, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Bad)
+	require.EqualValues(t, 5, res.From)
+	require.EqualValues(t, 3, res.To)
+	require.NotZero(t, res.Checked)
+
+	// keep a copy of the objects to repair from
+	peer := blockstoreutil.NewMemory()
+	keys, err := bs.AllKeysChan(ctx)
+	require.NoError(t, err)
+	for c := range keys {
+		blk, err := bs.Get(ctx, c)
+		require.NoError(t, err)
+		require.NoError(t, peer.Put(ctx, blk))
+	}
+
+	// lose a header and corrupt the state root
+	missing := head.Parents().Cids()[0]
+	require.NoError(t, bs.DeleteBlock(ctx, missing))
+	corrupt := head.Blocks()[0].ParentStateRoot
+	require.NoError(t, bs.DeleteBlock(ctx, corrupt))
+	blk, err := blocks.NewBlockWithCid([]byte("corrupt"), corrupt)
+	require.NoError(t, err)
+	require.NoError(t, bs.Put(ctx, blk))
+
+	requireBad := func(res map[cid.Cid]string) {
+		require.Len(t, res, 2)
+		require.Equal(t, chain.CheckProblemMissing, res[missing])
+		require.Equal(t, chain.CheckProblemCorrupt, res[corrupt])
+	}
+
+	res, err = store.CheckChain(ctx, head, 3, nil)
+	require.NoError(t, err)
+	bad := make(map[cid.Cid]string)
+	for _, obj := range res.Bad {
+		require.False(t, obj.Repaired)
+		bad[obj.Cid] = obj.Problem
+	}
+	requireBad(bad)
+
+	res, err = store.CheckChain(ctx, head, 3, &blockFetcher{bs: peer})
+	require.NoError(t, err)
+	bad = make(map[cid.Cid]string)
+	for _, obj := range res.Bad {
+		require.True(t, obj.Repaired, obj.Err)
+		bad[obj.Cid] = obj.Problem
+	}
+	requireBad(bad)
+
+	res, err = store.CheckChain(ctx, head, 3, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Bad)
+}
+
+type blockFetcher struct {
+	bs blockstoreutil.Blockstore
+}
+
+func (f *blockFetcher) GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	return f.bs.Get(ctx, c)
+}
@@ -0,0 +1,389 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// HeightIndexPrefix is the datastore prefix of the persistent height index of the canonical chain.
+var HeightIndexPrefix = datastore.NewKey("/chain/heightIndex")
+
+// HeightIndexHeadKey is the key of the head the height index is consistent with.
+var HeightIndexHeadKey = datastore.NewKey("/chain/heightIndexHead")
+
+// heightIndexBatchSize is the number of heights written per batch when the index is backfilled
+const heightIndexBatchSize = 1000
+
+// HeightIndex is a persistent epoch -> tipset key index of the canonical chain, stored in
+// the chain datastore. Every indexed height is an ancestor of the head the index is
+// consistent with, and a null round is indexed with the first tipset above it.
+//
+// The index is updated with each head change and repairs itself from the head on startup:
+// the last finality is indexed synchronously, older tipsets are backfilled in background.
+type HeightIndex struct {
+	ds         repo.Datastore
+	loadTipSet loadTipSetFunc
+
+	lk sync.Mutex
+	// headHeight is the height of the head the index is consistent with
+	headHeight abi.ChainEpoch
+	// lowestUpdate is the lowest height written by a head change, a running backfill
+	// stops once it reaches it
+	lowestUpdate abi.ChainEpoch
+	backfilling  bool
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewHeightIndex creates a height index stored in ds.
+func NewHeightIndex(ds repo.Datastore, lts loadTipSetFunc) *HeightIndex {
+	hi := &HeightIndex{
+		ds:         ds,
+		loadTipSet: lts,
+		headHeight: -1,
+		closing:    make(chan struct{}),
+	}
+
+	if tsk, err := hi.getKey(context.TODO(), HeightIndexHeadKey); err == nil {
+		if head, err := lts(context.TODO(), tsk); err == nil {
+			hi.headHeight = head.Height()
+		} else {
+			log.Warnf("failed to load the head of the height index %s: %v", tsk, err)
+		}
+	}
+
+	return hi
+}
+
+func heightIndexKey(h abi.ChainEpoch) datastore.Key {
+	return HeightIndexPrefix.ChildString(strconv.FormatInt(int64(h), 10))
+}
+
+func (hi *HeightIndex) getKey(ctx context.Context, key datastore.Key) (types.TipSetKey, error) {
+	val, err := hi.ds.Get(ctx, key)
+	if err != nil {
+		return types.EmptyTSK, err
+	}
+
+	var tsk types.TipSetKey
+	if err := tsk.UnmarshalCBOR(bytes.NewReader(val)); err != nil {
+		return types.EmptyTSK, fmt.Errorf("failed to decode tipset key at %s: %w", key, err)
+	}
+	return tsk, nil
+}
+
+// Get returns the key of the tipset at height h, or of the first tipset above h if h is a
+// null round. datastore.ErrNotFound is returned if the height is not indexed (yet).
+func (hi *HeightIndex) Get(ctx context.Context, h abi.ChainEpoch) (types.TipSetKey, error) {
+	return hi.getKey(ctx, heightIndexKey(h))
+}
+
+// Lookup returns the key of the tipset at height h in the chain of from, it returns false
+// if from is not part of the indexed chain or h is not indexed.
+func (hi *HeightIndex) Lookup(ctx context.Context, from *types.TipSet, h abi.ChainEpoch) (types.TipSetKey, bool) {
+	if h > from.Height() {
+		return types.EmptyTSK, false
+	}
+
+	fromKey, err := hi.Get(ctx, from.Height())
+	if err != nil || !fromKey.Equals(from.Key()) {
+		return types.EmptyTSK, false
+	}
+
+	tsk, err := hi.Get(ctx, h)
+	if err != nil {
+		return types.EmptyTSK, false
+	}
+	return tsk, true
+}
+
+// put indexes ts and the null rounds below it, parentHeight is the height of the parent of ts.
+func putHeightIndex(ctx context.Context, batch datastore.Batch, ts *types.TipSet, parentHeight abi.ChainEpoch) error {
+	buf := new(bytes.Buffer)
+	if err := ts.Key().MarshalCBOR(buf); err != nil {
+		return err
+	}
+
+	for h := parentHeight + 1; h <= ts.Height(); h++ {
+		if err := batch.Put(ctx, heightIndexKey(h), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hi *HeightIndex) parentHeight(ctx context.Context, ts *types.TipSet) (abi.ChainEpoch, error) {
+	if ts.Height() == 0 {
+		return -1, nil
+	}
+
+	parent, err := hi.loadTipSet(ctx, ts.Parents())
+	if err != nil {
+		return 0, err
+	}
+	return parent.Height(), nil
+}
+
+// Update makes the index consistent with newHead, added are the tipsets of the new chain
+// above the common ancestor with the previous head.
+func (hi *HeightIndex) Update(ctx context.Context, newHead *types.TipSet, added []*types.TipSet) error {
+	hi.lk.Lock()
+	defer hi.lk.Unlock()
+
+	batch, err := hi.ds.Batch(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the previous chain may be longer than the new one
+	for h := newHead.Height() + 1; h <= hi.headHeight; h++ {
+		if err := batch.Delete(ctx, heightIndexKey(h)); err != nil {
+			return err
+		}
+	}
+
+	added = append([]*types.TipSet{}, added...)
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].Height() < added[j].Height()
+	})
+
+	lowest := newHead.Height()
+	var base *types.TipSet
+	for i, ts := range added {
+		var parentHeight abi.ChainEpoch
+		if i > 0 {
+			parentHeight = added[i-1].Height()
+		} else if ts.Height() > 0 {
+			if base, err = hi.loadTipSet(ctx, ts.Parents()); err != nil {
+				return err
+			}
+			parentHeight = base.Height()
+		} else {
+			parentHeight = -1
+		}
+		if err := putHeightIndex(ctx, batch, ts, parentHeight); err != nil {
+			return err
+		}
+		if parentHeight+1 < lowest {
+			lowest = parentHeight + 1
+		}
+	}
+
+	// make sure the chain below the new tipsets is indexed, this repairs the index after
+	// an unclean shutdown and indexes the recent chain of a node which never indexed it.
+	cur := base
+	for steps := abi.ChainEpoch(0); cur != nil; steps++ {
+		tsk, err := hi.Get(ctx, cur.Height())
+		if err == nil && tsk.Equals(cur.Key()) {
+			break
+		}
+		if steps >= policy.ChainFinality {
+			hi.startBackfill(cur)
+			break
+		}
+
+		parentHeight, err := hi.parentHeight(ctx, cur)
+		if err != nil {
+			return err
+		}
+		if err := putHeightIndex(ctx, batch, cur, parentHeight); err != nil {
+			return err
+		}
+		lowest = parentHeight + 1
+
+		if cur.Height() == 0 {
+			break
+		}
+		if cur, err = hi.loadTipSet(ctx, cur.Parents()); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := newHead.Key().MarshalCBOR(buf); err != nil {
+		return err
+	}
+	if err := batch.Put(ctx, HeightIndexHeadKey, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to write height index: %w", err)
+	}
+
+	hi.headHeight = newHead.Height()
+	if lowest < hi.lowestUpdate {
+		hi.lowestUpdate = lowest
+	}
+	return nil
+}
+
+// Rebuild indexes the whole chain of head, replacing the existing index.
+func (hi *HeightIndex) Rebuild(ctx context.Context, head *types.TipSet) error {
+	hi.lk.Lock()
+	defer hi.lk.Unlock()
+
+	batch, err := hi.ds.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	for h := head.Height() + 1; h <= hi.headHeight; h++ {
+		if err := batch.Delete(ctx, heightIndexKey(h)); err != nil {
+			return err
+		}
+	}
+
+	count := 0
+	for cur := head; ; {
+		parentHeight, err := hi.parentHeight(ctx, cur)
+		if err != nil {
+			return err
+		}
+		if err := putHeightIndex(ctx, batch, cur, parentHeight); err != nil {
+			return err
+		}
+
+		count++
+		if count%heightIndexBatchSize == 0 {
+			if err := batch.Commit(ctx); err != nil {
+				return fmt.Errorf("failed to write height index: %w", err)
+			}
+			if batch, err = hi.ds.Batch(ctx); err != nil {
+				return err
+			}
+		}
+
+		if cur.Height() == 0 {
+			break
+		}
+		if cur, err = hi.loadTipSet(ctx, cur.Parents()); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := head.Key().MarshalCBOR(buf); err != nil {
+		return err
+	}
+	if err := batch.Put(ctx, HeightIndexHeadKey, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to write height index: %w", err)
+	}
+
+	hi.headHeight = head.Height()
+	hi.lowestUpdate = 0
+	log.Infof("rebuilt height index of %d tipsets from %s", count, head.Key())
+	return nil
+}
+
+// startBackfill indexes the chain from ts down to the genesis or an indexed tipset in background.
+// Caller must hold lk.
+func (hi *HeightIndex) startBackfill(ts *types.TipSet) {
+	if hi.backfilling {
+		return
+	}
+	hi.backfilling = true
+	hi.lowestUpdate = ts.Height() + 1
+
+	hi.wg.Add(1)
+	go func() {
+		defer hi.wg.Done()
+		defer func() {
+			hi.lk.Lock()
+			hi.backfilling = false
+			hi.lk.Unlock()
+		}()
+
+		log.Infof("start backfilling height index from %d", ts.Height())
+		if err := hi.backfill(context.Background(), ts); err != nil {
+			log.Errorf("failed to backfill height index: %v", err)
+			return
+		}
+		log.Infof("finished backfilling height index")
+	}()
+}
+
+func (hi *HeightIndex) backfill(ctx context.Context, cur *types.TipSet) error {
+	for {
+		select {
+		case <-hi.closing:
+			return nil
+		default:
+		}
+
+		done, next, err := hi.backfillBatch(ctx, cur)
+		if err != nil || done {
+			return err
+		}
+		cur = next
+	}
+}
+
+// backfillBatch indexes up to heightIndexBatchSize tipsets from cur, it returns the next tipset to index.
+func (hi *HeightIndex) backfillBatch(ctx context.Context, cur *types.TipSet) (bool, *types.TipSet, error) {
+	hi.lk.Lock()
+	defer hi.lk.Unlock()
+
+	batch, err := hi.ds.Batch(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	done := false
+	for i := 0; i < heightIndexBatchSize; i++ {
+		// a head change indexed this part of the chain since the backfill started
+		if cur.Height() >= hi.lowestUpdate {
+			done = true
+			break
+		}
+		if tsk, err := hi.Get(ctx, cur.Height()); err == nil && tsk.Equals(cur.Key()) {
+			done = true
+			break
+		}
+
+		parentHeight, err := hi.parentHeight(ctx, cur)
+		if err != nil {
+			return false, nil, err
+		}
+		if err := putHeightIndex(ctx, batch, cur, parentHeight); err != nil {
+			return false, nil, err
+		}
+		hi.lowestUpdate = parentHeight + 1
+
+		if cur.Height() == 0 {
+			done = true
+			break
+		}
+		if cur, err = hi.loadTipSet(ctx, cur.Parents()); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if err := batch.Commit(ctx); err != nil {
+		return false, nil, fmt.Errorf("failed to write height index: %w", err)
+	}
+	return done, cur, nil
+}
+
+// Close stops the background backfill.
+func (hi *HeightIndex) Close() {
+	select {
+	case <-hi.closing:
+	default:
+		close(hi.closing)
+	}
+	hi.wg.Wait()
+}
@@ -0,0 +1,83 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestHeightIndex(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	store := builder.Store()
+	ds := builder.Repo().ChainDatastore()
+
+	// genesis -> 1 -> 2 -> (null) -> (null) -> 5 -> 6
+	link2 := builder.AppendManyOn(ctx, 2, builder.Genesis())
+	link5 := builder.BuildOneOn(ctx, link2, func(bb *chain.BlockBuilder) { bb.IncHeight(2) })
+	link6 := builder.AppendOn(ctx, link5, 1)
+	require.NoError(t, store.SetHead(ctx, link6))
+
+	requireIndexed := func(hi *chain.HeightIndex, head *types.TipSet) {
+		for cur := head; ; {
+			tsk, err := hi.Get(ctx, cur.Height())
+			require.NoError(t, err)
+			require.Equal(t, cur.Key(), tsk)
+			if cur.Height() == 0 {
+				break
+			}
+			parent, err := store.GetTipSet(ctx, cur.Parents())
+			require.NoError(t, err)
+			cur = parent
+		}
+		_, err := hi.Get(ctx, head.Height()+1)
+		require.ErrorIs(t, err, datastore.ErrNotFound)
+	}
+
+	hi := chain.NewHeightIndex(ds, store.GetTipSet)
+	requireIndexed(hi, link6)
+
+	// null rounds are indexed with the tipset above them
+	for _, h := range []abi.ChainEpoch{3, 4} {
+		tsk, err := hi.Get(ctx, h)
+		require.NoError(t, err)
+		require.Equal(t, link5.Key(), tsk)
+
+		ts, err := store.GetTipSetByHeight(ctx, link6, h, false)
+		require.NoError(t, err)
+		require.Equal(t, link5.Key(), ts.Key())
+		ts, err = store.GetTipSetByHeight(ctx, link6, h, true)
+		require.NoError(t, err)
+		require.Equal(t, link2.Key(), ts.Key())
+	}
+
+	// reorg to a shorter fork, the reverted heights must be replaced or removed
+	fork3 := builder.AppendOn(ctx, link2, 2)
+	require.NoError(t, store.SetHead(ctx, fork3))
+	hi = chain.NewHeightIndex(ds, store.GetTipSet)
+	requireIndexed(hi, fork3)
+
+	// lookups from a reverted tipset don't use the index
+	_, ok := hi.Lookup(ctx, link6, 1)
+	require.False(t, ok)
+	tsk, ok := hi.Lookup(ctx, fork3, 1)
+	require.True(t, ok)
+	ts, err := store.GetTipSetByHeight(ctx, link6, 5, false)
+	require.NoError(t, err)
+	require.Equal(t, link5.Key(), ts.Key())
+	require.Equal(t, link2.Parents(), tsk)
+
+	// rebuild the index of the original chain
+	require.NoError(t, hi.Rebuild(ctx, link6))
+	requireIndexed(hi, link6)
+}
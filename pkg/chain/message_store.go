@@ -59,6 +59,11 @@ func NewMessageStore(bs blockstoreutil.Blockstore, fkCfg *config.ForkUpgradeConf
 	return &MessageStore{bs: bs, fkCfg: fkCfg}
 }
 
+// WithBlockstore returns a MessageStore reading and writing the messages and the receipts in bs
+func (ms *MessageStore) WithBlockstore(bs blockstoreutil.Blockstore) *MessageStore {
+	return &MessageStore{bs: bs, fkCfg: ms.fkCfg}
+}
+
 // LoadMetaMessages loads the signed messages in the collection with cid c from ipld
 // storage.
 func (ms *MessageStore) LoadMetaMessages(ctx context.Context, metaCid cid.Cid) ([]*types.SignedMessage, []*types.Message, error) {
@@ -0,0 +1,301 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// ErrPruneInProgress is returned when a chain prune is requested while another one is running.
+var ErrPruneInProgress = errors.New("chain prune already in progress")
+
+const (
+	pruneBatchSize        = 16384
+	pruneProgressInterval = 100000
+)
+
+// Chain prune stages reported by ChainPruneProgress.Stage.
+const (
+	PruneStageList  = "list"
+	PruneStageMark  = "mark"
+	PruneStageSweep = "sweep"
+	PruneStageGC    = "gc"
+	PruneStageDone  = "done"
+)
+
+// WalkLiveObjects calls cb for every object needed to follow the chain from ts: all the
+// headers, the messages (only the last inclRecentRoots epochs if skipOldMsgs is set) and
+// the state and receipts of the last inclRecentRoots epochs, including the state computed
+// on top of those tipsets.
+func (store *Store) WalkLiveObjects(ctx context.Context, ts *types.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, cb func(cid.Cid) error) error {
+	if err := store.WalkSnapshot(ctx, ts, inclRecentRoots, skipOldMsgs, true, cb); err != nil {
+		return fmt.Errorf("walk chain failed: %w", err)
+	}
+
+	// WalkSnapshot only keeps the root of the receipts and knows nothing about the state
+	// computed on top of the tipsets, walk them for the whole retained range.
+	return store.walkRecentState(ctx, ts, ts.Height()-inclRecentRoots, cb)
+}
+
+// walkRecentState calls cb for the state and the receipts of ts and its ancestors above the height to,
+// including the state computed on top of those tipsets.
+func (store *Store) walkRecentState(ctx context.Context, ts *types.TipSet, to abi.ChainEpoch, cb func(cid.Cid) error) error {
+	walked := cid.NewSet()
+	visit := func(root cid.Cid) error {
+		if !root.Defined() || !walked.Visit(root) {
+			return nil
+		}
+		if err := cb(root); err != nil {
+			return err
+		}
+		links, err := recurseLinks(ctx, store.bsstore, walked, root, nil)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if err := cb(link); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	cur := ts
+	for cur.Height() > 0 && cur.Height() > to {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if tskCid, err := cur.Key().Cid(); err == nil {
+			if err := cb(tskCid); err != nil {
+				return err
+			}
+		}
+		if meta, err := store.LoadTipsetMetadata(ctx, cur); err == nil {
+			if err := visit(meta.TipSetStateRoot); err != nil {
+				return fmt.Errorf("walk state of %d failed: %w", cur.Height(), err)
+			}
+			if err := visit(meta.TipSetReceipts); err != nil {
+				return fmt.Errorf("walk receipts of %d failed: %w", cur.Height(), err)
+			}
+		}
+		if err := visit(cur.Blocks()[0].ParentMessageReceipts); err != nil {
+			return fmt.Errorf("walk parent receipts of %d failed: %w", cur.Height(), err)
+		}
+
+		parent, err := store.GetTipSet(ctx, cur.Parents())
+		if err != nil {
+			return err
+		}
+		cur = parent
+	}
+
+	return nil
+}
+
+// walkOldState calls cb for the objects of the state and the receipts of the tipsets of the chain of
+// ts from the height from down to the genesis, which is excluded. The objects missing from the
+// blockstore, like the ones already pruned, are skipped with their links.
+func (store *Store) walkOldState(ctx context.Context, ts *types.TipSet, from abi.ChainEpoch, cb func(c cid.Cid, size int) error) error {
+	if from <= 0 {
+		return nil
+	}
+	cur, err := store.GetTipSetByHeight(ctx, ts, from, true)
+	if err != nil {
+		return fmt.Errorf("failed to load tipset at %d: %w", from, err)
+	}
+
+	walked := cid.NewSet()
+	visit := func(root cid.Cid) error {
+		if !root.Defined() || !walked.Visit(root) {
+			return nil
+		}
+		stack := []cid.Cid{root}
+		for len(stack) > 0 {
+			c := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if c.Prefix().MhType == mh.IDENTITY {
+				continue
+			}
+
+			blk, err := store.bsstore.Get(ctx, c)
+			if err != nil {
+				if ipld.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if err := cb(c, len(blk.RawData())); err != nil {
+				return err
+			}
+			if c.Prefix().Codec != cid.DagCBOR {
+				continue
+			}
+			if err := cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+				if walked.Visit(link) {
+					stack = append(stack, link)
+				}
+			}); err != nil {
+				return fmt.Errorf("scanning for links of %s failed: %w", c, err)
+			}
+		}
+		return nil
+	}
+
+	for cur.Height() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var roots []cid.Cid
+		if meta, err := store.LoadTipsetMetadata(ctx, cur); err == nil {
+			roots = append(roots, meta.TipSetStateRoot, meta.TipSetReceipts)
+		}
+		for _, blk := range cur.Blocks() {
+			roots = append(roots, blk.ParentStateRoot, blk.ParentMessageReceipts)
+		}
+		for _, root := range roots {
+			if err := visit(root); err != nil {
+				return fmt.Errorf("walk state of %d failed: %w", cur.Height(), err)
+			}
+		}
+
+		parent, err := store.GetTipSet(ctx, cur.Parents())
+		if err != nil {
+			return err
+		}
+		cur = parent
+	}
+
+	return nil
+}
+
+// Prune deletes from the blockstore the state older than the last opts.RetainState epochs of ts: the
+// objects of the state trees and the receipts of the older tipsets of the chain of ts which are not
+// needed to follow the chain from ts. Only the objects reachable from that old state are candidates,
+// so the objects which don't belong to the chain of ts, like the pending messages of the message pool,
+// the blocks of a sync past ts, or the headers and messages of the side forks, are never deleted. The
+// state computations wait for the sweep, which first marks the state computed on top of the head.
+func (store *Store) Prune(ctx context.Context, ts *types.TipSet, opts types.ChainPruneOpts, progress func(types.ChainPruneProgress)) error {
+	if opts.RetainState < policy.ChainFinality {
+		return fmt.Errorf("retained state must be at least %d epochs", policy.ChainFinality)
+	}
+	if !atomic.CompareAndSwapInt32(&store.pruning, 0, 1) {
+		return ErrPruneInProgress
+	}
+	defer atomic.StoreInt32(&store.pruning, 0)
+
+	var p types.ChainPruneProgress
+	report := func(stage string) {
+		p.Stage = stage
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	type candidate struct {
+		cid  cid.Cid
+		size int
+	}
+
+	report(PruneStageList)
+	candidates := make(map[string]candidate)
+	if err := store.walkOldState(ctx, ts, ts.Height()-opts.RetainState, func(c cid.Cid, size int) error {
+		candidates[string(c.Hash())] = candidate{cid: c, size: size}
+		p.Scanned++
+		if p.Scanned%pruneProgressInterval == 0 {
+			report(PruneStageList)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to list old state: %w", err)
+	}
+
+	report(PruneStageMark)
+	mark := func(c cid.Cid) error {
+		delete(candidates, string(c.Hash()))
+		p.Marked++
+		if p.Marked%pruneProgressInterval == 0 {
+			report(PruneStageMark)
+		}
+		return nil
+	}
+	if err := store.WalkLiveObjects(ctx, ts, opts.RetainState, false, mark); err != nil {
+		return fmt.Errorf("failed to mark live objects: %w", err)
+	}
+
+	// the state computations wait for the sweep, the state computed since ts, including while the
+	// live objects were marked, may share objects with the old state
+	sweep := func() error {
+		store.stateLk.Lock()
+		defer store.stateLk.Unlock()
+
+		if head := store.GetHead(); head.Height() > ts.Height() {
+			if err := store.walkRecentState(ctx, head, ts.Height(), mark); err != nil {
+				return fmt.Errorf("failed to mark the state computed since %d: %w", ts.Height(), err)
+			}
+		}
+
+		report(PruneStageSweep)
+		batch := make([]cid.Cid, 0, pruneBatchSize)
+		flush := func() error {
+			if len(batch) == 0 || opts.DryRun {
+				batch = batch[:0]
+				return nil
+			}
+			if err := store.bsstore.DeleteMany(ctx, batch); err != nil {
+				return fmt.Errorf("failed to delete objects: %w", err)
+			}
+			batch = batch[:0]
+			return nil
+		}
+		for _, c := range candidates {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			p.PrunedBytes += uint64(c.size)
+			p.Pruned++
+
+			batch = append(batch, c.cid)
+			if len(batch) >= pruneBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			if p.Pruned%pruneProgressInterval == 0 {
+				report(PruneStageSweep)
+			}
+		}
+		return flush()
+	}
+	if err := sweep(); err != nil {
+		return err
+	}
+
+	if !opts.DryRun {
+		if gc, ok := store.bsstore.(blockstoreutil.BlockstoreGC); ok {
+			report(PruneStageGC)
+			if err := gc.CollectGarbage(blockstoreutil.WithFullGC(true)); err != nil {
+				return fmt.Errorf("failed to collect garbage: %w", err)
+			}
+		}
+	}
+
+	log.Infow("chain prune finished", "dryRun", opts.DryRun, "marked", p.Marked, "pruned", p.Pruned, "bytes", p.PrunedBytes)
+	p.Done = true
+	report(PruneStageDone)
+	return nil
+}
@@ -0,0 +1,173 @@
+package chain_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestChainPrune(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	store := builder.Store()
+	bs := builder.BlockStore()
+	head := builder.AppendManyOn(ctx, int(policy.ChainFinality)+5, builder.Genesis())
+	require.NoError(t, store.SetHead(ctx, head))
+	// the state older than the height 4 is pruned
+	ts, err := store.GetTipSet(ctx, head.Parents())
+	require.NoError(t, err)
+
+	newBlock := func(data string) blocks.Block {
+		blk := blocks.NewBlock([]byte(data))
+		require.NoError(t, bs.Put(ctx, blk))
+		return blk
+	}
+	oldOnly, shared, recent, computed := newBlock("old"), newBlock("shared"), newBlock("recent"), newBlock("computed")
+	setState := func(height abi.ChainEpoch, links ...blocks.Block) blocks.Block {
+		cids := make([]cid.Cid, 0, len(links))
+		for _, blk := range links {
+			cids = append(cids, blk.Cid())
+		}
+		root, err := cbornode.WrapObject(cids, mh.SHA2_256, -1)
+		require.NoError(t, err)
+		require.NoError(t, bs.Put(ctx, root))
+
+		tipset, err := store.GetTipSetByHeight(ctx, head, height, true)
+		require.NoError(t, err)
+		require.NoError(t, store.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+			TipSetStateRoot: root.Cid(),
+			TipSet:          tipset,
+			TipSetReceipts:  root.Cid(),
+		}))
+		return root
+	}
+	oldRoot := setState(3, oldOnly, shared, computed)
+	recentRoot := setState(head.Height()-10, shared, recent)
+	// the state computed on top of the head since ts shares an object with the old state
+	computedRoot := setState(head.Height(), computed)
+
+	// objects out of the chain, like the pending messages, are kept
+	unreachable := []blocks.Block{
+		newBlock("unreachable 1"),
+		newBlock("unreachable 2"),
+	}
+
+	prune := func(dryRun bool) types.ChainPruneProgress {
+		var last types.ChainPruneProgress
+		err := store.Prune(ctx, ts, types.ChainPruneOpts{
+			RetainState: policy.ChainFinality,
+			DryRun:      dryRun,
+		}, func(p types.ChainPruneProgress) {
+			last = p
+		})
+		require.NoError(t, err)
+		require.True(t, last.Done)
+		return last
+	}
+	requireHas := func(has bool, blks ...blocks.Block) {
+		for _, blk := range blks {
+			ok, err := bs.Has(ctx, blk.Cid())
+			require.NoError(t, err)
+			require.Equal(t, has, ok, blk.Cid())
+		}
+	}
+
+	// a dry run only reports the old state
+	p := prune(true)
+	require.EqualValues(t, 2, p.Pruned)
+	require.EqualValues(t, len(oldRoot.RawData())+len(oldOnly.RawData()), p.PrunedBytes)
+	requireHas(true, oldRoot, oldOnly)
+
+	p = prune(false)
+	require.EqualValues(t, 2, p.Pruned)
+	requireHas(false, oldRoot, oldOnly)
+	requireHas(true, shared, recent, computed, recentRoot, computedRoot)
+	requireHas(true, unreachable...)
+
+	// the chain is still complete
+	for cur := head; cur.Height() > 0; {
+		for _, blk := range cur.Blocks() {
+			has, err := bs.Has(ctx, blk.Cid())
+			require.NoError(t, err)
+			require.True(t, has)
+		}
+		parent, err := store.GetTipSet(ctx, cur.Parents())
+		require.NoError(t, err)
+		cur = parent
+	}
+	require.EqualValues(t, 0, prune(true).Pruned)
+
+	err = store.Prune(ctx, ts, types.ChainPruneOpts{RetainState: policy.ChainFinality - 1}, nil)
+	require.Error(t, err)
+}
+
+func TestChainPruneWaitsForStateComputations(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	store := builder.Store()
+	bs := builder.BlockStore()
+	head := builder.AppendManyOn(ctx, int(policy.ChainFinality)+5, builder.Genesis())
+	require.NoError(t, store.SetHead(ctx, head))
+
+	setState := func(height abi.ChainEpoch, links ...cid.Cid) {
+		root, err := cbornode.WrapObject(links, mh.SHA2_256, -1)
+		require.NoError(t, err)
+		require.NoError(t, bs.Put(ctx, root))
+
+		tipset, err := store.GetTipSetByHeight(ctx, head, height, true)
+		require.NoError(t, err)
+		require.NoError(t, store.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+			TipSetStateRoot: root.Cid(),
+			TipSet:          tipset,
+			TipSetReceipts:  root.Cid(),
+		}))
+	}
+	reused := blocks.NewBlock([]byte("reused"))
+	require.NoError(t, bs.Put(ctx, reused))
+	setState(3, reused.Cid())
+
+	unlock := store.LockStateWrites()
+	marking := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		var once sync.Once
+		done <- store.Prune(ctx, head, types.ChainPruneOpts{RetainState: policy.ChainFinality}, func(p types.ChainPruneProgress) {
+			if p.Stage == chain.PruneStageMark {
+				once.Do(func() { close(marking) })
+			}
+		})
+	}()
+	<-marking
+
+	// a state computed while the prune runs reuses an object of the old state
+	setState(head.Height(), reused.Cid())
+	select {
+	case err := <-done:
+		t.Fatalf("the prune didn't wait for the state computation: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	require.NoError(t, <-done)
+
+	has, err := bs.Has(ctx, reused.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+}
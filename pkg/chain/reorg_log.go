@@ -0,0 +1,166 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+
+	"github.com/filecoin-project/venus/pkg/journal"
+	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// ReorgLogPrefix is the datastore prefix of the reorgs recorded by the reorg log.
+var ReorgLogPrefix = datastore.NewKey("/chain/reorgs")
+
+// DefaultReorgLogSize is the number of reorgs kept by the reorg log.
+const DefaultReorgLogSize = 1000
+
+var reorgDepth = metrics.NewInt64Histogram("chain/reorg_depth", "The depth in epochs of the reorgs of the chain",
+	[]float64{1, 2, 3, 4, 5, 10, 20, 50, 100, 200, 500, 900})
+
+// ReorgLog is a bounded log of the reorgs of the chain, stored in the chain datastore.
+// A reorg is also written to the "chain" topic of the journal and to the reorg depth histogram.
+type ReorgLog struct {
+	ds   repo.Datastore
+	size uint64
+
+	lk      sync.Mutex
+	journal journal.Writer
+	// next is the sequence number of the next recorded reorg
+	next uint64
+}
+
+// NewReorgLog creates a reorg log stored in ds keeping the last size reorgs.
+func NewReorgLog(ds repo.Datastore, size int) *ReorgLog {
+	rl := &ReorgLog{
+		ds:      ds,
+		size:    uint64(size),
+		journal: journal.NewNoopJournal().Topic("chain"),
+	}
+
+	seqs, err := rl.sequences(context.TODO())
+	if err != nil {
+		log.Warnf("failed to load the reorg log: %v", err)
+		return rl
+	}
+	if len(seqs) > 0 {
+		rl.next = seqs[len(seqs)-1] + 1
+	}
+	// drop the reorgs over the size, it may have been larger
+	for _, seq := range seqs {
+		if seq+rl.size >= rl.next {
+			break
+		}
+		if err := rl.ds.Delete(context.TODO(), reorgLogKey(seq)); err != nil {
+			log.Warnf("failed to delete reorg %d: %v", seq, err)
+		}
+	}
+
+	return rl
+}
+
+func reorgLogKey(seq uint64) datastore.Key {
+	// padded for the keys to be ordered as the sequence numbers
+	return ReorgLogPrefix.ChildString(fmt.Sprintf("%020d", seq))
+}
+
+// sequences returns the sequence numbers of the recorded reorgs in ascending order.
+func (rl *ReorgLog) sequences(ctx context.Context) ([]uint64, error) {
+	res, err := rl.ds.Query(ctx, query.Query{Prefix: ReorgLogPrefix.String(), KeysOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	var seqs []uint64
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		seq, err := strconv.ParseUint(datastore.RawKey(r.Key).BaseNamespace(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reorg key %s: %w", r.Key, err)
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	return seqs, nil
+}
+
+// SetJournal sets the journal the reorgs are written to.
+func (rl *ReorgLog) SetJournal(j journal.Journal) {
+	rl.lk.Lock()
+	defer rl.lk.Unlock()
+	rl.journal = j.Topic("chain")
+}
+
+// Record records a reorg, dropping the oldest one once the log is full.
+func (rl *ReorgLog) Record(ctx context.Context, evt *types.ReorgEvent) error {
+	rl.lk.Lock()
+	defer rl.lk.Unlock()
+
+	reorgDepth.Observe(ctx, int64(evt.Depth))
+	rl.journal.Write("reorg",
+		"depth", evt.Depth,
+		"oldHead", evt.OldHead.String(),
+		"oldHeight", evt.OldHeight,
+		"newHead", evt.NewHead.String(),
+		"newHeight", evt.NewHeight,
+		"commonAncestor", evt.CommonAncestor.String(),
+		"commonAncestorHeight", evt.CommonAncestorHeight,
+	)
+
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	if err := rl.ds.Put(ctx, reorgLogKey(rl.next), val); err != nil {
+		return fmt.Errorf("failed to record reorg: %w", err)
+	}
+	if rl.next >= rl.size {
+		if err := rl.ds.Delete(ctx, reorgLogKey(rl.next-rl.size)); err != nil {
+			log.Warnf("failed to delete reorg %d: %v", rl.next-rl.size, err)
+		}
+	}
+	rl.next++
+
+	return nil
+}
+
+// List returns the last limit recorded reorgs, the latest first. All the reorgs are returned if limit is not positive.
+func (rl *ReorgLog) List(ctx context.Context, limit int) ([]*types.ReorgEvent, error) {
+	rl.lk.Lock()
+	defer rl.lk.Unlock()
+
+	seqs, err := rl.sequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > len(seqs) {
+		limit = len(seqs)
+	}
+
+	out := make([]*types.ReorgEvent, 0, limit)
+	for i := len(seqs) - 1; i >= len(seqs)-limit; i-- {
+		val, err := rl.ds.Get(ctx, reorgLogKey(seqs[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load reorg %d: %w", seqs[i], err)
+		}
+		var evt types.ReorgEvent
+		if err := json.Unmarshal(val, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode reorg %d: %w", seqs[i], err)
+		}
+		out = append(out, &evt)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestReorgHistory(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	store := builder.Store()
+
+	// genesis -> 1 -> 2 -> 3 -> 4
+	//                  \-> 3'
+	link2 := builder.AppendManyOn(ctx, 2, builder.Genesis())
+	link4 := builder.AppendManyOn(ctx, 2, link2)
+	fork3 := builder.AppendOn(ctx, link2, 2)
+
+	// extending the head isn't a reorg
+	require.NoError(t, store.SetHead(ctx, link2))
+	require.NoError(t, store.SetHead(ctx, link4))
+	reorgs, err := store.ReorgHistory(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, reorgs)
+
+	require.NoError(t, store.SetHead(ctx, fork3))
+	require.NoError(t, store.SetHead(ctx, link4))
+	reorgs, err = store.ReorgHistory(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, reorgs, 2)
+
+	link3, err := store.GetTipSet(ctx, link4.Parents())
+	require.NoError(t, err)
+	// the latest first
+	back, toFork := reorgs[0], reorgs[1]
+	assert.Equal(t, abi.ChainEpoch(2), toFork.Depth)
+	assert.Equal(t, link4.Key(), toFork.OldHead)
+	assert.Equal(t, abi.ChainEpoch(4), toFork.OldHeight)
+	assert.Equal(t, fork3.Key(), toFork.NewHead)
+	assert.Equal(t, link2.Key(), toFork.CommonAncestor)
+	assert.Equal(t, abi.ChainEpoch(2), toFork.CommonAncestorHeight)
+	assert.Equal(t, []types.TipSetKey{link4.Key(), link3.Key()}, toFork.Dropped)
+	assert.Equal(t, []types.TipSetKey{fork3.Key()}, toFork.Added)
+
+	assert.Equal(t, abi.ChainEpoch(1), back.Depth)
+	assert.Equal(t, []types.TipSetKey{fork3.Key()}, back.Dropped)
+	assert.Equal(t, []types.TipSetKey{link3.Key(), link4.Key()}, back.Added)
+
+	reorgs, err = store.ReorgHistory(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reorgs, 1)
+	assert.Equal(t, back, reorgs[0])
+}
+
+func TestReorgLogBounded(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	ds := repo.NewInMemoryRepo().ChainDatastore()
+
+	rl := chain.NewReorgLog(ds, 3)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, rl.Record(ctx, &types.ReorgEvent{Depth: abi.ChainEpoch(i)}))
+	}
+	depths := func(rl *chain.ReorgLog, limit int) []abi.ChainEpoch {
+		reorgs, err := rl.List(ctx, limit)
+		require.NoError(t, err)
+		var out []abi.ChainEpoch
+		for _, evt := range reorgs {
+			out = append(out, evt.Depth)
+		}
+		return out
+	}
+	assert.Equal(t, []abi.ChainEpoch{5, 4, 3}, depths(rl, 0))
+	assert.Equal(t, []abi.ChainEpoch{5, 4}, depths(rl, 2))
+
+	// the sequence is resumed on restart, and a smaller log drops the oldest reorgs
+	rl = chain.NewReorgLog(ds, 2)
+	assert.Equal(t, []abi.ChainEpoch{5, 4}, depths(rl, 0))
+	require.NoError(t, rl.Record(ctx, &types.ReorgEvent{Depth: 6}))
+	assert.Equal(t, []abi.ChainEpoch{6, 5}, depths(rl, 0))
+}
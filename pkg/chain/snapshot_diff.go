@@ -0,0 +1,240 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+	carv2 "github.com/ipld/go-car/v2"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// SnapshotDiffVersion is the version of the differential snapshots written by ExportDiff.
+const SnapshotDiffVersion = 1
+
+// SnapshotDiffHeader is the root object of a differential snapshot, it records the tipset the
+// snapshot applies to and the head of the chain it contains.
+type SnapshotDiffHeader struct {
+	Version uint64
+	Base    []cid.Cid
+	Head    []cid.Cid
+}
+
+// ExportDiff writes to w a car file holding the objects reachable from the tipsets of the chain
+// of ts above base which are not reachable from base: the block headers, the messages, the
+// receipts and the state trees. The single root of the car file is a SnapshotDiffHeader, which
+// is also its first block. Base must be an ancestor of ts.
+func (store *Store) ExportDiff(ctx context.Context, base, ts *types.TipSet, w io.Writer) error {
+	tipsets, err := store.diffRange(ctx, base, ts)
+	if err != nil {
+		return err
+	}
+
+	// the importer holds the messages, the receipts and the parent state of base
+	skip := cid.NewSet()
+	for _, b := range base.Blocks() {
+		for _, root := range []cid.Cid{b.Messages, b.ParentMessageReceipts, b.ParentStateRoot} {
+			if err := store.walkDag(ctx, root, skip, nil, func(cid.Cid, []byte) error { return nil }); err != nil {
+				return fmt.Errorf("walking objects of base tipset failed: %w", err)
+			}
+		}
+	}
+
+	hdrBuf := new(bytes.Buffer)
+	hdr := &SnapshotDiffHeader{Version: SnapshotDiffVersion, Base: base.Cids(), Head: ts.Cids()}
+	if err := hdr.MarshalCBOR(hdrBuf); err != nil {
+		return err
+	}
+	hdrCid, err := types.DefaultCidBuilder.Sum(hdrBuf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := car.WriteHeader(&car.CarHeader{Roots: []cid.Cid{hdrCid}, Version: 1}, w); err != nil {
+		return fmt.Errorf("failed to write car header: %w", err)
+	}
+	write := func(c cid.Cid, data []byte) error {
+		if err := carutil.LdWrite(w, c.Bytes(), data); err != nil {
+			return fmt.Errorf("failed to write block to car output: %w", err)
+		}
+		return nil
+	}
+	if err := write(hdrCid, hdrBuf.Bytes()); err != nil {
+		return err
+	}
+
+	log.Infow("diff export started", "base", base.Height(), "head", ts.Height())
+	exportStart := constants.Clock.Now()
+
+	seen := cid.NewSet()
+	for _, cur := range tipsets {
+		for _, b := range cur.Blocks() {
+			if !seen.Visit(b.Cid()) {
+				continue
+			}
+			buf := new(bytes.Buffer)
+			if err := b.MarshalCBOR(buf); err != nil {
+				return err
+			}
+			if err := write(b.Cid(), buf.Bytes()); err != nil {
+				return err
+			}
+
+			for _, root := range []cid.Cid{b.Messages, b.ParentMessageReceipts, b.ParentStateRoot} {
+				if err := store.walkDag(ctx, root, seen, skip, write); err != nil {
+					return fmt.Errorf("walking objects of block %s failed: %w", b.Cid(), err)
+				}
+			}
+		}
+	}
+
+	log.Infow("diff export finished", "tipsets", len(tipsets), "duration", constants.Clock.Now().Sub(exportStart).Seconds())
+	return nil
+}
+
+// diffRange returns the tipsets of the chain of ts above base, from ts down.
+func (store *Store) diffRange(ctx context.Context, base, ts *types.TipSet) ([]*types.TipSet, error) {
+	var tipsets []*types.TipSet
+	cur := ts
+	for cur.Height() > base.Height() {
+		tipsets = append(tipsets, cur)
+
+		var err error
+		if cur, err = store.GetTipSet(ctx, cur.Parents()); err != nil {
+			return nil, err
+		}
+	}
+	if !cur.Equals(base) {
+		return nil, fmt.Errorf("tipset %s at %d is not an ancestor of %s", base.Key(), base.Height(), ts.Key())
+	}
+	return tipsets, nil
+}
+
+// walkDag calls cb with the raw and dagcbor objects reachable from root which are not in
+// seen nor in skip, and adds them to seen.
+func (store *Store) walkDag(ctx context.Context, root cid.Cid, seen, skip *cid.Set, cb func(cid.Cid, []byte) error) error {
+	var walkErr error
+	stack := []cid.Cid{root}
+	for len(stack) > 0 && walkErr == nil {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		prefix := c.Prefix()
+		// identity objects are inlined in their cid, see WalkSnapshot
+		if prefix.MhType == mh.IDENTITY || (prefix.Codec != cid.Raw && prefix.Codec != cid.DagCBOR) {
+			continue
+		}
+		if skip != nil && skip.Has(c) {
+			continue
+		}
+		if !seen.Visit(c) {
+			continue
+		}
+
+		blk, err := store.bsstore.Get(ctx, c)
+		if err != nil {
+			return fmt.Errorf("getting object %s: %w", c, err)
+		}
+		if err := cb(c, blk.RawData()); err != nil {
+			return err
+		}
+
+		if prefix.Codec == cid.DagCBOR {
+			err = cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+				stack = append(stack, link)
+			})
+			if err != nil {
+				walkErr = fmt.Errorf("scanning for links of %s failed: %w", c, err)
+			}
+		}
+	}
+	return walkErr
+}
+
+// ImportDiff imports a differential snapshot written by ExportDiff on top of the head, which
+// must be the base tipset of the snapshot, and returns the head tipset of the snapshot. Like
+// Import, the state of the returned tipset is not computed and the caller sets it as the head.
+func (store *Store) ImportDiff(ctx context.Context, r io.Reader) (*types.TipSet, error) {
+	br, err := carv2.NewBlockReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("loadcar failed: %w", err)
+	}
+	if len(br.Roots) != 1 {
+		return nil, fmt.Errorf("not a differential snapshot, expected one root but got %d", len(br.Roots))
+	}
+
+	hdrBlk, err := br.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	var hdr SnapshotDiffHeader
+	if !hdrBlk.Cid().Equals(br.Roots[0]) || hdr.UnmarshalCBOR(bytes.NewReader(hdrBlk.RawData())) != nil {
+		return nil, fmt.Errorf("not a differential snapshot")
+	}
+	if hdr.Version != SnapshotDiffVersion {
+		return nil, fmt.Errorf("unsupported differential snapshot version %d", hdr.Version)
+	}
+
+	baseKey := types.NewTipSetKey(hdr.Base...)
+	head := store.GetHead()
+	if !head.Key().Equals(baseKey) {
+		return nil, fmt.Errorf("the snapshot applies to %s, but the head is %s", baseKey, head.Key())
+	}
+
+	var buf []blocks.Block
+	for {
+		blk, err := br.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		buf = append(buf, blk)
+		if len(buf) >= 1000 {
+			if err := store.bsstore.PutMany(ctx, buf); err != nil {
+				return nil, err
+			}
+			buf = nil
+		}
+	}
+	if err := store.bsstore.PutMany(ctx, buf); err != nil {
+		return nil, err
+	}
+
+	root, err := store.GetTipSet(ctx, types.NewTipSetKey(hdr.Head...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load head tipset from snapshot: %w", err)
+	}
+	tipsets, err := store.diffRange(ctx, head, root)
+	if err != nil {
+		return nil, err
+	}
+
+	// the state of each tipset is the parent state of its child, see Import
+	tipsets = append(tipsets, head)
+	for i := 1; i < len(tipsets); i++ {
+		child, ts := tipsets[i-1], tipsets[i]
+		if err := store.PutTipSetMetadata(ctx, &TipSetMetadata{
+			TipSetStateRoot: child.At(0).ParentStateRoot,
+			TipSet:          ts,
+			TipSetReceipts:  child.At(0).ParentMessageReceipts,
+		}); err != nil {
+			return nil, err
+		}
+		store.PersistTipSetKey(ctx, ts.Key())
+	}
+
+	log.Infof("imported differential snapshot from %d to %d", head.Height(), root.Height())
+	return root, nil
+}
@@ -0,0 +1,113 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	carv2 "github.com/ipld/go-car/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/config"
+	_ "github.com/filecoin-project/venus/pkg/crypto/secp"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestExportImportDiff(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	signer, _ := testhelpers.NewMockSignersAndKeyInfo(2)
+	newSignedMessage := testhelpers.NewSignedMessageForTestGetter(signer)
+
+	base := builder.AppendManyOn(ctx, 3, builder.Genesis())
+	msg := newSignedMessage(0)
+	link1 := builder.BuildOneOn(ctx, base, func(bb *chain.BlockBuilder) {
+		bb.AddMessages([]*types.SignedMessage{msg}, nil)
+	})
+	head := builder.AppendManyOn(ctx, 2, link1)
+	// the fake state computed by the builder is not stored
+	state, err := cbor.NewCborStore(builder.BlockStore()).Put(ctx, []cid.Cid{base.At(0).ParentStateRoot, msg.Cid()})
+	require.NoError(t, err)
+	require.Equal(t, head.At(0).ParentStateRoot, state)
+	fork := builder.AppendOn(ctx, builder.Genesis(), 1)
+	store := builder.Store()
+
+	buf := new(bytes.Buffer)
+	require.Error(t, store.ExportDiff(ctx, fork, head, buf))
+
+	buf.Reset()
+	require.NoError(t, store.ExportDiff(ctx, base, head, buf))
+	snapshot := buf.Bytes()
+
+	// the snapshot holds the new headers and messages but none of the headers of base
+	br, err := carv2.NewBlockReader(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	require.Len(t, br.Roots, 1)
+	exported := cid.NewSet()
+	for {
+		blk, err := br.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		exported.Add(blk.Cid())
+	}
+	for _, ts := range []*types.TipSet{head, link1} {
+		for _, c := range ts.Cids() {
+			require.True(t, exported.Has(c))
+		}
+	}
+	require.True(t, exported.Has(link1.At(0).Messages))
+	require.True(t, exported.Has(state))
+	require.False(t, exported.Has(base.At(0).ParentStateRoot))
+	for _, c := range base.Cids() {
+		require.False(t, exported.Has(c))
+	}
+
+	// a node at base holds every object but the ones of the snapshot
+	bs := blockstoreutil.NewMemory()
+	keys, err := builder.BlockStore().AllKeysChan(ctx)
+	require.NoError(t, err)
+	for c := range keys {
+		if exported.Has(c) {
+			continue
+		}
+		blk, err := builder.BlockStore().Get(ctx, c)
+		require.NoError(t, err)
+		require.NoError(t, bs.Put(ctx, blk))
+	}
+
+	target := chain.NewStore(repo.NewInMemoryRepo().ChainDatastore(), bs, builder.Genesis().At(0).Cid(), chain.NewMockCirculatingSupplyCalculator())
+	require.NoError(t, target.SetHead(ctx, link1))
+	_, err = target.ImportDiff(ctx, bytes.NewReader(snapshot))
+	require.Error(t, err)
+
+	require.NoError(t, target.SetHead(ctx, base))
+	root, err := target.ImportDiff(ctx, bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	require.Equal(t, head.Key(), root.Key())
+
+	parent, err := target.GetTipSet(ctx, head.Parents())
+	require.NoError(t, err)
+	meta, err := target.LoadTipsetMetadata(ctx, parent)
+	require.NoError(t, err)
+	require.Equal(t, head.At(0).ParentStateRoot, meta.TipSetStateRoot)
+	meta, err = target.LoadTipsetMetadata(ctx, base)
+	require.NoError(t, err)
+	require.Equal(t, link1.At(0).ParentStateRoot, meta.TipSetStateRoot)
+
+	secp, _, err := chain.NewMessageStore(bs, config.DefaultForkUpgradeParam).LoadMetaMessages(ctx, link1.At(0).Messages)
+	require.NoError(t, err)
+	require.Len(t, secp, 1)
+}
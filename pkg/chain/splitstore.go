@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// SplitStoreCompactor drives the online compaction of a hot/cold split blockstore.
+// Every finality it computes the live set of the chain (all headers, plus the
+// state and messages of the last HotStoreFinalities finalities) and moves
+// everything else out of the hot store.
+type SplitStoreCompactor struct {
+	store *Store
+	ss    *blockstoreutil.SplitStore
+
+	// retain is the number of epochs whose state and messages are kept in the hot store
+	retain abi.ChainEpoch
+	// interval is the number of epochs between two compactions
+	interval abi.ChainEpoch
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSplitStoreCompactor creates a compactor for ss, hotStoreFinalities is the
+// number of finalities of state kept in the hot store.
+func NewSplitStoreCompactor(store *Store, ss *blockstoreutil.SplitStore, hotStoreFinalities int) *SplitStoreCompactor {
+	if hotStoreFinalities < 1 {
+		hotStoreFinalities = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SplitStoreCompactor{
+		store:    store,
+		ss:       ss,
+		retain:   abi.ChainEpoch(hotStoreFinalities) * policy.ChainFinality,
+		interval: policy.ChainFinality,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start subscribes the compactor to head changes.
+func (c *SplitStoreCompactor) Start() {
+	c.store.SubscribeHeadChanges(c.headChange)
+}
+
+// Stop interrupts a running compaction and waits for it to return, the
+// interrupted compaction is restarted on the next head change after a restart.
+func (c *SplitStoreCompactor) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+func (c *SplitStoreCompactor) headChange(_, apply []*types.TipSet) error {
+	if len(apply) == 0 || c.ctx.Err() != nil {
+		return nil
+	}
+
+	head := apply[len(apply)-1]
+	if !c.ss.ShouldCompact(head.Height(), c.interval) {
+		return nil
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		if err := c.ss.Compact(c.ctx, head.Height(), c.walkLiveObjects(head)); err != nil {
+			log.Errorf("failed to compact split store at %d: %v", head.Height(), err)
+		}
+	}()
+
+	return nil
+}
+
+// walkLiveObjects returns a walker over the objects that must stay in the hot store when the head is ts.
+func (c *SplitStoreCompactor) walkLiveObjects(ts *types.TipSet) blockstoreutil.LiveObjectWalker {
+	return func(ctx context.Context, cb func(cid.Cid) error) error {
+		return c.store.WalkLiveObjects(ctx, ts, c.retain, true, cb)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/ipld/go-car"
@@ -34,6 +35,7 @@ import (
 	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
 
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/metrics/tracing"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/state"
@@ -79,6 +81,10 @@ type reorg struct {
 // CheckPoint is the key which the check-point written in the datastore.
 var CheckPoint = datastore.NewKey("/chain/checkPoint")
 
+// PinnedCheckPoint is the key which the checkpoint pinned by the operator is written in the datastore,
+// unlike the check-point written by the snapshot imports, the chains which don't include it are refused.
+var PinnedCheckPoint = datastore.NewKey("/chain/pinnedCheckPoint")
+
 // TSState export this func is just for gen cbor tool to work
 type TSState struct {
 	StateRoot cid.Cid
@@ -104,10 +110,15 @@ type Store struct {
 	// head is the tipset at the head of the best known chain.
 	head *types.TipSet
 
-	checkPoint types.TipSetKey
-	// Protects head and genesisCid.
+	checkPoint       types.TipSetKey
+	pinnedCheckPoint types.TipSetKey
+	// Protects head, genesisCid, checkPoint and pinnedCheckPoint.
 	mu sync.RWMutex
 
+	// stateLk is read locked by the state computations and locked by the sweep of a prune, so that
+	// no computation writes or reuses an object of the old state while it is deleted.
+	stateLk sync.RWMutex
+
 	// headEvents is a pubsub channel that publishes an event every time the head changes.
 	// We operate under the assumption that tipsets published to this channel
 	// will always be queued and delivered to subscribers in the order discovered.
@@ -124,11 +135,18 @@ type Store struct {
 	circulatingSupplyCalculator ICirculatingSupplyCalcualtor
 
 	chainIndex *ChainIndex
+	// heightIndex is the persistent height index of the canonical chain
+	heightIndex *HeightIndex
 
 	reorgCh        chan reorg
 	reorgNotifeeCh chan ReorgNotifee
+	// reorgLog records the reorgs of the chain
+	reorgLog *ReorgLog
 
 	tsCache *lru.ARCCache[types.TipSetKey, *types.TipSet]
+
+	// pruning is set while a chain prune is running
+	pruning int32
 }
 
 // NewStore constructs a new default store.
@@ -144,14 +162,17 @@ func NewStore(chainDs repo.Datastore,
 		bsstore:             bsstore,
 		headEvents:          pubsub.New(64),
 
-		checkPoint:     types.EmptyTSK,
-		genesis:        genesisCid,
-		reorgNotifeeCh: make(chan ReorgNotifee),
-		tsCache:        tsCache,
+		checkPoint:       types.EmptyTSK,
+		pinnedCheckPoint: types.EmptyTSK,
+		genesis:          genesisCid,
+		reorgNotifeeCh:   make(chan ReorgNotifee),
+		tsCache:          tsCache,
 	}
 	// todo cycle reference , may think a better idea
 	store.tipIndex = NewTipStateCache(store)
 	store.chainIndex = NewChainIndex(store.GetTipSet)
+	store.heightIndex = NewHeightIndex(chainDs, store.GetTipSet)
+	store.reorgLog = NewReorgLog(chainDs, DefaultReorgLogSize)
 	store.circulatingSupplyCalculator = circulatiingSupplyCalculator
 
 	val, err := store.ds.Get(context.TODO(), CheckPoint)
@@ -161,6 +182,10 @@ func NewStore(chainDs repo.Datastore,
 		_ = store.checkPoint.UnmarshalCBOR(bytes.NewReader(val)) //nolint:staticcheck
 	}
 	log.Infof("check point value: %v", store.checkPoint)
+	if val, err := store.ds.Get(context.TODO(), PinnedCheckPoint); err == nil {
+		_ = store.pinnedCheckPoint.UnmarshalCBOR(bytes.NewReader(val)) //nolint:staticcheck
+		log.Infof("pinned check point value: %v", store.pinnedCheckPoint)
+	}
 
 	store.reorgCh = store.reorgWorker(context.TODO())
 	return store
@@ -359,9 +384,18 @@ func (store *Store) GetTipSetByHeight(ctx context.Context, ts *types.TipSet, h a
 		return ts, nil
 	}
 
-	lbts, err := store.chainIndex.GetTipSetByHeight(ctx, ts, h)
-	if err != nil {
-		return nil, err
+	var lbts *types.TipSet
+	if tsk, ok := store.heightIndex.Lookup(ctx, ts, h); ok {
+		if indexed, err := store.GetTipSet(ctx, tsk); err == nil && indexed.Height() >= h {
+			lbts = indexed
+		}
+	}
+	var err error
+	if lbts == nil {
+		lbts, err = store.chainIndex.GetTipSetByHeight(ctx, ts, h)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if lbts.Height() < h {
@@ -566,6 +600,9 @@ func (store *Store) SetHead(ctx context.Context, newTS *types.TipSet) error {
 		}
 
 		// Ensure consistency by storing this new head on disk.
+		if errInner := store.heightIndex.Update(ctx, newTS, added); errInner != nil {
+			log.Errorf("failed to update height index: %v", errInner)
+		}
 		if errInner := store.writeHead(ctx, newTS.Key()); errInner != nil {
 			return nil, nil, false, errors.Wrap(errInner, "failed to write new Head to datastore")
 		}
@@ -583,6 +620,12 @@ func (store *Store) SetHead(ctx context.Context, newTS *types.TipSet) error {
 	// todo wrap by go function
 	Reverse(added)
 
+	if len(dropped) > 0 {
+		if err := store.recordReorg(ctx, newTS, dropped, added); err != nil {
+			log.Errorf("failed to record reorg: %v", err)
+		}
+	}
+
 	// do reorg
 	store.reorgCh <- reorg{
 		old: dropped,
@@ -592,6 +635,76 @@ func (store *Store) SetHead(ctx context.Context, newTS *types.TipSet) error {
 	return nil
 }
 
+// ResetHead switches the head to newTS as if there was no head before: the tipsets of the current
+// chain aren't reverted and only newTS is applied. It is used to jump to a chain which isn't known
+// down to its common ancestor with the current head, like the chain of a fast sync.
+func (store *Store) ResetHead(ctx context.Context, newTS *types.TipSet) error {
+	log.Infof("ResetHead %s %d", newTS.String(), newTS.Height())
+
+	err := func() error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		if errInner := store.heightIndex.Update(ctx, newTS, []*types.TipSet{newTS}); errInner != nil {
+			log.Errorf("failed to update height index: %v", errInner)
+		}
+		if errInner := store.writeHead(ctx, newTS.Key()); errInner != nil {
+			return errors.Wrap(errInner, "failed to write new Head to datastore")
+		}
+		store.head = newTS
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	store.reorgCh <- reorg{
+		new: []*types.TipSet{newTS},
+	}
+	return nil
+}
+
+// recordReorg records a head change dropping tipsets in the reorg log, dropped are ordered
+// from the old head down and added up to the new head.
+func (store *Store) recordReorg(ctx context.Context, newHead *types.TipSet, dropped, added []*types.TipSet) error {
+	ancestor, err := store.GetTipSet(ctx, dropped[len(dropped)-1].Parents())
+	if err != nil {
+		return fmt.Errorf("failed to load common ancestor: %w", err)
+	}
+	oldHead := dropped[0]
+
+	evt := &types.ReorgEvent{
+		Time:                 time.Now(),
+		Depth:                oldHead.Height() - ancestor.Height(),
+		OldHead:              oldHead.Key(),
+		OldHeight:            oldHead.Height(),
+		NewHead:              newHead.Key(),
+		NewHeight:            newHead.Height(),
+		CommonAncestor:       ancestor.Key(),
+		CommonAncestorHeight: ancestor.Height(),
+		Dropped:              make([]types.TipSetKey, 0, len(dropped)),
+		Added:                make([]types.TipSetKey, 0, len(added)),
+	}
+	for _, ts := range dropped {
+		evt.Dropped = append(evt.Dropped, ts.Key())
+	}
+	for _, ts := range added {
+		evt.Added = append(evt.Added, ts.Key())
+	}
+
+	return store.reorgLog.Record(ctx, evt)
+}
+
+// SetJournal sets the journal the reorgs of the chain are written to.
+func (store *Store) SetJournal(j journal.Journal) {
+	store.reorgLog.SetJournal(j)
+}
+
+// ReorgHistory returns the last limit reorgs of the chain, the latest first.
+func (store *Store) ReorgHistory(ctx context.Context, limit int) ([]*types.ReorgEvent, error) {
+	return store.reorgLog.List(ctx, limit)
+}
+
 func (store *Store) PersistTipSetKey(ctx context.Context, key types.TipSetKey) {
 	tskBlk, err := key.ToStorageBlock()
 	if err != nil {
@@ -1072,11 +1185,17 @@ func (store *Store) Import(ctx context.Context, r io.Reader) (*types.TipSet, err
 		curTipset = curParentTipset
 	}
 
+	if err := store.heightIndex.Rebuild(ctx, root); err != nil {
+		return nil, fmt.Errorf("failed to rebuild height index: %w", err)
+	}
+
 	return root, nil
 }
 
 // SetCheckPoint set current checkpoint
 func (store *Store) SetCheckPoint(checkPoint types.TipSetKey) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
 	store.checkPoint = checkPoint
 }
 
@@ -1213,13 +1332,46 @@ func (store *Store) getCirculatingSupply(ctx context.Context, height abi.ChainEp
 	return circ, nil
 }
 
+// LockStateWrites holds off the sweep of a prune until the returned function is called, the state
+// computations writing to the blockstore run between the two calls.
+func (store *Store) LockStateWrites() func() {
+	store.stateLk.RLock()
+	return store.stateLk.RUnlock
+}
+
+// SetPinnedCheckPoint writes the checkpoint pinned by the operator to disk and sets it.
+func (store *Store) SetPinnedCheckPoint(ctx context.Context, tsk types.TipSetKey) error {
+	buf := new(bytes.Buffer)
+	if err := tsk.MarshalCBOR(buf); err != nil {
+		return err
+	}
+	if err := store.ds.Put(ctx, PinnedCheckPoint, buf.Bytes()); err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.pinnedCheckPoint = tsk
+	return nil
+}
+
+// GetPinnedCheckPoint returns the checkpoint pinned by the operator, or an empty key if none is.
+func (store *Store) GetPinnedCheckPoint() types.TipSetKey {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.pinnedCheckPoint
+}
+
 // GetCheckPoint get the check point from store or disk.
 func (store *Store) GetCheckPoint() types.TipSetKey {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
 	return store.checkPoint
 }
 
 // Stop stops all activities and cleans up.
 func (store *Store) Stop() {
+	store.heightIndex.Close()
 	store.headEvents.Shutdown()
 }
 
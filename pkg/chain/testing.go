@@ -701,6 +701,24 @@ func (e *FakeStateEvaluator) RunStateTransition(ctx context.Context, ts *types.T
 	return rootCid, receiptCid, nil
 }
 
+// RunStateTransitionInStore delegates to StateBuilder.ComputeState, and writes the receipts to bs
+func (e *FakeStateEvaluator) RunStateTransitionInStore(ctx context.Context, ts *types.TipSet, bs blockstoreutil.Blockstore) (cid.Cid, cid.Cid, error) {
+	blockMessageInfo, err := e.MessageStore.LoadTipSetMessage(ctx, ts)
+	if err != nil {
+		return cid.Undef, cid.Undef, fmt.Errorf("failed to gather message in tipset %v", err)
+	}
+	rootCid, receipts, err := e.ComputeState(ts.At(0).ParentStateRoot, blockMessageInfo)
+	if err != nil {
+		return cid.Undef, cid.Undef, errors.Wrap(err, "error compute state")
+	}
+
+	receiptCid, err := e.MessageStore.WithBlockstore(bs).StoreReceipts(ctx, receipts)
+	if err != nil {
+		return cid.Undef, cid.Undef, fmt.Errorf("failed to save receipt: %v", err)
+	}
+	return rootCid, receiptCid, nil
+}
+
 func (e *FakeStateEvaluator) ValidateFullBlock(ctx context.Context, blk *types.BlockHeader) error {
 	parent, err := e.ChainStore.GetTipSet(ctx, types.NewTipSetKey(blk.Parents...))
 	if err != nil {
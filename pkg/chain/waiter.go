@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/msgindex"
 	"github.com/filecoin-project/venus/pkg/vm"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/ipfs/go-cid"
@@ -27,6 +28,7 @@ type MsgLookup struct {
 type waiterChainReader interface {
 	GetHead() *types.TipSet
 	GetTipSet(context.Context, types.TipSetKey) (*types.TipSet, error)
+	GetTipSetByHeight(context.Context, *types.TipSet, abi.ChainEpoch, bool) (*types.TipSet, error)
 	LookupID(context.Context, *types.TipSet, address.Address) (address.Address, error)
 	GetActorAt(context.Context, *types.TipSet, address.Address) (*types.Actor, error)
 	GetTipSetReceiptsRoot(context.Context, *types.TipSet) (cid.Cid, error)
@@ -38,6 +40,11 @@ type IStmgr interface {
 	RunStateTransition(context.Context, *types.TipSet, vm.ExecCallBack, bool) (root cid.Cid, receipts cid.Cid, err error)
 }
 
+// MessageIndex locates the tipset including a message.
+type MessageIndex interface {
+	GetMsgInfo(ctx context.Context, m cid.Cid) (msgindex.MsgInfo, error)
+}
+
 // Waiter waits for a message to appear on chain.
 type Waiter struct {
 	chainReader     waiterChainReader
@@ -45,6 +52,8 @@ type Waiter struct {
 	cst             cbor.IpldStore
 	bs              bstore.Blockstore
 	Stmgr           IStmgr
+	// msgIndex is optional, it is used before walking back the chain
+	msgIndex MessageIndex
 }
 
 // WaitPredicate is a function that identifies a message and returns true when found.
@@ -60,6 +69,11 @@ func NewWaiter(chainStore waiterChainReader, messages MessageProvider, bs bstore
 	}
 }
 
+// SetMessageIndex sets the index used to find a message before walking back the chain.
+func (w *Waiter) SetMessageIndex(msgIndex MessageIndex) {
+	w.msgIndex = msgIndex
+}
+
 // Find searches the blockchain history (but doesn't wait).
 func (w *Waiter) Find(ctx context.Context, msg types.ChainMsg, lookback abi.ChainEpoch, ts *types.TipSet, allowReplaced bool) (*types.ChainMessage, bool, error) {
 	if ts == nil {
@@ -108,6 +122,15 @@ func (w *Waiter) findMessage(ctx context.Context, from *types.TipSet, m types.Ch
 	limitHeight := from.Height() - lookback
 	noLimit := lookback == constants.LookbackNoLimit
 
+	if w.msgIndex != nil {
+		msg, found, err := w.findIndexedMessage(ctx, from, m, limitHeight, noLimit, allowReplaced)
+		if err != nil {
+			log.Warnf("failed to find message %s in message index: %v", m.Cid(), err)
+		} else if found {
+			return msg, true, nil
+		}
+	}
+
 	cur := from
 	curActor, err := w.Stmgr.GetActorAt(ctx, m.VMMessage().From, cur)
 	if err != nil {
@@ -164,6 +187,42 @@ func (w *Waiter) findMessage(ctx context.Context, from *types.TipSet, m types.Ch
 	}
 }
 
+// findIndexedMessage looks for the message in the message index, the message is only found if
+// the tipset including it is in the chain of from and has been executed within the lookback.
+func (w *Waiter) findIndexedMessage(ctx context.Context, from *types.TipSet, m types.ChainMsg, limitHeight abi.ChainEpoch, noLimit, allowReplaced bool) (*types.ChainMessage, bool, error) {
+	info, err := w.msgIndex.GetMsgInfo(ctx, m.Cid())
+	if err != nil {
+		if errors.Is(err, msgindex.ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	// the message has not been executed yet in the chain of from
+	if info.Epoch >= from.Height() {
+		return nil, false, nil
+	}
+
+	inclTS, err := w.chainReader.GetTipSetByHeight(ctx, from, info.Epoch, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if !inclTS.Key().Equals(info.TipSet) {
+		// the tipset including the message has been reverted
+		return nil, false, nil
+	}
+
+	execTS, err := w.chainReader.GetTipSetByHeight(ctx, from, info.Epoch+1, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if !noLimit && execTS.Height() <= limitHeight {
+		return nil, false, nil
+	}
+
+	return w.receiptForTipset(ctx, execTS, m, allowReplaced)
+}
+
 // waitForMessage looks for a matching message in a channel of tipsets and returns
 // the message, block and receipt, when it is found. Reads until the channel is
 // closed or the context done. Returns the found message/block (or nil if the
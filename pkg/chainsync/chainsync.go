@@ -32,6 +32,7 @@ var _ = (BlockProposer)((*dispatcher.Dispatcher)(nil))
 // Manager sync the chain.
 type Manager struct {
 	dispatcher *dispatcher.Dispatcher
+	syncer     *syncer.Syncer
 }
 
 // NewManager creates a new chain sync manager.
@@ -44,16 +45,18 @@ func NewManager(
 	exchangeClient exchange.Client,
 	c clock.Clock,
 	fork fork.IFork,
+	badTipSets *types.BadTipSetCache,
 ) (Manager, error) {
 	chainSyncer, err := syncer.NewSyncer(stmgr, hv, cs, submodule.ChainReader,
 		submodule.MessageStore, bsstore,
-		exchangeClient, c, fork)
+		exchangeClient, c, fork, badTipSets)
 	if err != nil {
 		return Manager{}, err
 	}
 
 	return Manager{
 		dispatcher: dispatcher.NewDispatcher(chainSyncer),
+		syncer:     chainSyncer,
 	}, nil
 }
 
@@ -63,7 +66,27 @@ func (m *Manager) Start(ctx context.Context) error {
 	return nil
 }
 
+// StartAfter starts the chain sync manager, the chains received are only synced once ready is closed.
+func (m *Manager) StartAfter(ctx context.Context, ready <-chan struct{}) {
+	m.dispatcher.StartAfter(ctx, ready)
+}
+
 // BlockProposer returns the block proposer.
 func (m *Manager) BlockProposer() BlockProposer {
 	return m.dispatcher
 }
+
+// SetPipelineDepth sets the number of segments whose messages are fetched ahead of the segment being executed.
+func (m *Manager) SetPipelineDepth(depth int) {
+	m.syncer.SetPipelineDepth(depth)
+}
+
+// OnValidatedBlock sets the function called with the header of every block validated by the syncer.
+func (m *Manager) OnValidatedBlock(fn func(*types2.BlockHeader)) {
+	m.syncer.OnValidatedBlock(fn)
+}
+
+// SyncCheckpoint pins the tipset as the checkpoint of the chain, switching the head to it if needed.
+func (m *Manager) SyncCheckpoint(ctx context.Context, tsk types2.TipSetKey) error {
+	return m.syncer.SyncCheckpoint(ctx, tsk)
+}
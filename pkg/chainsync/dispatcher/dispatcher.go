@@ -123,6 +123,19 @@ func (d *Dispatcher) Start(syncingCtx context.Context) {
 	go d.syncWorker(syncingCtx)
 }
 
+// StartAfter starts the dispatcher, the incoming targets are queued but not synced until ready is closed.
+func (d *Dispatcher) StartAfter(syncingCtx context.Context, ready <-chan struct{}) {
+	go d.processIncoming(syncingCtx)
+
+	go func() {
+		select {
+		case <-ready:
+			d.syncWorker(syncingCtx)
+		case <-syncingCtx.Done():
+		}
+	}()
+}
+
 func (d *Dispatcher) processIncoming(ctx context.Context) {
 	defer func() {
 		log.Info("exiting sync dispatcher")
@@ -211,7 +224,7 @@ func (d *Dispatcher) syncWorker(ctx context.Context) {
 				lastTarget = syncTarget
 				if d.conCurrent.Get() < d.maxCount {
 					atmoic2.StoreInt64(&unsolvedNotify, 0)
-					syncTarget.State = types.StateInSyncing
+					d.workTracker.SetState(syncTarget, types.StateInSyncing, nil)
 					ctx, cancel := context.WithCancel(ctx)
 					d.cancelControler.PushBack(cancel)
 					d.conCurrent.Add(1)
@@ -219,6 +232,9 @@ func (d *Dispatcher) syncWorker(ctx context.Context) {
 						err := d.syncer.HandleNewTipSet(ctx, syncTarget)
 						if err != nil {
 							log.Infof("failed sync of %v at %d  %s", syncTarget.Head.Key(), syncTarget.Head.Height(), err)
+							d.workTracker.SetState(syncTarget, types.StageSyncErrored, err)
+						} else {
+							d.workTracker.SetState(syncTarget, types.StageSyncComplete, nil)
 						}
 						d.workTracker.Remove(syncTarget)
 						d.registeredCb(syncTarget, err)
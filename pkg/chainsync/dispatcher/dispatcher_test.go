@@ -149,6 +149,22 @@ func TestQueueEmptyPopErrors(t *testing.T) {
 	assert.Equal(t, 0, testQ.Len())
 }
 
+func TestQueueBucketsAreSnapshots(t *testing.T) {
+	tf.UnitTest(t)
+	testQ := syncTypes.NewTargetTracker(20)
+	sR0 := &syncTypes.Target{ChainInfo: *(chainInfoWithHeightAndWeight(t, 0, 1001))}
+	testQ.Add(sR0)
+
+	buckets := testQ.Buckets()
+	require.Len(t, buckets, 1)
+	assert.Equal(t, syncTypes.StageIdle, buckets[0].State)
+
+	// the state set afterwards doesn't change the returned copies
+	testQ.SetState(sR0, syncTypes.StateInSyncing, nil)
+	assert.Equal(t, syncTypes.StageIdle, buckets[0].State)
+	assert.Equal(t, syncTypes.StateInSyncing, testQ.Buckets()[0].State)
+}
+
 // requirePop is a helper requiring that pop does not error
 func requirePop(t *testing.T, q *syncTypes.TargetTracker) *syncTypes.Target {
 	req, popped := q.Select()
@@ -0,0 +1,136 @@
+package fastsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/net/exchange"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	exchange2 "github.com/filecoin-project/venus/venus-shared/libp2p/exchange"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var log = logging.Logger("chainsync.fastsync")
+
+// PrefetchedKey is the key of the meta datastore recording that the state of the fast synced chain was prefetched.
+var PrefetchedKey = datastore.NewKey("/fastsync/prefetched")
+
+// retryInterval is the time waited before requesting the headers again after a failure, like
+// when no peer is connected yet.
+var retryInterval = 5 * time.Second
+
+// Sync fetches the headers of the chain of the trusted tipset tsk from the network, down to the
+// genesis or to depth epochs below tsk if depth is positive, and makes tsk the head and the
+// checkpoint of the chain. The state roots and receipts of the fetched tipsets are the ones
+// recorded by their children, the state itself isn't fetched: it is read from the network as it
+// is touched when the blockstore is lazy. The chain of tsk is authenticated by the key of tsk.
+//
+// The sync is skipped if tsk is already the checkpoint of the chain.
+func Sync(ctx context.Context, cs *chain.Store, client exchange.Client, tsk types.TipSetKey, depth abi.ChainEpoch) (*types.TipSet, error) {
+	if tsk.IsEmpty() {
+		return nil, fmt.Errorf("empty tipset key")
+	}
+	// the store loads the state roots of the last finality on startup
+	if depth > 0 && depth < policy.ChainFinality {
+		return nil, fmt.Errorf("the depth must be at least the finality, %d epochs", policy.ChainFinality)
+	}
+	if cs.GetCheckPoint().Equals(tsk) {
+		log.Infof("fast sync to %s already done", tsk)
+		return cs.GetTipSet(ctx, tsk)
+	}
+
+	log.Infof("start fast sync to %s", tsk)
+	start := time.Now()
+
+	var head, child *types.TipSet
+	cur := tsk
+	for done := false; !done; {
+		count := int(exchange2.MaxRequestLength)
+		if head != nil && depth > 0 {
+			if left := int(child.Height() - (head.Height() - depth)); left < count {
+				count = left
+			}
+		}
+
+		tipsets, err := fetchHeaders(ctx, client, cur, count)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ts := range tipsets {
+			if !ts.Key().Equals(cur) {
+				return nil, fmt.Errorf("expected tipset %s from the network, got %s", cur, ts.Key())
+			}
+			for _, blk := range ts.Blocks() {
+				if _, err := cs.PutObject(ctx, blk); err != nil {
+					return nil, fmt.Errorf("failed to save block %s: %w", blk.Cid(), err)
+				}
+			}
+
+			if head == nil {
+				head = ts
+			} else {
+				if err := cs.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+					TipSet:          ts,
+					TipSetStateRoot: child.At(0).ParentStateRoot,
+					TipSetReceipts:  child.At(0).ParentMessageReceipts,
+				}); err != nil {
+					return nil, err
+				}
+				cs.PersistTipSetKey(ctx, ts.Key())
+			}
+			child = ts
+
+			if ts.Height() == 0 {
+				if !ts.Key().Equals(types.NewTipSetKey(cs.GenesisCid())) {
+					return nil, fmt.Errorf("the chain of %s has genesis %s, expected %s", tsk, ts.Key(), cs.GenesisCid())
+				}
+				done = true
+				break
+			}
+			if depth > 0 && ts.Height() <= head.Height()-depth {
+				done = true
+				break
+			}
+			cur = ts.Parents()
+		}
+		log.Infow("fast sync fetched headers", "height", child.Height(), "head", head.Height())
+	}
+
+	if err := cs.ResetHead(ctx, head); err != nil {
+		return nil, err
+	}
+	if err := cs.WriteCheckPoint(ctx, tsk); err != nil {
+		return nil, err
+	}
+	cs.SetCheckPoint(tsk)
+
+	log.Infof("fast sync to %s at %d finished in %s, headers fetched down to %d", tsk, head.Height(), time.Since(start), child.Height())
+	return head, nil
+}
+
+// fetchHeaders fetches count tipsets from tsk down, retrying until it succeeds or ctx is done.
+func fetchHeaders(ctx context.Context, client exchange.Client, tsk types.TipSetKey, count int) ([]*types.TipSet, error) {
+	for {
+		tipsets, err := client.GetBlocks(ctx, tsk, count)
+		if err == nil && len(tipsets) > 0 {
+			return tipsets, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no tipset returned")
+		}
+		log.Warnf("failed to fetch the headers from %s, retrying: %v", tsk, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
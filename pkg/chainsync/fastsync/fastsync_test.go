@@ -0,0 +1,84 @@
+package fastsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+func newEmptyStore(t *testing.T, builder *chain.Builder) *chain.Store {
+	ctx := context.Background()
+	genesis := builder.Genesis()
+	cs := chain.NewStore(repo.NewInMemoryRepo().ChainDatastore(), blockstoreutil.NewTemporarySync(), genesis.At(0).Cid(), chain.NewMockCirculatingSupplyCalculator())
+	_, err := cs.PutObject(ctx, genesis.At(0))
+	require.NoError(t, err)
+	require.NoError(t, cs.SetHead(ctx, genesis))
+	return cs
+}
+
+func TestSync(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	head := builder.AppendManyOn(ctx, int(policy.ChainFinality)+20, builder.Genesis())
+
+	t.Run("to genesis", func(t *testing.T) {
+		cs := newEmptyStore(t, builder)
+		ts, err := Sync(ctx, cs, builder, head.Key(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, head.Key(), ts.Key())
+		assert.Equal(t, head.Key(), cs.GetHead().Key())
+		assert.Equal(t, head.Key(), cs.GetCheckPoint())
+
+		// the state roots of the tipsets are recorded by their children
+		child := head
+		for child.Height() > 1 {
+			parent, err := cs.GetTipSet(ctx, child.Parents())
+			require.NoError(t, err)
+			root, err := cs.GetTipSetStateRoot(ctx, parent)
+			require.NoError(t, err)
+			assert.Equal(t, child.At(0).ParentStateRoot, root)
+			child = parent
+		}
+		ts, err = cs.GetTipSetByHeight(ctx, head, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, abi.ChainEpoch(1), ts.Height())
+
+		// done once
+		ts, err = Sync(ctx, cs, nil, head.Key(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, head.Key(), ts.Key())
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		cs := newEmptyStore(t, builder)
+		_, err := Sync(ctx, cs, builder, head.Key(), policy.ChainFinality-1)
+		require.Error(t, err)
+
+		_, err = Sync(ctx, cs, builder, head.Key(), policy.ChainFinality)
+		require.NoError(t, err)
+		assert.Equal(t, head.Key(), cs.GetHead().Key())
+
+		lowest, err := builder.GetTipSetByHeight(ctx, head, head.Height()-policy.ChainFinality, false)
+		require.NoError(t, err)
+		_, err = cs.GetTipSet(ctx, lowest.Key())
+		require.NoError(t, err)
+		_, err = cs.GetTipSet(ctx, lowest.Parents())
+		require.Error(t, err)
+
+		// the store can be loaded
+		require.NoError(t, cs.Load(ctx))
+		assert.Equal(t, head.Key(), cs.GetHead().Key())
+	})
+}
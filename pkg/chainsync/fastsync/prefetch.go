@@ -0,0 +1,146 @@
+package fastsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/sync/errgroup"
+
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+// DefaultPrefetchConcurrency is the number of objects fetched in parallel by the prefetcher.
+const DefaultPrefetchConcurrency = 64
+
+// Prefetcher fetches in background the objects of a state tree missing from a lazy blockstore,
+// so the state of a node which fast synced eventually becomes local.
+type Prefetcher struct {
+	bs          *blockstoreutil.LazyStore
+	concurrency int
+}
+
+// NewPrefetcher creates a prefetcher fetching concurrency objects in parallel into bs.
+func NewPrefetcher(bs *blockstoreutil.LazyStore, concurrency int) *Prefetcher {
+	if concurrency <= 0 {
+		concurrency = DefaultPrefetchConcurrency
+	}
+	return &Prefetcher{
+		bs:          bs,
+		concurrency: concurrency,
+	}
+}
+
+// Prefetch walks the dag of root, fetching the objects missing from the local store. It returns
+// the number of objects walked and the number of objects fetched.
+func (p *Prefetcher) Prefetch(ctx context.Context, root cid.Cid) (int, int, error) {
+	var (
+		lk      sync.Mutex
+		walked  int
+		fetched int
+	)
+
+	seen := cid.NewSet()
+	seen.Add(root)
+	frontier := []cid.Cid{root}
+	for len(frontier) > 0 {
+		var next []cid.Cid
+		eg, egCtx := errgroup.WithContext(ctx)
+		eg.SetLimit(p.concurrency)
+		for _, c := range frontier {
+			c := c
+			eg.Go(func() error {
+				links, wasFetched, err := p.visit(egCtx, c)
+				if err != nil {
+					return err
+				}
+
+				lk.Lock()
+				defer lk.Unlock()
+				next = append(next, links...)
+				walked++
+				if wasFetched {
+					fetched++
+					if fetched%100000 == 0 {
+						log.Infow("prefetching state", "root", root, "walked", walked, "fetched", fetched)
+					}
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return walked, fetched, err
+		}
+
+		frontier = frontier[:0]
+		for _, c := range next {
+			if seen.Visit(c) {
+				frontier = append(frontier, c)
+			}
+		}
+	}
+
+	return walked, fetched, nil
+}
+
+// visit reads c, fetching it if it is missing, and returns its links.
+func (p *Prefetcher) visit(ctx context.Context, c cid.Cid) ([]cid.Cid, bool, error) {
+	// only raw and dagcbor objects are part of the state, see WalkSnapshot
+	prefix := c.Prefix()
+	if prefix.MhType == mh.IDENTITY || (prefix.Codec != cid.Raw && prefix.Codec != cid.DagCBOR) {
+		return nil, false, nil
+	}
+
+	has, err := p.bs.Local().Has(ctx, c)
+	if err != nil {
+		return nil, false, err
+	}
+	if has && prefix.Codec == cid.Raw {
+		return nil, false, nil
+	}
+
+	blk, err := p.bs.Get(ctx, c)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", c, err)
+	}
+	if prefix.Codec != cid.DagCBOR {
+		return nil, !has, nil
+	}
+
+	var links []cid.Cid
+	if err := cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+		links = append(links, link)
+	}); err != nil {
+		return nil, false, fmt.Errorf("scanning for links of %s failed: %w", c, err)
+	}
+	return links, !has, nil
+}
+
+// Run prefetches the dag of root until it is local or ctx is done, a failed walk is retried
+// after retryInterval. It returns an error only if ctx is done.
+func (p *Prefetcher) Run(ctx context.Context, root cid.Cid) error {
+	log.Infof("start prefetching state %s", root)
+	start := time.Now()
+	for {
+		walked, fetched, err := p.Prefetch(ctx, root)
+		if err == nil {
+			log.Infow("finished prefetching state", "root", root, "walked", walked, "fetched", fetched, "took", time.Since(start))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Warnw("failed to prefetch state, retrying", "root", root, "walked", walked, "fetched", fetched, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package fastsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+type storeGetter struct {
+	bs blockstoreutil.Blockstore
+}
+
+func (g storeGetter) GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	return g.bs.Get(ctx, c)
+}
+
+func TestPrefetch(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	remote := blockstoreutil.NewTemporarySync()
+	cst := cbor.NewCborStore(remote)
+
+	// a dag of 3 levels, with a raw leaf shared by two nodes
+	data := []byte("leaf")
+	rawCid := cid.NewCidV1(cid.Raw, blocks.NewBlock(data).Cid().Hash())
+	require.NoError(t, remote.Put(ctx, mustBlock(t, rawCid, data)))
+
+	var children []cid.Cid
+	for i := 0; i < 3; i++ {
+		c, err := cst.Put(ctx, []interface{}{i, rawCid})
+		require.NoError(t, err)
+		children = append(children, c)
+	}
+	root, err := cst.Put(ctx, children)
+	require.NoError(t, err)
+
+	local := blockstoreutil.NewTemporarySync()
+	// one node is already local
+	blk, err := remote.Get(ctx, children[0])
+	require.NoError(t, err)
+	require.NoError(t, local.Put(ctx, blk))
+
+	lazy := blockstoreutil.NewLazyStore(local)
+	lazy.SetGetter(storeGetter{bs: remote})
+	walked, fetched, err := NewPrefetcher(lazy, 2).Prefetch(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, 5, walked)
+	assert.Equal(t, 4, fetched)
+
+	for _, c := range append(children, root, rawCid) {
+		has, err := local.Has(ctx, c)
+		require.NoError(t, err)
+		assert.True(t, has)
+	}
+
+	// everything is local
+	walked, fetched, err = NewPrefetcher(lazy, 2).Prefetch(ctx, root)
+	require.NoError(t, err)
+	assert.Equal(t, 5, walked)
+	assert.Equal(t, 0, fetched)
+}
+
+func mustBlock(t *testing.T, c cid.Cid, data []byte) blocks.Block {
+	blk, err := blocks.NewBlockWithCid(data, c)
+	require.NoError(t, err)
+	return blk
+}
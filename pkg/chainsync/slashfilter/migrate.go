@@ -0,0 +1,50 @@
+package slashfilter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// The types of the slash filter backends.
+const (
+	TypeLocal  = "local"
+	TypeMySQL  = "mysql"
+	TypeSqlite = "sqlite"
+)
+
+// NewSlashFilter creates the slash filter of the given type, with the databases of the repo. The
+// types other than local and sqlite use mysql.
+func NewSlashFilter(typ string, r repo.Repo) (ISlashFilter, error) {
+	switch typ {
+	case TypeLocal:
+		return NewLocalSlashFilter(r.ChainDatastore()), nil
+	case TypeSqlite:
+		sqlitePath, err := r.SqlitePath()
+		if err != nil {
+			return nil, err
+		}
+		return NewSqliteSlashFilter(filepath.Join(sqlitePath, "slashfilter.db"))
+	default:
+		return NewMysqlSlashFilter(r.Config().SlashFilterDs.MySQL)
+	}
+}
+
+// Migrate copies all the blocks recorded by from to to, the blocks already recorded by to for a
+// miner at an epoch are kept. It returns the number of blocks read from from.
+func Migrate(ctx context.Context, from, to ISlashFilter) (int, error) {
+	blks, err := from.ListMinedBlocks(ctx, address.Undef, 0, -1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list mined blocks: %w", err)
+	}
+	for i, blk := range blks {
+		if err := to.PutMinedBlock(ctx, blk); err != nil {
+			return i, fmt.Errorf("failed to copy block %s: %w", blk.Cid, err)
+		}
+	}
+	return len(blks), nil
+}
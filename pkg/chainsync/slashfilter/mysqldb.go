@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
 
@@ -18,10 +19,14 @@ import (
 
 var log = logging.Logger("mysql")
 
-type MysqlSlashFilter struct {
+// DBSlashFilter use a sql database to save mined block for detect slash consensus block
+type DBSlashFilter struct {
 	_db *gorm.DB
 }
 
+// MysqlSlashFilter is the slash filter base on mysql, it is kept for compatibility
+type MysqlSlashFilter = DBSlashFilter
+
 // MinedBlock record mined block
 type MinedBlock struct {
 	ParentEpoch int64  `gorm:"column:parent_epoch;type:bigint(20);NOT NULL"`
@@ -60,13 +65,13 @@ func NewMysqlSlashFilter(cfg config.MySQLConfig) (ISlashFilter, error) {
 	sqlDB.SetConnMaxLifetime(time.Second * cfg.ConnMaxLifeTime)
 
 	log.Info("init mysql success for LocalSlashFilter!")
-	return &MysqlSlashFilter{
+	return &DBSlashFilter{
 		_db: db,
 	}, nil
 }
 
 // checkSameHeightFault check whether the miner mined multi block on the same height
-func (f *MysqlSlashFilter) checkSameHeightFault(bh *types.BlockHeader) error {
+func (f *DBSlashFilter) checkSameHeightFault(bh *types.BlockHeader) error {
 	var bk MinedBlock
 	err := f._db.Model(&MinedBlock{}).Take(&bk, "miner=? and epoch=?", bh.Miner.String(), bh.Height).Error
 	if err == gorm.ErrRecordNotFound {
@@ -86,7 +91,7 @@ func (f *MysqlSlashFilter) checkSameHeightFault(bh *types.BlockHeader) error {
 }
 
 // checkSameParentFault check whether the miner mined block on the same parent
-func (f *MysqlSlashFilter) checkSameParentFault(bh *types.BlockHeader) error {
+func (f *DBSlashFilter) checkSameParentFault(bh *types.BlockHeader) error {
 	var bk MinedBlock
 	err := f._db.Model(&MinedBlock{}).Take(&bk, "miner=? and parent_key=?", bh.Miner.String(), types.NewTipSetKey(bh.Parents...).String()).Error
 	if err == gorm.ErrRecordNotFound {
@@ -106,7 +111,22 @@ func (f *MysqlSlashFilter) checkSameParentFault(bh *types.BlockHeader) error {
 }
 
 // MinedBlock check whether the block mined is slash
-func (f *MysqlSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error {
+func (f *DBSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error {
+	if err := f.CheckBlock(ctx, bh, parentEpoch); err != nil {
+		return err
+	}
+
+	return f.PutMinedBlock(ctx, &types.MinedBlock{
+		Miner:       bh.Miner,
+		Epoch:       bh.Height,
+		Cid:         bh.Cid(),
+		ParentKey:   types.NewTipSetKey(bh.Parents...),
+		ParentEpoch: parentEpoch,
+	})
+}
+
+// CheckBlock check whether the block mined is slash, without recording it
+func (f *DBSlashFilter) CheckBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error {
 	if err := f.checkSameHeightFault(bh); err != nil {
 		return err
 	}
@@ -120,7 +140,7 @@ func (f *MysqlSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader
 
 		// First check if we have mined a block on the parent epoch
 		var bk MinedBlock
-		err := f._db.Model(&MinedBlock{}).Take(&bk, "miner=? and parent_epoch=?", bh.Miner.String(), parentEpoch).Error
+		err := f._db.Model(&MinedBlock{}).Take(&bk, "miner=? and epoch=?", bh.Miner.String(), parentEpoch).Error
 		if err == nil {
 			// if exit
 			parent, err := cid.Decode(bk.Cid)
@@ -145,11 +165,65 @@ func (f *MysqlSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader
 		// if not exit good block
 	}
 
-	return f._db.Save(&MinedBlock{
-		ParentEpoch: int64(parentEpoch),
-		ParentKey:   types.NewTipSetKey(bh.Parents...).String(),
-		Epoch:       int64(bh.Height),
-		Miner:       bh.Miner.String(),
-		Cid:         bh.Cid().String(),
+	return nil
+}
+
+// PutMinedBlock records a mined block without checking it
+func (f *DBSlashFilter) PutMinedBlock(ctx context.Context, blk *types.MinedBlock) error {
+	var count int64
+	err := f._db.Model(&MinedBlock{}).Where("miner=? and epoch=?", blk.Miner.String(), blk.Epoch).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return f._db.Create(&MinedBlock{
+		ParentEpoch: int64(blk.ParentEpoch),
+		ParentKey:   blk.ParentKey.String(),
+		Epoch:       int64(blk.Epoch),
+		Miner:       blk.Miner.String(),
+		Cid:         blk.Cid.String(),
 	}).Error
 }
+
+// ListMinedBlocks lists the blocks recorded for the miner between the epochs, ordered by epoch
+func (f *DBSlashFilter) ListMinedBlocks(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error) {
+	db := f._db.Model(&MinedBlock{}).Where("epoch>=?", from)
+	if to >= 0 {
+		db = db.Where("epoch<=?", to)
+	}
+	if miner != address.Undef {
+		db = db.Where("miner=?", miner.String())
+	}
+
+	var bks []MinedBlock
+	if err := db.Order("epoch, miner").Find(&bks).Error; err != nil {
+		return nil, err
+	}
+
+	blks := make([]*types.MinedBlock, 0, len(bks))
+	for _, bk := range bks {
+		m, err := address.NewFromString(bk.Miner)
+		if err != nil {
+			return nil, err
+		}
+		c, err := cid.Decode(bk.Cid)
+		if err != nil {
+			return nil, err
+		}
+		parents, err := parseTipSetKeyString(bk.ParentKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent key of block %s: %w", bk.Cid, err)
+		}
+		blks = append(blks, &types.MinedBlock{
+			Miner:       m,
+			Epoch:       abi.ChainEpoch(bk.Epoch),
+			Cid:         c,
+			ParentKey:   parents,
+			ParentEpoch: abi.ChainEpoch(bk.ParentEpoch),
+		})
+	}
+	return blks, nil
+}
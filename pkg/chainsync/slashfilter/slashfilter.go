@@ -2,25 +2,41 @@ package slashfilter
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/ipfs/go-cid"
 	ds "github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
 
-// ISlashFilter used to detect whether the miner mined a invalidated block , support local db and mysql storage
+// ISlashFilter used to detect whether the miner mined a invalidated block , support local db, mysql and sqlite storage
 type ISlashFilter interface {
+	// MinedBlock checks whether the block mined is slash, and records it if it isn't
 	MinedBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error
+	// CheckBlock checks whether the block mined is slash, without recording it
+	CheckBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error
+	// PutMinedBlock records a mined block without checking it, the block already recorded for the
+	// miner at the epoch is kept
+	PutMinedBlock(ctx context.Context, blk *types.MinedBlock) error
+	// ListMinedBlocks lists the blocks recorded for the miner, or for all the miners if it is
+	// undefined, between the epochs from and to included, a negative to means no upper bound
+	ListMinedBlocks(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error)
 }
 
 // LocalSlashFilter use badger db to save mined block for detect slash consensus block
 type LocalSlashFilter struct {
 	byEpoch   ds.Datastore // double-fork mining faults, parent-grinding fault
 	byParents ds.Datastore // time-offset mining faults
+	records   ds.Datastore // mined blocks, by epoch
 }
 
 // NewLocalSlashFilter create a slash filter base on badger db
@@ -28,12 +44,36 @@ func NewLocalSlashFilter(dstore ds.Batching) ISlashFilter {
 	return &LocalSlashFilter{
 		byEpoch:   namespace.Wrap(dstore, ds.NewKey("/slashfilter/epoch")),
 		byParents: namespace.Wrap(dstore, ds.NewKey("/slashfilter/parents")),
+		records:   namespace.Wrap(dstore, ds.NewKey("/slashfilter/records")),
 	}
 }
 
+func epochDsKey(miner address.Address, epoch abi.ChainEpoch) ds.Key {
+	return ds.NewKey(fmt.Sprintf("/%s/%d", miner, epoch))
+}
+
+func parentsDsKey(miner address.Address, parents types.TipSetKey) ds.Key {
+	return ds.NewKey(fmt.Sprintf("/%s/%s", miner, parents.String()))
+}
+
 // MinedBlock check whether the block mined is slash
 func (f *LocalSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error {
-	epochKey := ds.NewKey(fmt.Sprintf("/%s/%d", bh.Miner, bh.Height))
+	if err := f.CheckBlock(ctx, bh, parentEpoch); err != nil {
+		return err
+	}
+
+	return f.putMinedBlock(ctx, &types.MinedBlock{
+		Miner:       bh.Miner,
+		Epoch:       bh.Height,
+		Cid:         bh.Cid(),
+		ParentKey:   types.NewTipSetKey(bh.Parents...),
+		ParentEpoch: parentEpoch,
+	})
+}
+
+// CheckBlock check whether the block mined is slash, without recording it
+func (f *LocalSlashFilter) CheckBlock(ctx context.Context, bh *types.BlockHeader, parentEpoch abi.ChainEpoch) error {
+	epochKey := epochDsKey(bh.Miner, bh.Height)
 	{
 		// double-fork mining (2 blocks at one epoch)
 		if err := checkFault(ctx, f.byEpoch, epochKey, bh, "double-fork mining faults"); err != nil {
@@ -41,7 +81,7 @@ func (f *LocalSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader
 		}
 	}
 
-	parentsKey := ds.NewKey(fmt.Sprintf("/%s/%s", bh.Miner, types.NewTipSetKey(bh.Parents...).String()))
+	parentsKey := parentsDsKey(bh.Miner, types.NewTipSetKey(bh.Parents...))
 	{
 		// time-offset mining faults (2 blocks with the same parents)
 		if err := checkFault(ctx, f.byParents, parentsKey, bh, "time-offset mining faults"); err != nil {
@@ -53,7 +93,7 @@ func (f *LocalSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader
 		// parent-grinding fault (didn't mine on top of our own block)
 
 		// First check if we have mined a block on the parent epoch
-		parentEpochKey := ds.NewKey(fmt.Sprintf("/%s/%d", bh.Miner, parentEpoch))
+		parentEpochKey := epochDsKey(bh.Miner, parentEpoch)
 		have, err := f.byEpoch.Has(ctx, parentEpochKey)
 		if err != nil {
 			return err
@@ -84,17 +124,167 @@ func (f *LocalSlashFilter) MinedBlock(ctx context.Context, bh *types.BlockHeader
 		}
 	}
 
-	if err := f.byParents.Put(ctx, parentsKey, bh.Cid().Bytes()); err != nil {
+	return nil
+}
+
+// PutMinedBlock records a mined block without checking it
+func (f *LocalSlashFilter) PutMinedBlock(ctx context.Context, blk *types.MinedBlock) error {
+	have, err := f.byEpoch.Has(ctx, epochDsKey(blk.Miner, blk.Epoch))
+	if err != nil {
+		return err
+	}
+	if have {
+		return nil
+	}
+	return f.putMinedBlock(ctx, blk)
+}
+
+func (f *LocalSlashFilter) putMinedBlock(ctx context.Context, blk *types.MinedBlock) error {
+	record, err := json.Marshal(blk)
+	if err != nil {
+		return err
+	}
+
+	epochKey := epochDsKey(blk.Miner, blk.Epoch)
+	if err := f.records.Put(ctx, epochKey, record); err != nil {
+		return fmt.Errorf("putting record entry: %w", err)
+	}
+
+	if err := f.byParents.Put(ctx, parentsDsKey(blk.Miner, blk.ParentKey), blk.Cid.Bytes()); err != nil {
 		return fmt.Errorf("putting byParents entry: %w", err)
 	}
 
-	if err := f.byEpoch.Put(ctx, epochKey, bh.Cid().Bytes()); err != nil {
+	if err := f.byEpoch.Put(ctx, epochKey, blk.Cid.Bytes()); err != nil {
 		return fmt.Errorf("putting byEpoch entry: %w", err)
 	}
 
 	return nil
 }
 
+// ListMinedBlocks lists the blocks recorded for the miner between the epochs, ordered by epoch
+func (f *LocalSlashFilter) ListMinedBlocks(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error) {
+	var prefix string
+	if miner != address.Undef {
+		prefix = "/" + miner.String()
+	}
+
+	res, err := f.byEpoch.Query(ctx, query.Query{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() // nolint: errcheck
+
+	var blks []*types.MinedBlock
+	// the parents of the blocks recorded before the records were kept, by block cid
+	var legacyParents map[cid.Cid]types.TipSetKey
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		m, epoch, err := parseEpochDsKey(ds.NewKey(r.Key))
+		if err != nil {
+			return nil, err
+		}
+		if epoch < from || (to >= 0 && epoch > to) {
+			continue
+		}
+
+		record, err := f.records.Get(ctx, ds.NewKey(r.Key))
+		if err == nil {
+			var blk types.MinedBlock
+			if err := json.Unmarshal(record, &blk); err != nil {
+				return nil, fmt.Errorf("failed to decode record %s: %w", r.Key, err)
+			}
+			blks = append(blks, &blk)
+			continue
+		} else if err != ds.ErrNotFound {
+			return nil, err
+		}
+
+		_, c, err := cid.CidFromBytes(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		if legacyParents == nil {
+			if legacyParents, err = f.loadParents(ctx); err != nil {
+				return nil, err
+			}
+		}
+		// the parent epoch of these blocks is unknown
+		blks = append(blks, &types.MinedBlock{
+			Miner:     m,
+			Epoch:     epoch,
+			Cid:       c,
+			ParentKey: legacyParents[c],
+		})
+	}
+
+	sort.Slice(blks, func(i, j int) bool {
+		if blks[i].Epoch != blks[j].Epoch {
+			return blks[i].Epoch < blks[j].Epoch
+		}
+		return blks[i].Miner.String() < blks[j].Miner.String()
+	})
+	return blks, nil
+}
+
+// loadParents returns the parents of all the recorded blocks, by block cid
+func (f *LocalSlashFilter) loadParents(ctx context.Context) (map[cid.Cid]types.TipSetKey, error) {
+	res, err := f.byParents.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() // nolint: errcheck
+
+	parents := make(map[cid.Cid]types.TipSetKey)
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		_, c, err := cid.CidFromBytes(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		// the key is /<miner>/<parents>
+		tsk, err := parseTipSetKeyString(ds.NewKey(r.Key).BaseNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("invalid parents key %s: %w", r.Key, err)
+		}
+		parents[c] = tsk
+	}
+	return parents, nil
+}
+
+func parseEpochDsKey(key ds.Key) (address.Address, abi.ChainEpoch, error) {
+	namespaces := key.Namespaces()
+	if len(namespaces) != 2 {
+		return address.Undef, 0, fmt.Errorf("invalid epoch key %s", key)
+	}
+	miner, err := address.NewFromString(namespaces[0])
+	if err != nil {
+		return address.Undef, 0, fmt.Errorf("invalid epoch key %s: %w", key, err)
+	}
+	epoch, err := strconv.ParseInt(namespaces[1], 10, 64)
+	if err != nil {
+		return address.Undef, 0, fmt.Errorf("invalid epoch key %s: %w", key, err)
+	}
+	return miner, abi.ChainEpoch(epoch), nil
+}
+
+// parseTipSetKeyString parses the output of TipSetKey.String
+func parseTipSetKeyString(s string) (types.TipSetKey, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "{"), "}")
+	var cids []cid.Cid
+	for _, str := range strings.Fields(s) {
+		c, err := cid.Decode(str)
+		if err != nil {
+			return types.EmptyTSK, err
+		}
+		cids = append(cids, c)
+	}
+	return types.NewTipSetKey(cids...), nil
+}
+
 func checkFault(ctx context.Context, t ds.Datastore, key ds.Key, bh *types.BlockHeader, faultType string) error {
 	fault, err := t.Has(ctx, key)
 	if err != nil {
@@ -0,0 +1,165 @@
+package slashfilter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func mkBlock(t *testing.T, miner uint64, height abi.ChainEpoch, parents []cid.Cid, ticket string) *types.BlockHeader {
+	addr, err := address.NewIDAddress(miner)
+	require.NoError(t, err)
+	c, err := cid.Decode("bafyreicmaj5hhoy5mgqvamfhgexxyergw7hdeshizghodwkjg6qmpoco7i")
+	require.NoError(t, err)
+
+	return &types.BlockHeader{
+		Miner:                 addr,
+		Ticket:                &types.Ticket{VRFProof: []byte(ticket)},
+		ElectionProof:         &types.ElectionProof{VRFProof: []byte(ticket)},
+		Parents:               parents,
+		ParentWeight:          big.Zero(),
+		Height:                height,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+		ParentBaseFee:         big.Zero(),
+	}
+}
+
+func testSlashFilter(t *testing.T, f ISlashFilter) []*types.BlockHeader {
+	ctx := context.Background()
+
+	genesis := mkBlock(t, 1, 0, nil, "genesis")
+	blk1 := mkBlock(t, 1000, 1, []cid.Cid{genesis.Cid()}, "a")
+	blk2 := mkBlock(t, 1001, 1, []cid.Cid{genesis.Cid()}, "b")
+	blk3 := mkBlock(t, 1000, 2, []cid.Cid{blk1.Cid(), blk2.Cid()}, "c")
+	require.NoError(t, f.MinedBlock(ctx, blk1, 0))
+	require.NoError(t, f.MinedBlock(ctx, blk2, 0))
+	require.NoError(t, f.MinedBlock(ctx, blk3, 1))
+	// recording a block twice is fine
+	require.NoError(t, f.MinedBlock(ctx, blk3, 1))
+
+	// double-fork mining
+	other := mkBlock(t, 1000, 2, []cid.Cid{blk1.Cid()}, "d")
+	err := f.CheckBlock(ctx, other, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "double-fork mining")
+	// time-offset mining
+	other = mkBlock(t, 1000, 3, []cid.Cid{blk1.Cid(), blk2.Cid()}, "e")
+	err = f.CheckBlock(ctx, other, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "time-offset mining")
+	// parent-grinding, the parents of the block don't include the block of the miner at the parent epoch
+	other = mkBlock(t, 1001, 3, []cid.Cid{blk1.Cid(), blk2.Cid()}, "g")
+	require.NoError(t, f.CheckBlock(ctx, other, 1))
+	other = mkBlock(t, 1001, 3, []cid.Cid{blk1.Cid()}, "h")
+	err = f.CheckBlock(ctx, other, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parent-grinding")
+	// CheckBlock doesn't record the block
+	other = mkBlock(t, 1000, 4, []cid.Cid{blk3.Cid()}, "f")
+	require.NoError(t, f.CheckBlock(ctx, other, 2))
+
+	blks, err := f.ListMinedBlocks(ctx, address.Undef, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, blks, 3)
+	for i, bh := range []*types.BlockHeader{blk1, blk2, blk3} {
+		assert.Equal(t, bh.Cid(), blks[i].Cid)
+		assert.Equal(t, bh.Miner, blks[i].Miner)
+		assert.Equal(t, bh.Height, blks[i].Epoch)
+		assert.Equal(t, types.NewTipSetKey(bh.Parents...), blks[i].ParentKey)
+	}
+	assert.Equal(t, abi.ChainEpoch(1), blks[2].ParentEpoch)
+
+	blks, err = f.ListMinedBlocks(ctx, blk1.Miner, 2, -1)
+	require.NoError(t, err)
+	require.Len(t, blks, 1)
+	assert.Equal(t, blk3.Cid(), blks[0].Cid)
+
+	blks, err = f.ListMinedBlocks(ctx, address.Undef, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, blks, 2)
+
+	return []*types.BlockHeader{blk1, blk2, blk3}
+}
+
+func TestLocalSlashFilter(t *testing.T) {
+	tf.UnitTest(t)
+
+	testSlashFilter(t, NewLocalSlashFilter(dssync.MutexWrap(ds.NewMapDatastore())))
+}
+
+func TestSqliteSlashFilter(t *testing.T) {
+	tf.UnitTest(t)
+
+	f, err := NewSqliteSlashFilter(filepath.Join(t.TempDir(), "slashfilter.db"))
+	require.NoError(t, err)
+	testSlashFilter(t, f)
+}
+
+func TestLocalSlashFilterLegacyRecords(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	f := NewLocalSlashFilter(dstore).(*LocalSlashFilter)
+	blk := mkBlock(t, 1000, 1, []cid.Cid{mkBlock(t, 1, 0, nil, "genesis").Cid()}, "a")
+
+	// the blocks recorded without a record
+	parents := types.NewTipSetKey(blk.Parents...)
+	require.NoError(t, f.byEpoch.Put(ctx, epochDsKey(blk.Miner, blk.Height), blk.Cid().Bytes()))
+	require.NoError(t, f.byParents.Put(ctx, parentsDsKey(blk.Miner, parents), blk.Cid().Bytes()))
+
+	blks, err := f.ListMinedBlocks(ctx, address.Undef, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, blks, 1)
+	assert.Equal(t, &types.MinedBlock{Miner: blk.Miner, Epoch: blk.Height, Cid: blk.Cid(), ParentKey: parents}, blks[0])
+}
+
+func TestMigrate(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	local := NewLocalSlashFilter(dssync.MutexWrap(ds.NewMapDatastore()))
+	blks := testSlashFilter(t, local)
+
+	sqlite, err := NewSqliteSlashFilter(filepath.Join(t.TempDir(), "slashfilter.db"))
+	require.NoError(t, err)
+	n, err := Migrate(ctx, local, sqlite)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// the copied blocks are checked by the destination
+	other := mkBlock(t, 1000, 2, []cid.Cid{blks[0].Cid()}, "d")
+	require.Error(t, sqlite.CheckBlock(ctx, other, 1))
+
+	// and back, the blocks already recorded are kept
+	back := NewLocalSlashFilter(dssync.MutexWrap(ds.NewMapDatastore()))
+	require.NoError(t, back.PutMinedBlock(ctx, &types.MinedBlock{Miner: blks[2].Miner, Epoch: blks[2].Height, Cid: other.Cid()}))
+	n, err = Migrate(ctx, sqlite, back)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	expected, err := local.ListMinedBlocks(ctx, address.Undef, 0, -1)
+	require.NoError(t, err)
+	res, err := back.ListMinedBlocks(ctx, address.Undef, 0, -1)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	assert.Equal(t, expected[:2], res[:2])
+	assert.Equal(t, other.Cid(), res[2].Cid)
+}
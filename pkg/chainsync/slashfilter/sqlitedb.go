@@ -0,0 +1,32 @@
+package slashfilter
+
+import (
+	"fmt"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// NewSqliteSlashFilter create a new slash filter base on a sqlite database, for the single host deployments
+func NewSqliteSlashFilter(path string) (ISlashFilter, error) {
+	db, err := gorm.Open(sqlite.Open(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+
+	if err := db.AutoMigrate(MinedBlock{}); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// sqlite doesn't support concurrent writes
+	sqlDB.SetMaxOpenConns(1)
+
+	log.Infof("init sqlite slash filter at %s", path)
+	return &DBSlashFilter{
+		_db: db,
+	}, nil
+}
@@ -59,16 +59,26 @@ var (
 	// ErrUnexpectedStoreState indicates that the syncer's chain bsstore is violating expected invariants.
 	ErrUnexpectedStoreState = errors.New("the chain bsstore is in an unexpected state")
 
-	logSyncer    = logging.Logger("chainsync.syncer")
-	syncOneTimer *metrics.Float64Timer
-	reorgCnt     *metrics.Int64Counter // nolint
+	logSyncer           = logging.Logger("chainsync.syncer")
+	syncOneTimer        *metrics.Float64Timer
+	fetchSegmentTimer   *metrics.Float64Timer
+	processSegmentTimer *metrics.Float64Timer
+	segmentWaitTimer    *metrics.Float64Timer
+	reorgCnt            *metrics.Int64Counter // nolint
 )
 
 func init() {
 	syncOneTimer = metrics.NewTimerMs("syncer/sync_one", "Duration of single tipset validation in milliseconds")
+	fetchSegmentTimer = metrics.NewTimerMs("syncer/fetch_segment", "Duration of fetching the messages of a segment of tipsets in milliseconds")
+	processSegmentTimer = metrics.NewTimerMs("syncer/process_segment", "Duration of validating and executing a segment of tipsets in milliseconds")
+	segmentWaitTimer = metrics.NewTimerMs("syncer/segment_wait", "Duration the execution waited for the messages of the next segment in milliseconds")
 	reorgCnt = metrics.NewInt64Counter("chain/reorg_count", "The number of reorgs that have occurred.")
 }
 
+// DefaultPipelineDepth is the default number of segments whose messages are fetched ahead of
+// the segment being executed.
+const DefaultPipelineDepth = 2
+
 // StateProcessor does semantic validation on fullblocks.
 type StateProcessor interface {
 	// RunStateTransition returns the state root CID resulting from applying the input ts to the
@@ -134,11 +144,17 @@ type Syncer struct {
 	clock    clock.Clock
 	headLock sync.Mutex
 
-	bsstore    blockstoreutil.Blockstore
-	checkPoint types.TipSetKey
+	bsstore blockstoreutil.Blockstore
 
 	fork fork.IFork
 
+	// pipelineDepth is the number of segments whose messages are fetched ahead of the
+	// segment being executed, guarded by atomic.
+	pipelineDepth int64
+
+	// onValidatedBlock is called with the headers of the blocks validated by the syncer, it may be nil
+	onValidatedBlock func(*types.BlockHeader)
+
 	delayRunTx *delayRunTsTransition
 }
 
@@ -153,6 +169,7 @@ func NewSyncer(stmgr *statemanger.Stmgr,
 	exchangeClient exchange.Client,
 	c clock.Clock,
 	fork fork.IFork,
+	badTipSets *syncTypes.BadTipSetCache,
 ) (*Syncer, error) {
 	if constants.InsecurePoStValidation {
 		logSyncer.Warn("*********************************************************************************************")
@@ -162,7 +179,7 @@ func NewSyncer(stmgr *statemanger.Stmgr,
 
 	syncer := &Syncer{
 		exchangeClient:  exchangeClient,
-		badTipSets:      syncTypes.NewBadTipSetCache(),
+		badTipSets:      badTipSets,
 		blockValidator:  hv,
 		chainSelector:   cs,
 		bsstore:         bsstore,
@@ -171,6 +188,7 @@ func NewSyncer(stmgr *statemanger.Stmgr,
 		clock:           c,
 		fork:            fork,
 		stmgr:           stmgr,
+		pipelineDepth:   DefaultPipelineDepth,
 	}
 
 	defer func() {
@@ -200,36 +218,39 @@ func (syncer *Syncer) syncOne(ctx context.Context, parent, next *types.TipSet) e
 
 	var err error
 
-	if !parent.Key().Equals(syncer.checkPoint) {
-		var wg errgroup.Group
-		for i := 0; i < next.Len(); i++ {
-			blk := next.At(i)
-			wg.Go(func() error {
-				// Fetch the URL.
-				return syncer.blockValidator.ValidateFullBlock(ctx, blk)
-			})
-		}
-		err = wg.Wait()
-		if err != nil {
-			var rootNotMatch bool // nolint
-
-			if merr, isok := err.(*multierror.Error); isok {
-				for _, e := range merr.Errors {
-					if isRootNotMatch(e) {
-						rootNotMatch = true
-						break
-					}
+	var wg errgroup.Group
+	for i := 0; i < next.Len(); i++ {
+		blk := next.At(i)
+		wg.Go(func() error {
+			// Fetch the URL.
+			return syncer.blockValidator.ValidateFullBlock(ctx, blk)
+		})
+	}
+	err = wg.Wait()
+	if err != nil {
+		var rootNotMatch bool // nolint
+
+		if merr, isok := err.(*multierror.Error); isok {
+			for _, e := range merr.Errors {
+				if isRootNotMatch(e) {
+					rootNotMatch = true
+					break
 				}
-			} else {
-				rootNotMatch = isRootNotMatch(err) // nolint
 			}
+		} else {
+			rootNotMatch = isRootNotMatch(err) // nolint
+		}
 
-			if rootNotMatch { // nolint
-				// todo: should here rollback, and re-compute?
-				_ = syncer.stmgr.Rollback(ctx, parent, next)
-			}
+		if rootNotMatch { // nolint
+			// todo: should here rollback, and re-compute?
+			_ = syncer.stmgr.Rollback(ctx, parent, next)
+		}
 
-			return fmt.Errorf("validate mining failed %w", err)
+		return fmt.Errorf("validate mining failed %w", err)
+	}
+	if syncer.onValidatedBlock != nil {
+		for _, blk := range next.Blocks() {
+			syncer.onValidatedBlock(blk)
 		}
 	}
 
@@ -251,13 +272,8 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, target *syncTypes.Tar
 
 	now := time.Now()
 
+	// the state of the target is set by the dispatcher, under the lock of the tracker
 	defer func() {
-		if err != nil {
-			target.Err = err
-			target.State = syncTypes.StageSyncErrored
-		} else {
-			target.State = syncTypes.StageSyncComplete
-		}
 		tracing.AddErrorEndSpan(ctx, span, &err)
 		span.End()
 		logSyncer.Infof("handle tipset height %d, count %d, took %.4f(s)", target.Head.Height(), target.Head.Len(), time.Since(now).Seconds())
@@ -274,11 +290,24 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, target *syncTypes.Tar
 		return errors.New("do not sync to a target has synced before")
 	}
 
+	if reason, ok := syncer.badTipSets.Reason(target.Head.Key()); ok {
+		return fmt.Errorf("do not sync to a target marked bad: %s", reason)
+	}
+
 	syncer.exchangeClient.AddPeer(target.Sender)
 	tipsets, err := syncer.fetchChainBlocks(ctx, head, target.Head)
 	if err != nil {
 		return errors.Wrapf(err, "failure fetching or validating headers")
 	}
+	if err := syncer.checkCheckpoint(ctx, target.Head); err != nil {
+		return err
+	}
+	for i, ts := range tipsets {
+		if reason, ok := syncer.badTipSets.Reason(ts.Key()); ok {
+			syncer.badTipSets.AddChain(tipsets[i+1:], fmt.Sprintf("linked to bad tipset %s", ts.Key()))
+			return fmt.Errorf("chain is linked to tipset %s at %d marked bad: %s", ts.Key(), ts.Height(), reason)
+		}
+	}
 	logSyncer.Debugf("fetch header success at %v %s ...", tipsets[0].Height(), tipsets[0].Key())
 
 	if err = syncer.syncSegement(ctx, target, tipsets); err == nil {
@@ -288,62 +317,69 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, target *syncTypes.Tar
 	return err
 }
 
+// syncSegement fetches the messages of the tipsets and executes them, segment by segment. The
+// messages of up to pipelineDepth segments are fetched ahead of the segment being executed, so
+// the network and the vm are busy at the same time during catch-up.
 func (syncer *Syncer) syncSegement(ctx context.Context, target *syncTypes.Target, tipsets []*types.TipSet) error {
 	parent, err := syncer.chainStore.GetTipSet(ctx, tipsets[0].Parents())
 	if err != nil {
 		return err
 	}
 
-	errProcessChan := make(chan error, 1)
-	errProcessChan <- nil // init
-	var wg sync.WaitGroup
-	// todo  write a pipline segment processor function
-	if err = rangeProcess(tipsets, func(segTipset []*types.TipSet) error {
-		// fetch messages
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the fetcher blocks on the send of the last fetched segment once the channel is full
+	segCh := make(chan []*types.TipSet, syncer.PipelineDepth()-1)
+	errFetchChan := make(chan error, 1)
+	go func() {
+		defer close(segCh)
+		errFetchChan <- rangeProcess(tipsets, func(segTipset []*types.TipSet) error {
+			startTip := segTipset[0].Height()
+			emdTipset := segTipset[len(segTipset)-1].Height()
+			logSyncer.Debugf("start to fetch message segement %d-%d", startTip, emdTipset)
+			stopwatch := fetchSegmentTimer.Start(ctx)
+			if _, err := syncer.fetchSegMessage(ctx, segTipset); err != nil {
+				return err
+			}
+			stopwatch.Stop(ctx)
+			logSyncer.Debugf("finish to fetch message segement %d-%d", startTip, emdTipset)
+
+			select {
+			case segCh <- segTipset:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	for {
+		waitStopwatch := segmentWaitTimer.Start(ctx)
+		segTipset, ok := <-segCh
+		if !ok {
+			break
+		}
+		waitStopwatch.Stop(ctx)
+
 		startTip := segTipset[0].Height()
 		emdTipset := segTipset[len(segTipset)-1].Height()
-		logSyncer.Debugf("start to fetch message segement %d-%d", startTip, emdTipset)
-		_, err := syncer.fetchSegMessage(ctx, segTipset)
-		if err != nil {
-			return err
-		}
-		logSyncer.Debugf("finish to fetch message segement %d-%d", startTip, emdTipset)
-		err = <-errProcessChan
+		logSyncer.Debugf("start to process message segement %d-%d", startTip, emdTipset)
+		stopwatch := processSegmentTimer.Start(ctx)
+		parent, err = syncer.processTipSetSegment(ctx, target, parent, segTipset)
 		if err != nil {
 			return fmt.Errorf("process message failed %v", err)
 		}
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			logSyncer.Debugf("start to process message segement %d-%d", startTip, emdTipset)
-			defer logSyncer.Debugf("finish to process message segement %d-%d", startTip, emdTipset)
-			var processErr error
-			parent, processErr = syncer.processTipSetSegment(ctx, target, parent, segTipset)
-			if processErr != nil {
-				errProcessChan <- processErr
-				return
-			}
-			if !parent.Key().Equals(syncer.checkPoint) {
-				logSyncer.Debugf("set chain head, height:%d, blocks:%d", parent.Height(), parent.Len())
-				if err := syncer.SetHead(ctx, parent); err != nil {
-					errProcessChan <- err
-					return
-				}
-			}
-			errProcessChan <- nil
-		}()
-		return nil
-	}); err != nil {
-		return err
-	}
+		stopwatch.Stop(ctx)
+		logSyncer.Debugf("finish to process message segement %d-%d", startTip, emdTipset)
 
-	wg.Wait()
-	select {
-	case err = <-errProcessChan:
-		return err
-	default:
-		return nil
+		logSyncer.Debugf("set chain head, height:%d, blocks:%d", parent.Height(), parent.Len())
+		if err := syncer.SetHead(ctx, parent); err != nil {
+			return err
+		}
 	}
+
+	return <-errFetchChan
 }
 
 // fetchChainBlocks get the block data, from targettip to knowntip.
@@ -600,7 +636,7 @@ func (syncer *Syncer) processTipSetSegment(ctx context.Context, target *syncType
 			// have access to the chain. If syncOne fails for non-consensus reasons,
 			// there is no assumption that the running node's data is valid at all,
 			// so we don't really lose anything with this simplification.
-			syncer.badTipSets.AddChain(segTipset[i:])
+			syncer.badTipSets.AddChain(segTipset[i:], err.Error())
 			return nil, errors.Wrapf(err, "failed to sync tipset %s, number %d of %d in chain", ts.Key().String(), i, len(segTipset))
 		}
 		parent = ts
@@ -609,6 +645,111 @@ func (syncer *Syncer) processTipSetSegment(ctx context.Context, target *syncType
 	return parent, nil
 }
 
+// checkCheckpoint returns an error if the checkpoint pinned by the operator isn't in the chain of ts.
+// The checkpoint written by the snapshot imports isn't checked, the snapshot head may not be final.
+func (syncer *Syncer) checkCheckpoint(ctx context.Context, ts *types.TipSet) error {
+	cpKey := syncer.chainStore.GetPinnedCheckPoint()
+	if cpKey.IsEmpty() {
+		return nil
+	}
+	cp, err := syncer.chainStore.GetTipSet(ctx, cpKey)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	return syncer.checkCheckpointAt(ctx, ts, cp)
+}
+
+// SyncCheckpoint pins the tipset as the checkpoint of the chain: the syncer refuses the targets
+// whose chain doesn't include it and never reorgs below it. If the tipset isn't in the chain of
+// the head, the chain is synced to the tipset and it becomes the new head.
+func (syncer *Syncer) SyncCheckpoint(ctx context.Context, tsk types.TipSetKey) error {
+	if tsk.IsEmpty() {
+		return fmt.Errorf("empty tipset key")
+	}
+	if reason, ok := syncer.badTipSets.Reason(tsk); ok {
+		return fmt.Errorf("tipset %s is marked bad: %s", tsk, reason)
+	}
+
+	ts, err := syncer.chainStore.GetTipSet(ctx, tsk)
+	if err != nil {
+		tipsets, err := syncer.exchangeClient.GetBlocks(ctx, tsk, 1)
+		if err != nil {
+			return fmt.Errorf("failed to fetch checkpoint tipset: %w", err)
+		}
+		ts = tipsets[0]
+		for _, blk := range ts.Blocks() {
+			if _, err := syncer.chainStore.PutObject(ctx, blk); err != nil {
+				return err
+			}
+		}
+	}
+
+	head := syncer.chainStore.GetHead()
+	if err := syncer.checkCheckpointAt(ctx, head, ts); err != nil {
+		logSyncer.Warnf("switching the head to checkpoint %s at %d: %v", ts.Key(), ts.Height(), err)
+		if !syncer.chainStore.HasTipSetAndState(ctx, ts) {
+			tipsets, err := syncer.fetchChainBlocks(ctx, head, ts)
+			if err != nil {
+				return fmt.Errorf("failed to fetch the chain of the checkpoint: %w", err)
+			}
+			target := &syncTypes.Target{ChainInfo: *types.NewChainInfo("", "", ts)}
+			if err := syncer.syncSegement(ctx, target, tipsets); err != nil {
+				return fmt.Errorf("failed to sync the chain of the checkpoint: %w", err)
+			}
+		}
+	}
+
+	syncer.headLock.Lock()
+	defer syncer.headLock.Unlock()
+	// the head may have changed while syncing
+	if err := syncer.checkCheckpointAt(ctx, syncer.chainStore.GetHead(), ts); err != nil {
+		if err := syncer.chainStore.SetHead(ctx, ts); err != nil {
+			return err
+		}
+	}
+	if err := syncer.chainStore.SetPinnedCheckPoint(ctx, tsk); err != nil {
+		return err
+	}
+	logSyncer.Infof("set checkpoint %s at %d", ts.Key(), ts.Height())
+	return nil
+}
+
+// checkCheckpointAt returns an error if cp isn't in the chain of ts.
+func (syncer *Syncer) checkCheckpointAt(ctx context.Context, ts, cp *types.TipSet) error {
+	if ts.Height() < cp.Height() {
+		return fmt.Errorf("tipset %s at %d is below the checkpoint at %d", ts.Key(), ts.Height(), cp.Height())
+	}
+	anc, err := syncer.chainStore.GetTipSetByHeight(ctx, ts, cp.Height(), false)
+	if err != nil {
+		return err
+	}
+	if !anc.Equals(cp) {
+		return fmt.Errorf("the chain of tipset %s at %d doesn't include the checkpoint %s at %d", ts.Key(), ts.Height(), cp.Key(), cp.Height())
+	}
+	return nil
+}
+
+// SetPipelineDepth sets the number of segments whose messages are fetched ahead of the segment
+// being executed, it bounds how far the fetching runs ahead of the execution.
+func (syncer *Syncer) SetPipelineDepth(depth int) {
+	if depth < 1 {
+		depth = 1
+	}
+	atomic.StoreInt64(&syncer.pipelineDepth, int64(depth))
+}
+
+// OnValidatedBlock sets the function called with the header of every block the syncer validated,
+// whichever way its chain was received. It must be set before the syncer is started.
+func (syncer *Syncer) OnValidatedBlock(fn func(*types.BlockHeader)) {
+	syncer.onValidatedBlock = fn
+}
+
+// PipelineDepth returns the number of segments whose messages are fetched ahead of the segment
+// being executed.
+func (syncer *Syncer) PipelineDepth() int {
+	return int(atomic.LoadInt64(&syncer.pipelineDepth))
+}
+
 // Head get latest head from chain store
 func (syncer *Syncer) Head() *types.TipSet {
 	return syncer.chainStore.GetHead()
@@ -686,7 +827,7 @@ func (syncer *Syncer) exceedsForkLength(ctx context.Context, synced, external *t
 		}
 
 		// Now check to see if we've walked back to the checkpoint.
-		if synced.Key().Equals(syncer.checkPoint) {
+		if synced.Key().Equals(syncer.chainStore.GetCheckPoint()) {
 			return true, nil
 		}
 
@@ -43,7 +43,7 @@ func TestLoadFork(t *testing.T) {
 	stmgr := statemanger.NewStateManger(builder.Store(), builder.MessageStore(), blockValidator, nil, nil, nil, nil, false)
 
 	s, err := syncer.NewSyncer(stmgr, blockValidator, sel, builder.Store(),
-		builder.Mstore(), builder.BlockStore(), builder, clock.NewFake(time.Unix(1234567890, 0)), nil)
+		builder.Mstore(), builder.BlockStore(), builder, clock.NewFake(time.Unix(1234567890, 0)), nil, types.NewBadTipSetCache())
 
 	require.NoError(t, err)
 
@@ -101,7 +101,8 @@ func TestLoadFork(t *testing.T) {
 		builder.BlockStore(),
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		types.NewBadTipSetCache())
 	require.NoError(t, err)
 
 	assert.True(t, newStore.HasTipSetAndState(ctx, left))
@@ -142,6 +142,25 @@ func TestChainIncremental(t *testing.T) {
 	verifyHead(t, builder.Store(), t4)
 }
 
+func TestPipelinedSync(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	for _, depth := range []int{1, 3} {
+		builder, syncer := setup(ctx, t)
+		syncer.SetPipelineDepth(depth)
+		require.Equal(t, depth, syncer.PipelineDepth())
+
+		// several segments of tipsets
+		head := builder.AppendManyOn(ctx, 30, builder.Genesis())
+		target := &syncTypes.Target{ChainInfo: *types.NewChainInfo("", "", head)}
+		require.NoError(t, syncer.HandleNewTipSet(ctx, target))
+		require.NoError(t, builder.FlushHead(ctx))
+		verifyTip(t, builder.Store(), head, builder.StateForKey(ctx, head.Key()))
+		verifyHead(t, builder.Store(), head)
+	}
+}
+
 func TestChainJump(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
@@ -326,7 +345,8 @@ func TestNoUncessesaryFetch(t *testing.T) {
 		builder.BlockStore(),
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		syncTypes.NewBadTipSetCache())
 	require.NoError(t, err)
 
 	target2 := &syncTypes.Target{
@@ -533,6 +553,101 @@ func TestSemanticallyBadTipSetFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "val semantic fails")
 }
 
+func TestBadTipSetRejected(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	eval := builder.FakeStateEvaluator()
+	stmgr := statemanger.NewStateManger(builder.Store(), builder.MessageStore(), eval, nil, nil, nil, nil, false)
+	badTipSets := syncTypes.NewBadTipSetCache()
+	s, err := syncer.NewSyncer(stmgr, eval, &chain.FakeChainSelector{}, builder.Store(), builder.Mstore(),
+		builder.BlockStore(), builder, clock.NewFake(time.Unix(1234567890, 0)), fork.NewMockFork(), badTipSets)
+	require.NoError(t, err)
+
+	t1 := builder.AppendOn(ctx, builder.Genesis(), 1)
+	t2 := builder.AppendOn(ctx, t1, 1)
+	t3 := builder.AppendOn(ctx, t2, 1)
+	newTarget := func(ts *types.TipSet) *syncTypes.Target {
+		return &syncTypes.Target{ChainInfo: *types.NewChainInfo("", "", ts)}
+	}
+
+	require.NoError(t, badTipSets.Add(ctx, t2.Key(), "bad state"))
+	err = s.HandleNewTipSet(ctx, newTarget(t2))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad state")
+
+	// the descendants of a bad tipset are bad too
+	require.Error(t, s.HandleNewTipSet(ctx, newTarget(t3)))
+	reason, ok := badTipSets.Reason(t3.Key())
+	require.True(t, ok)
+	assert.Contains(t, reason, t2.Key().String())
+
+	require.NoError(t, badTipSets.Remove(ctx, t2.Key()))
+	require.NoError(t, badTipSets.Remove(ctx, t3.Key()))
+	require.NoError(t, s.HandleNewTipSet(ctx, newTarget(t3)))
+	require.NoError(t, builder.FlushHead(ctx))
+	verifyHead(t, builder.Store(), t3)
+}
+
+func TestSyncCheckpoint(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, syncer := setup(ctx, t)
+	genesis := builder.Store().GetHead()
+
+	forkbase := builder.AppendOn(ctx, genesis, 1)
+	main1 := builder.AppendOn(ctx, forkbase, 1)
+	main2 := builder.AppendOn(ctx, main1, 1)
+	main3 := builder.AppendOn(ctx, main2, 1)
+	fork1 := builder.AppendOn(ctx, forkbase, 2)
+	fork2 := builder.AppendOn(ctx, fork1, 1)
+	newTarget := func(ts *types.TipSet) *syncTypes.Target {
+		return &syncTypes.Target{ChainInfo: *types.NewChainInfo("", "", ts)}
+	}
+
+	require.NoError(t, syncer.HandleNewTipSet(ctx, newTarget(main2)))
+	require.NoError(t, builder.FlushHead(ctx))
+	verifyHead(t, builder.Store(), main2)
+
+	// the head is switched to the checkpoint on the fork
+	require.NoError(t, syncer.SyncCheckpoint(ctx, fork1.Key()))
+	require.NoError(t, builder.FlushHead(ctx))
+	verifyTip(t, builder.Store(), fork1, builder.StateForKey(ctx, fork1.Key()))
+	verifyHead(t, builder.Store(), fork1)
+	assert.Equal(t, fork1.Key(), builder.Store().GetPinnedCheckPoint())
+
+	// chains without the checkpoint are refused, even if heavier
+	err := syncer.HandleNewTipSet(ctx, newTarget(main3))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checkpoint")
+	verifyHead(t, builder.Store(), fork1)
+
+	require.NoError(t, syncer.HandleNewTipSet(ctx, newTarget(fork2)))
+	require.NoError(t, builder.FlushHead(ctx))
+	verifyHead(t, builder.Store(), fork2)
+
+	// a checkpoint in the chain of the head doesn't change the head
+	require.NoError(t, syncer.SyncCheckpoint(ctx, fork2.Key()))
+	verifyHead(t, builder.Store(), fork2)
+}
+
+func TestSyncRecordsValidatedBlocks(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, syncer := setup(ctx, t)
+	genesis := builder.Store().GetHead()
+
+	var validated []cid.Cid
+	syncer.OnValidatedBlock(func(blk *types.BlockHeader) {
+		validated = append(validated, blk.Cid())
+	})
+
+	t1 := builder.AppendOn(ctx, genesis, 2)
+	t2 := builder.AppendOn(ctx, t1, 1)
+	require.NoError(t, syncer.HandleNewTipSet(ctx, &syncTypes.Target{ChainInfo: *types.NewChainInfo("", "", t2)}))
+	assert.ElementsMatch(t, append(t1.Cids(), t2.Cids()...), validated)
+}
+
 // TODO: fix test
 func TestStoresMessageReceipts(t *testing.T) {
 	t.SkipNow()
@@ -596,7 +711,8 @@ func setupWithValidator(ctx context.Context, t *testing.T, builder *chain.Builde
 		builder.BlockStore(),
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		syncTypes.NewBadTipSetCache())
 	require.NoError(t, err)
 
 	return builder, syncer
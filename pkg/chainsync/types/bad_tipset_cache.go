@@ -1,48 +1,147 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
 
+// badTipSetsDs is the namespace of the bad tipsets in the datastore
+var badTipSetsDs = datastore.NewKey("/sync/bad")
+
+// badTipSetCacheSize is the number of tipsets found bad by the syncer kept in the cache
+const badTipSetCacheSize = 1 << 15
+
 // BadTipSetCache keeps track of bad tipsets that the syncer should not try to
-// download. Readers and writers grab a lock. The purpose of this cache is to
-// prevent a node from having to repeatedly invalidate a block (and its children)
-// in the event that the tipset does not conform to the rules of consensus. Note
-// that the cache is only in-memory, so it is reset whenever the node is restarted.
-// TODO: this needs to be limited.
+// download, with the reason they were marked bad. Readers and writers grab a lock.
+// The purpose of this cache is to prevent a node from having to repeatedly
+// invalidate a block (and its children) in the event that the tipset does not
+// conform to the rules of consensus. The tipsets found bad by the syncer are
+// kept in memory, the least recently used ones are evicted beyond
+// badTipSetCacheSize. The tipsets marked bad by the operator are kept until
+// they are removed, and when the cache is loaded from a datastore, they are
+// persisted and survive restarts.
 type BadTipSetCache struct {
-	mu  sync.Mutex
-	bad map[string]struct{}
+	mu     sync.Mutex
+	bad    *lru.Cache[string, string]
+	marked map[string]string
+	ds     datastore.Datastore
 }
 
+// NewBadTipSetCache creates an in-memory BadTipSetCache.
 func NewBadTipSetCache() *BadTipSetCache {
+	bad, _ := lru.New[string, string](badTipSetCacheSize)
 	return &BadTipSetCache{
-		bad: make(map[string]struct{}),
+		bad:    bad,
+		marked: make(map[string]string),
 	}
 }
 
-// AddChain adds the chain of tipsets to the BadTipSetCache.  For now it just
-// does the simplest thing and adds all blocks of the chain to the cache.
-// TODO: might want to cache a random subset once cache size is limited.
-func (cache *BadTipSetCache) AddChain(chain []*types.TipSet) {
+// LoadBadTipSetCache creates a BadTipSetCache persisted in ds, holding the bad tipsets stored in it.
+func LoadBadTipSetCache(ctx context.Context, ds datastore.Datastore) (*BadTipSetCache, error) {
+	cache := NewBadTipSetCache()
+	cache.ds = namespace.Wrap(ds, badTipSetsDs)
+
+	res, err := cache.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() // nolint: errcheck
+
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		var entry badTipSetEntry
+		if err := json.Unmarshal(r.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode bad tipset %s: %w", r.Key, err)
+		}
+		cache.marked[entry.Key.String()] = entry.Reason
+	}
+	return cache, nil
+}
+
+// badTipSetEntry is a bad tipset stored in the datastore
+type badTipSetEntry struct {
+	Key    types.TipSetKey
+	Reason string
+}
+
+func badTipSetDsKey(tsk types.TipSetKey) datastore.Key {
+	cids := make([]string, 0, len(tsk.Cids()))
+	for _, c := range tsk.Cids() {
+		cids = append(cids, c.String())
+	}
+	return datastore.NewKey(strings.Join(cids, "-"))
+}
+
+// AddChain adds the chain of tipsets found bad by the syncer to the BadTipSetCache.
+// They are only kept in memory, the least recently used ones are evicted once the
+// cache is full.
+func (cache *BadTipSetCache) AddChain(chain []*types.TipSet, reason string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
 	for _, ts := range chain {
-		cache.Add(ts.String())
+		cache.bad.Add(ts.Key().String(), reason)
 	}
 }
 
-// Add adds a single tipset key to the BadTipSetCache.
-func (cache *BadTipSetCache) Add(tsKey string) {
+// Add adds a single tipset key marked bad by the operator to the BadTipSetCache.
+// It is kept until it is removed, and persisted if the cache has a datastore.
+func (cache *BadTipSetCache) Add(ctx context.Context, tsk types.TipSetKey, reason string) error {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
-	cache.bad[tsKey] = struct{}{}
+
+	if cache.ds != nil {
+		val, err := json.Marshal(badTipSetEntry{Key: tsk, Reason: reason})
+		if err != nil {
+			return err
+		}
+		if err := cache.ds.Put(ctx, badTipSetDsKey(tsk), val); err != nil {
+			return err
+		}
+	}
+	cache.marked[tsk.String()] = reason
+	return nil
 }
 
-// Has checks for membership in the BadTipSetCache.
-func (cache *BadTipSetCache) Has(tsKey string) bool {
+// Remove removes a tipset key from the BadTipSetCache.
+func (cache *BadTipSetCache) Remove(ctx context.Context, tsk types.TipSetKey) error {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
-	_, ok := cache.bad[tsKey]
+
+	if cache.ds != nil {
+		if err := cache.ds.Delete(ctx, badTipSetDsKey(tsk)); err != nil {
+			return err
+		}
+	}
+	delete(cache.marked, tsk.String())
+	cache.bad.Remove(tsk.String())
+	return nil
+}
+
+// Has checks for membership in the BadTipSetCache.
+func (cache *BadTipSetCache) Has(tsk types.TipSetKey) bool {
+	_, ok := cache.Reason(tsk)
 	return ok
 }
+
+// Reason returns the reason a tipset key was added to the BadTipSetCache, and whether it is in it.
+func (cache *BadTipSetCache) Reason(tsk types.TipSetKey) (string, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if reason, ok := cache.marked[tsk.String()]; ok {
+		return reason, true
+	}
+	return cache.bad.Get(tsk.String())
+}
@@ -2,12 +2,16 @@
 package types
 
 import (
+	"context"
 	"testing"
 
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/venus/venus-shared/testutil"
 	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBadTipsetCache(t *testing.T) {
@@ -18,15 +22,71 @@ func TestBadTipsetCache(t *testing.T) {
 	testutil.Provide(t, &ts)
 
 	// stm: @CHAINSYNC_TYPES_ADD_CHAIN_001
-	badTSCache.AddChain([]*types.TipSet{&ts})
+	badTSCache.AddChain([]*types.TipSet{&ts}, "bad chain")
 
 	var tsKey types.TipSetKey
 	testutil.Provide(t, &tsKey, testutil.WithSliceLen(3))
 
 	// stm: @CHAINSYNC_TYPES_ADD_001
-	badTSCache.Add(tsKey.String())
+	assert.NoError(t, badTSCache.Add(context.Background(), tsKey, "bad tipset"))
 
 	// stm: @CHAINSYNC_TYPES_HAS_001
-	assert.True(t, badTSCache.Has(ts.Key().String()))
-	assert.True(t, badTSCache.Has(tsKey.String()))
+	assert.True(t, badTSCache.Has(ts.Key()))
+	assert.True(t, badTSCache.Has(tsKey))
+}
+
+func TestPersistedBadTipsetCache(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	ds := datastore.NewMapDatastore()
+
+	badTSCache, err := LoadBadTipSetCache(ctx, ds)
+	require.NoError(t, err)
+
+	var cids []cid.Cid
+	testutil.Provide(t, &cids, testutil.WithSliceLen(3))
+	tsKey, other := types.NewTipSetKey(cids[:2]...), types.NewTipSetKey(cids[2])
+	require.NoError(t, badTSCache.Add(ctx, tsKey, "bad tipset"))
+	require.NoError(t, badTSCache.Add(ctx, other, "other tipset"))
+	require.NoError(t, badTSCache.Remove(ctx, other))
+
+	// the tipsets found bad by the syncer are not persisted
+	var ts types.TipSet
+	testutil.Provide(t, &ts)
+	badTSCache.AddChain([]*types.TipSet{&ts}, "bad chain")
+	require.True(t, badTSCache.Has(ts.Key()))
+
+	// the entries marked by the operator survive a reload
+	badTSCache, err = LoadBadTipSetCache(ctx, ds)
+	require.NoError(t, err)
+	reason, ok := badTSCache.Reason(tsKey)
+	require.True(t, ok)
+	require.Equal(t, "bad tipset", reason)
+	require.False(t, badTSCache.Has(other))
+	require.False(t, badTSCache.Has(ts.Key()))
+}
+
+func TestBadTipsetCacheLimit(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	badTSCache := NewBadTipSetCache()
+
+	var marked types.TipSetKey
+	testutil.Provide(t, &marked, testutil.WithSliceLen(1))
+	require.NoError(t, badTSCache.Add(ctx, marked, "marked"))
+
+	var cids []cid.Cid
+	testutil.Provide(t, &cids, testutil.WithSliceLen(badTipSetCacheSize+1))
+	chain := make([]*types.TipSet, 0, len(cids))
+	for _, c := range cids {
+		ts, err := types.NewTipSet([]*types.BlockHeader{{Miner: testutil.IDAddressProvider()(t), Messages: c, ParentMessageReceipts: c, ParentStateRoot: c}})
+		require.NoError(t, err)
+		chain = append(chain, ts)
+	}
+	badTSCache.AddChain(chain, "bad chain")
+
+	// the least recently added tipset found bad by the syncer is evicted, not the marked one
+	require.False(t, badTSCache.Has(chain[0].Key()))
+	require.True(t, badTSCache.Has(chain[len(chain)-1].Key()))
+	require.True(t, badTSCache.Has(marked))
 }
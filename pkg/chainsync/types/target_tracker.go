@@ -412,9 +412,24 @@ func (tq *TargetTracker) Len() int {
 	return tq.q.Len()
 }
 
-// Buckets returns the number of targets in the queue.
-func (tq *TargetTracker) Buckets() TargetBuckets {
-	return tq.q
+// Buckets returns a copy of the targets in the queue, the heaviest first. The copies are taken under
+// the lock the states of the targets are set with.
+func (tq *TargetTracker) Buckets() []Target {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
+	targets := make([]Target, 0, len(tq.q))
+	for _, t := range tq.q {
+		targets = append(targets, *t)
+	}
+	return targets
+}
+
+// SetState sets the state of a target, and the error its sync failed with.
+func (tq *TargetTracker) SetState(t *Target, state SyncStateStage, err error) {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
+	t.State = state
+	t.Err = err
 }
 
 // TargetBuckets orders targets by a policy.
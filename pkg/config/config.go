@@ -40,6 +40,9 @@ type Config struct {
 	SlashFilterDs *SlashFilterDsConfig `json:"slashFilter"`
 	RateLimitCfg  *RateLimitCfg        `json:"rateLimit"`
 	FevmConfig    *FevmConfig          `json:"fevm"`
+	Index         *IndexConfig         `json:"index"`
+	Sync          *SyncConfig          `json:"sync"`
+	FaultReporter *FaultReporterConfig `json:"faultReporter"`
 }
 
 // APIConfig holds all configuration options related to the api.
@@ -76,8 +79,61 @@ func newDefaultAPIConfig() *APIConfig {
 // DatastoreConfig holds all the configuration options for the datastore.
 // TODO: use the advanced datastore configuration from ipfs
 type DatastoreConfig struct {
+	// Type is the type of the block datastore, "badgerds", "pebble", "splitstore" or "remote"
 	Type string `json:"type"`
 	Path string `json:"path"`
+	// SplitStore configures the hot/cold split blockstore, only used when Type is "splitstore"
+	SplitStore *SplitStoreConfig `json:"splitstore,omitempty"`
+	// Remote configures the remote blockstore read through by the local store, only used when Type is "remote"
+	Remote *RemoteStoreConfig `json:"remote,omitempty"`
+	// LazyState fetches the objects missing from the blockstore from the network with bitswap as they
+	// are read, it is set by a fast sync which only fetches the headers of the chain. It stays set once the
+	// state of the trusted tipset is prefetched, the older states, messages and receipts are only local once read
+	LazyState bool `json:"lazyState,omitempty"`
+	// Convert is the blockstore converted to this one by a running daemon, the objects written to
+	// it after the conversion are copied when the repo is opened next
+	Convert *DatastoreConvertConfig `json:"convert,omitempty"`
+}
+
+// DatastoreConvertConfig locates the blockstore a conversion started from.
+type DatastoreConvertConfig struct {
+	// Type is the type of the source blockstore, "badgerds" or "pebble"
+	Type string `json:"type"`
+	// Path is the path of the source blockstore, relative to the repo
+	Path string `json:"path"`
+}
+
+// RemoteStoreConfig holds the configuration options for a remote blockstore served over NetRPC
+// by another node. The local store lives in DatastoreConfig.Path, the objects missing from it are
+// read from the remote blockstore and cached locally.
+type RemoteStoreConfig struct {
+	// URL is the websocket url of the blockstore endpoint, e.g. ws://127.0.0.1:3453/rpc/blockstore/v0
+	URL string `json:"url"`
+	// Token is the token used to authenticate with the remote node, it needs the read permission
+	Token string `json:"token"`
+}
+
+// SplitStoreConfig holds the configuration options for the hot/cold split blockstore.
+// The hot store lives in DatastoreConfig.Path, objects that fall out of the live set
+// are moved to the cold store (or discarded) by the online compaction.
+type SplitStoreConfig struct {
+	// ColdStoreType is the type of the cold store, "badgerds" or "discard"
+	ColdStoreType string `json:"coldStoreType"`
+	// ColdStorePath is the path of the cold store, relative to the repo, only used by "badgerds"
+	ColdStorePath string `json:"coldStorePath"`
+	// HotStoreFinalities is the number of finalities of state kept in the hot store,
+	// a compaction is triggered every finality
+	HotStoreFinalities int `json:"hotStoreFinalities"`
+}
+
+// NewDefaultSplitStoreConfig returns the split store configuration used when Type is "splitstore"
+// and SplitStore is not set.
+func NewDefaultSplitStoreConfig() *SplitStoreConfig {
+	return &SplitStoreConfig{
+		ColdStoreType:      "badgerds",
+		ColdStorePath:      "coldstore",
+		HotStoreFinalities: 2,
+	}
 }
 
 // Validators hold the list of validation functions for each configuration
@@ -360,6 +416,8 @@ type MySQLConfig struct {
 }
 
 type SlashFilterDsConfig struct {
+	// Type is the backend the mined blocks are recorded in: local (the chain datastore), mysql or
+	// sqlite (a database in the sqlite directory of the repo)
 	Type  string      `json:"type"`
 	MySQL MySQLConfig `json:"mysql"`
 }
@@ -436,6 +494,49 @@ func newFevmConfig() *FevmConfig {
 	}
 }
 
+type IndexConfig struct {
+	// EnableMsgIndex enables indexing the messages included in the chain, which speeds up the
+	// lookup of messages by StateSearchMsg and StateWaitMsg.
+	EnableMsgIndex bool `json:"enableMsgIndex"`
+}
+
+func newIndexConfig() *IndexConfig {
+	return &IndexConfig{
+		EnableMsgIndex: false,
+	}
+}
+
+type SyncConfig struct {
+	// PipelineDepth is the number of segments of tipsets whose messages are fetched ahead of the
+	// segment being executed during catch-up.
+	PipelineDepth int `json:"pipelineDepth"`
+}
+
+func newSyncConfig() *SyncConfig {
+	return &SyncConfig{
+		PipelineDepth: 2,
+	}
+}
+
+type FaultReporterConfig struct {
+	// EnableDetector records the block headers received via gossip and hello, and detects the
+	// miners mining two blocks at the same epoch or on the same parents.
+	EnableDetector bool `json:"enableDetector"`
+	// ReportFrom is the address the detected faults are reported from with ReportConsensusFault
+	// messages, the faults aren't reported if it is empty.
+	ReportFrom address.Address `json:"reportFrom,omitempty"`
+	// MaxFee is the max fee of a ReportConsensusFault message.
+	MaxFee types.FIL `json:"maxFee"`
+}
+
+func newFaultReporterConfig() *FaultReporterConfig {
+	return &FaultReporterConfig{
+		EnableDetector: false,
+		ReportFrom:     address.Undef,
+		MaxFee:         types.MustParseFIL("1"),
+	}
+}
+
 // NewDefaultConfig returns a config object with all the fields filled out to
 // their default values
 func NewDefaultConfig() *Config {
@@ -451,6 +552,9 @@ func NewDefaultConfig() *Config {
 		SlashFilterDs: newDefaultSlashFilterDsConfig(),
 		RateLimitCfg:  newRateLimitConfig(),
 		FevmConfig:    newFevmConfig(),
+		Index:         newIndexConfig(),
+		Sync:          newSyncConfig(),
+		FaultReporter: newFaultReporterConfig(),
 	}
 }
 
@@ -133,7 +133,7 @@ func (bv *BlockValidator) ValidateFullBlock(ctx context.Context, blk *types.Bloc
 	if _, ok := bv.validateBlockCache.Get(blk.Cid()); ok {
 		return nil
 	}
-	err := bv.validateBlock(ctx, blk)
+	err := bv.validateBlock(ctx, blk, bv.runStateTransition)
 	if err != nil {
 		return err
 	}
@@ -144,7 +144,68 @@ func (bv *BlockValidator) ValidateFullBlock(ctx context.Context, blk *types.Bloc
 	return nil
 }
 
-func (bv *BlockValidator) validateBlock(ctx context.Context, blk *types.BlockHeader) error {
+// ValidateTipSet runs the checks of ValidateFullBlock on every block of the tipset and re-executes
+// the state transition of its parent, and reports the result. Unlike ValidateFullBlock, it ignores
+// the cache of validated blocks and doesn't add to it, and the parent is re-executed in a scratch
+// blockstore, without using or updating its computed state.
+func (bv *BlockValidator) ValidateTipSet(ctx context.Context, ts *types.TipSet) (*types.TipSetValidation, error) {
+	exec, ok := bv.Stmgr.(TipSetExecutor)
+	if !ok {
+		return nil, fmt.Errorf("state transformer %T can't re-execute tipsets", bv.Stmgr)
+	}
+	parent, err := bv.chainState.GetTipSet(ctx, ts.Parents())
+	if err != nil {
+		return nil, fmt.Errorf("load parent tipset failed %w", err)
+	}
+
+	res := &types.TipSetValidation{
+		TipSet:           ts.Key(),
+		Height:           ts.Height(),
+		ClaimedStateRoot: ts.At(0).ParentStateRoot,
+		ClaimedReceipts:  ts.At(0).ParentMessageReceipts,
+	}
+	stateRoot, receipt, _, execErr := exec.ExecuteTipSet(ctx, parent)
+	// the blocks are checked against the re-executed state
+	parentState := func(context.Context, *types.TipSet) (cid.Cid, cid.Cid, error) {
+		return stateRoot, receipt, execErr
+	}
+	if execErr != nil {
+		res.StateTransitionError = execErr.Error()
+	} else {
+		res.ComputedStateRoot = stateRoot
+		res.ComputedReceipts = receipt
+		res.Valid = stateRoot.Equals(res.ClaimedStateRoot) && receipt.Equals(res.ClaimedReceipts)
+	}
+
+	for _, blk := range ts.Blocks() {
+		blkRes := types.BlockValidation{
+			Cid:   blk.Cid(),
+			Miner: blk.Miner,
+		}
+		if err := bv.validateBlock(ctx, blk, parentState); err != nil {
+			if merr, ok := err.(*multierror.Error); ok {
+				for _, e := range merr.Errors {
+					blkRes.Errors = append(blkRes.Errors, e.Error())
+				}
+			} else {
+				blkRes.Errors = append(blkRes.Errors, err.Error())
+			}
+			res.Valid = false
+		}
+		res.Blocks = append(res.Blocks, blkRes)
+	}
+	return res, nil
+}
+
+// parentStateFunc returns the state root and the receipts root resulting from the execution of
+// the parent tipset.
+type parentStateFunc func(ctx context.Context, parent *types.TipSet) (root cid.Cid, receipt cid.Cid, err error)
+
+func (bv *BlockValidator) runStateTransition(ctx context.Context, parent *types.TipSet) (cid.Cid, cid.Cid, error) {
+	return bv.Stmgr.RunStateTransition(ctx, parent, nil, false)
+}
+
+func (bv *BlockValidator) validateBlock(ctx context.Context, blk *types.BlockHeader, parentState parentStateFunc) error {
 	parent, err := bv.chainState.GetTipSet(ctx, types.NewTipSetKey(blk.Parents...))
 	if err != nil {
 		return fmt.Errorf("load parent tipset failed %w", err)
@@ -196,7 +257,7 @@ func (bv *BlockValidator) validateBlock(ctx context.Context, blk *types.BlockHea
 	}
 
 	minerCheck := async.Err(func() error {
-		stateRoot, _, err := bv.Stmgr.RunStateTransition(ctx, parent, nil, false)
+		stateRoot, _, err := parentState(ctx, parent)
 		if err != nil {
 			return err
 		}
@@ -263,7 +324,7 @@ func (bv *BlockValidator) validateBlock(ctx context.Context, blk *types.BlockHea
 	})
 
 	msgsCheck := async.Err(func() error {
-		stateRoot, _, err := bv.Stmgr.RunStateTransition(ctx, parent, nil, false)
+		stateRoot, _, err := parentState(ctx, parent)
 		if err != nil {
 			return err
 		}
@@ -276,7 +337,7 @@ func (bv *BlockValidator) validateBlock(ctx context.Context, blk *types.BlockHea
 	})
 
 	stateRootCheck := async.Err(func() error {
-		stateRoot, receipt, err := bv.Stmgr.RunStateTransition(ctx, parent, nil, false)
+		stateRoot, receipt, err := parentState(ctx, parent)
 		if err != nil {
 			return fmt.Errorf("get tipsetstate(%d, %s) failed: %w", blk.Height, blk.Parents, err)
 		}
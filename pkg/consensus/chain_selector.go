@@ -82,26 +82,46 @@ func (c *ChainSelector) Weight(ctx context.Context, ts *types.TipSet) (fbig.Int,
 // vice versa.  In the rare case where two tipsets have the same weight ties
 // are broken by taking the tipset with more blocks.
 func (c *ChainSelector) IsHeavier(ctx context.Context, a, b *types.TipSet) (bool, error) {
+	heavier, _, err := c.compare(ctx, a, b, true)
+	return heavier, err
+}
+
+// ExplainHeavier compares tipset a to tipset b as IsHeavier does, and also returns why a wins or loses.
+func (c *ChainSelector) ExplainHeavier(ctx context.Context, a, b *types.TipSet) (bool, string, error) {
+	return c.compare(ctx, a, b, false)
+}
+
+func (c *ChainSelector) compare(ctx context.Context, a, b *types.TipSet, logTie bool) (bool, string, error) {
 	aW, err := c.Weight(ctx, a)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 	bW, err := c.Weight(ctx, b)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
-	heavier := aW.GreaterThan(bW)
-	if aW.Equals(bW) && !a.Equals(b) {
-		log.Errorw("weight draw", "currTs", a, "ts", b)
-		heavier = breakWeightTie(a, b)
+	switch {
+	case a.Equals(b):
+		return false, "same tipset", nil
+	case aW.GreaterThan(bW):
+		return true, fmt.Sprintf("weight %s greater than %s", aW, bW), nil
+	case aW.LessThan(bW):
+		return false, fmt.Sprintf("weight %s less than %s", aW, bW), nil
 	}
 
-	return heavier, nil
+	if logTie {
+		log.Errorw("weight draw", "currTs", a, "ts", b)
+	}
+	heavier, reason := breakWeightTie(a, b)
+	if logTie {
+		log.Infof("weight tie between %s and %s: %s", a.Key(), b.Key(), reason)
+	}
+	return heavier, fmt.Sprintf("same weight %s, %s", aW, reason), nil
 }
 
-// true if ts1 wins according to the filecoin tie-break rule
-func breakWeightTie(ts1, ts2 *types.TipSet) bool {
+// true if ts1 wins according to the filecoin tie-break rule, the reason of the result is returned too
+func breakWeightTie(ts1, ts2 *types.TipSet) (bool, string) {
 	s := len(ts1.Blocks())
 	if s > len(ts2.Blocks()) {
 		s = len(ts2.Blocks())
@@ -110,11 +130,9 @@ func breakWeightTie(ts1, ts2 *types.TipSet) bool {
 	// blocks are already sorted by ticket
 	for i := 0; i < s; i++ {
 		if ts1.Blocks()[i].Ticket.Less(ts2.Blocks()[i].Ticket) {
-			log.Infof("weight tie broken in favour of %s", ts1.Key())
-			return true
+			return true, fmt.Sprintf("tie broken by the smaller ticket of block %d (%s)", i, ts1.Blocks()[i].Cid())
 		}
 	}
 
-	log.Infof("weight tie left unbroken, default to %s", ts2.Key())
-	return false
+	return false, fmt.Sprintf("tie left unbroken, default to %s", ts2.Key())
 }
@@ -166,6 +166,23 @@ func NewExpected(cs cbor.IpldStore,
 // It errors if the tipset was not mined according to the EC rules, or if any of the messages
 // in the tipset results in an error.
 func (c *Expected) RunStateTransition(ctx context.Context, ts *types.TipSet, cb vm.ExecCallBack, vmTracing bool) (cid.Cid, cid.Cid, error) {
+	return c.runStateTransition(ctx, ts, cb, vmTracing, c.bstore, c.messageStore)
+}
+
+// RunStateTransitionInStore runs the state transition of the tipset like RunStateTransition, but
+// writes the new state and the receipts to bs instead of the node blockstore. bs must read the node
+// blockstore underneath, like a buffered blockstore on top of it.
+func (c *Expected) RunStateTransitionInStore(ctx context.Context, ts *types.TipSet, bs blockstoreutil.Blockstore) (cid.Cid, cid.Cid, error) {
+	return c.runStateTransition(ctx, ts, nil, false, bs, c.messageStore.WithBlockstore(bs))
+}
+
+func (c *Expected) runStateTransition(ctx context.Context,
+	ts *types.TipSet,
+	cb vm.ExecCallBack,
+	vmTracing bool,
+	bs blockstoreutil.Blockstore,
+	messageStore *chain.MessageStore,
+) (cid.Cid, cid.Cid, error) {
 	begin := time.Now()
 	defer func() {
 		logExpect.Infof("process ts height %d, blocks %d, took %.4f(s)", ts.Height(), ts.Len(), time.Since(begin).Seconds())
@@ -174,7 +191,7 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts *types.TipSet, cb
 	defer span.End()
 	span.AddAttributes(trace.StringAttribute("blocks", ts.String()))
 	span.AddAttributes(trace.Int64Attribute("height", int64(ts.Height())))
-	blockMessageInfo, err := c.messageStore.LoadTipSetMessage(ctx, ts)
+	blockMessageInfo, err := messageStore.LoadTipSetMessage(ctx, ts)
 	if err != nil {
 		return cid.Undef, cid.Undef, err
 	}
@@ -211,7 +228,7 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts *types.TipSet, cb
 		Epoch:               ts.At(0).Height,
 		Timestamp:           ts.MinTimestamp(),
 		GasPriceSchedule:    c.gasPirceSchedule,
-		Bsstore:             c.bstore,
+		Bsstore:             bs,
 		PRoot:               ts.At(0).ParentStateRoot,
 		SysCallsImpl:        c.syscallsImpl,
 		TipSetGetter:        vmcontext.TipSetGetterForTipset(c.chainState.GetTipSetByHeight, ts),
@@ -230,7 +247,7 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts *types.TipSet, cb
 		return cid.Undef, cid.Undef, errors.Wrap(err, "error validating tipset")
 	}
 
-	receiptCid, err := c.messageStore.StoreReceipts(ctx, receipts)
+	receiptCid, err := messageStore.StoreReceipts(ctx, receipts)
 	if err != nil {
 		return cid.Undef, cid.Undef, fmt.Errorf("failed to save receipt: %v", err)
 	}
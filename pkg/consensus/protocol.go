@@ -13,6 +13,7 @@ import (
 	"context"
 
 	"github.com/filecoin-project/venus/pkg/vm"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/ipfs/go-cid"
 )
@@ -38,3 +39,17 @@ type StateTransformer interface {
 	// prior `stateID`.  It returns an error if the transition is invalid.
 	RunStateTransition(ctx context.Context, ts *types.TipSet, cb vm.ExecCallBack, vmTracing bool) (root cid.Cid, receipt cid.Cid, err error)
 }
+
+// StoreStateTransformer runs the state transitions in a given blockstore.
+type StoreStateTransformer interface {
+	// RunStateTransitionInStore returns the state root and the receipts root resulting from applying
+	// the input ts to its parent state, writing them to bs.
+	RunStateTransitionInStore(ctx context.Context, ts *types.TipSet, bs blockstoreutil.Blockstore) (root cid.Cid, receipt cid.Cid, err error)
+}
+
+// TipSetExecutor re-executes tipsets without using or updating the computed states.
+type TipSetExecutor interface {
+	// ExecuteTipSet returns the state root and the receipts root resulting from applying the
+	// input ts to its parent state, and the scratch blockstore they are written to.
+	ExecuteTipSet(ctx context.Context, ts *types.TipSet) (root cid.Cid, receipt cid.Cid, bs blockstoreutil.Blockstore, err error)
+}
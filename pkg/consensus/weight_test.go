@@ -151,6 +151,45 @@ func TestWeight(t *testing.T) {
 		isHeavier, err := sel.IsHeavier(ctx, toWeighThreeBlock, toWeighTwoBlock)
 		assert.NoError(t, err)
 		assert.True(t, isHeavier)
+
+		isHeavier, reason, err := sel.ExplainHeavier(ctx, toWeighTwoBlock, toWeighThreeBlock)
+		assert.NoError(t, err)
+		assert.False(t, isHeavier)
+		assert.Equal(t, "weight 1228 less than 1331", reason)
+	})
+
+	t.Run("explain weight tie", func(t *testing.T) {
+		makeTipSet := func(proof byte) *types.TipSet {
+			return testhelpers.RequireNewTipSet(t, &types.BlockHeader{
+				Miner:        minerAddr,
+				ParentWeight: fbig.Zero(),
+				Ticket:       &types.Ticket{VRFProof: []byte{proof}},
+				ElectionProof: &types.ElectionProof{
+					WinCount: 1,
+				},
+				ParentStateRoot:       fakeRoot,
+				Messages:              testhelpers.EmptyMessagesCID,
+				ParentMessageReceipts: testhelpers.EmptyReceiptsCID,
+			})
+		}
+		winner, loser := makeTipSet(1), makeTipSet(2)
+		if loser.At(0).Ticket.Less(winner.At(0).Ticket) {
+			winner, loser = loser, winner
+		}
+
+		isHeavier, reason, err := sel.ExplainHeavier(ctx, winner, loser)
+		assert.NoError(t, err)
+		assert.True(t, isHeavier)
+		assert.Contains(t, reason, "same weight 1126, tie broken by the smaller ticket of block 0")
+
+		isHeavier, reason, err = sel.ExplainHeavier(ctx, loser, winner)
+		assert.NoError(t, err)
+		assert.False(t, isHeavier)
+		assert.Equal(t, "same weight 1126, tie left unbroken, default to "+winner.Key().String(), reason)
+
+		isHeavier, err = sel.IsHeavier(ctx, winner, loser)
+		assert.NoError(t, err)
+		assert.True(t, isHeavier)
 	})
 }
 
@@ -0,0 +1,376 @@
+package consensusfault
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var log = logging.Logger("consensusfault")
+
+// The types of the consensus faults found by the Detector.
+const (
+	FaultDoubleForkMining = "double-fork-mining"
+	FaultTimeOffsetMining = "time-offset-mining"
+	FaultParentGrinding   = "parent-grinding"
+)
+
+const (
+	// DefaultDetectorEpochs is the number of epochs of block headers kept by the Detector, the
+	// older faults can't be reported anymore.
+	DefaultDetectorEpochs = policy.ChainFinality
+	// maxHeadersPerEpoch bounds the block headers kept for an epoch.
+	maxHeadersPerEpoch = 1000
+	// maxFutureEpochs bounds how far ahead of the current epoch of the chain clock the recorded
+	// block headers can be, the ones beyond would prune the headers of the current epochs.
+	maxFutureEpochs = 1
+	// maxFaults bounds the faults kept by the Detector, the oldest are dropped first.
+	maxFaults = 1000
+)
+
+// Reporter reports a fault on chain, and returns the cid of the message reporting it.
+type Reporter func(ctx context.Context, fault *types.ConsensusFault) (cid.Cid, error)
+
+type minerEpoch struct {
+	miner address.Address
+	epoch abi.ChainEpoch
+}
+
+type minerParents struct {
+	miner   address.Address
+	parents types.TipSetKey
+}
+
+// epochClock returns the epoch of the chain at a given time.
+type epochClock interface {
+	EpochAtTime(t time.Time) abi.ChainEpoch
+}
+
+// Detector records the block headers received from the network, and detects the miners that
+// mined two blocks at the same epoch (double-fork mining), two blocks on the same parents at
+// different epochs (time-offset mining), or a block whose parents include a sibling of their own
+// block but not their own block (parent grinding). The headers of the last epochs only are kept.
+// The recorded headers must have been validated, their signature at least, the detector only
+// drops the ones ahead of the chain clock.
+type Detector struct {
+	lk sync.Mutex
+
+	clock     epochClock
+	epochs    abi.ChainEpoch
+	highest   abi.ChainEpoch
+	byEpoch   map[minerEpoch]*types.BlockHeader
+	byParents map[minerParents]*types.BlockHeader
+	heights   map[abi.ChainEpoch][]*types.BlockHeader
+	byCid     map[cid.Cid]*types.BlockHeader
+	// children holds the recorded headers by the cids of their parents
+	children map[cid.Cid][]*types.BlockHeader
+
+	faults []*types.ConsensusFault
+	// found holds the epochs of the faults found, by the cids of their blocks
+	found map[[2]cid.Cid]abi.ChainEpoch
+
+	reporter Reporter
+}
+
+// NewDetector creates a Detector keeping the block headers of the last epochs of the chain clock.
+func NewDetector(epochs abi.ChainEpoch, clock epochClock) *Detector {
+	return &Detector{
+		clock:     clock,
+		epochs:    epochs,
+		byEpoch:   make(map[minerEpoch]*types.BlockHeader),
+		byParents: make(map[minerParents]*types.BlockHeader),
+		heights:   make(map[abi.ChainEpoch][]*types.BlockHeader),
+		byCid:     make(map[cid.Cid]*types.BlockHeader),
+		children:  make(map[cid.Cid][]*types.BlockHeader),
+		found:     make(map[[2]cid.Cid]abi.ChainEpoch),
+	}
+}
+
+// SetReporter sets the reporter the faults are reported with once detected.
+func (d *Detector) SetReporter(reporter Reporter) {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	d.reporter = reporter
+}
+
+// AddBlock records a validated block header, and returns the fault it proves if any.
+func (d *Detector) AddBlock(blk *types.BlockHeader) *types.ConsensusFault {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	if blk.Height > d.clock.EpochAtTime(time.Now())+maxFutureEpochs {
+		return nil
+	}
+	if blk.Height <= d.highest-d.epochs || len(d.heights[blk.Height]) >= maxHeadersPerEpoch {
+		return nil
+	}
+	if _, ok := d.byCid[blk.Cid()]; ok {
+		return nil
+	}
+	if blk.Height > d.highest {
+		d.highest = blk.Height
+		d.prune()
+	}
+
+	meKey := minerEpoch{miner: blk.Miner, epoch: blk.Height}
+	mpKey := minerParents{miner: blk.Miner, parents: types.NewTipSetKey(blk.Parents...)}
+	var fault *types.ConsensusFault
+	if other, ok := d.byEpoch[meKey]; ok {
+		fault = d.newFault(FaultDoubleForkMining, other, blk, nil)
+	} else if other, ok := d.byParents[mpKey]; ok {
+		fault = d.newFault(FaultTimeOffsetMining, other, blk, nil)
+	} else {
+		d.byEpoch[meKey] = blk
+		d.byParents[mpKey] = blk
+		d.heights[blk.Height] = append(d.heights[blk.Height], blk)
+		d.byCid[blk.Cid()] = blk
+		for _, p := range blk.Parents {
+			d.children[p] = append(d.children[p], blk)
+		}
+		fault = d.parentGrinding(blk)
+	}
+	if fault == nil {
+		return nil
+	}
+
+	log.Warnf("detected %s consensus fault of miner %s at %d: blocks %s and %s",
+		fault.Type, fault.Miner, fault.Epoch, fault.Block1.Cid(), fault.Block2.Cid())
+	d.faults = append(d.faults, fault)
+	if len(d.faults) > maxFaults {
+		d.faults = d.faults[len(d.faults)-maxFaults:]
+	}
+	if d.reporter != nil {
+		go d.report(d.reporter, fault)
+	}
+
+	res := *fault
+	return &res
+}
+
+// IsParentGrinding returns whether the blocks of a miner prove a parent-grinding fault: b2 was
+// mined on parents including extra, mined at the same epoch on the same parents as b1, but not b1.
+func IsParentGrinding(b1, b2, extra *types.BlockHeader) bool {
+	return b1.Miner == b2.Miner && b1.Cid() != extra.Cid() &&
+		b1.Height == extra.Height && b2.Height > b1.Height &&
+		types.NewTipSetKey(b1.Parents...).Equals(types.NewTipSetKey(extra.Parents...)) &&
+		types.NewTipSetKey(b2.Parents...).Has(extra.Cid()) &&
+		!types.NewTipSetKey(b2.Parents...).Has(b1.Cid())
+}
+
+// parentGrinding returns a parent-grinding fault the newly recorded block is part of, or nil: a
+// block (b2) whose parents include a block (extra) but not the block (b1) mined by the same miner
+// at the same epoch on the same parents as extra. The block can be any of the three.
+func (d *Detector) parentGrinding(blk *types.BlockHeader) *types.ConsensusFault {
+	// check returns the fault proven by b2 and its parent extra, if any
+	check := func(b2, extra *types.BlockHeader) *types.ConsensusFault {
+		b1, ok := d.byEpoch[minerEpoch{miner: b2.Miner, epoch: extra.Height}]
+		if !ok || !IsParentGrinding(b1, b2, extra) {
+			return nil
+		}
+		return d.newFault(FaultParentGrinding, b1, b2, extra)
+	}
+
+	// blk is b2
+	for _, p := range blk.Parents {
+		if extra, ok := d.byCid[p]; ok {
+			if fault := check(blk, extra); fault != nil {
+				return fault
+			}
+		}
+	}
+	// blk is extra
+	for _, child := range d.children[blk.Cid()] {
+		if fault := check(child, blk); fault != nil {
+			return fault
+		}
+	}
+	// blk is b1, the siblings mined on the same parents are the candidates for extra
+	pKey := types.NewTipSetKey(blk.Parents...)
+	for _, sibling := range d.heights[blk.Height] {
+		if sibling == blk || !types.NewTipSetKey(sibling.Parents...).Equals(pKey) {
+			continue
+		}
+		for _, child := range d.children[sibling.Cid()] {
+			if child.Miner != blk.Miner {
+				continue
+			}
+			if fault := check(child, sibling); fault != nil {
+				return fault
+			}
+		}
+	}
+	return nil
+}
+
+// newFault returns the fault proven by the two blocks and the extra block if any, or nil if it
+// was already found.
+func (d *Detector) newFault(typ string, b1, b2, extra *types.BlockHeader) *types.ConsensusFault {
+	if b1.Cid() == b2.Cid() {
+		return nil
+	}
+	// the blocks are ordered by epoch, as required by the miner actor
+	if b2.Height < b1.Height || (b2.Height == b1.Height && b2.Cid().KeyString() < b1.Cid().KeyString()) {
+		b1, b2 = b2, b1
+	}
+	key := [2]cid.Cid{b1.Cid(), b2.Cid()}
+	if _, ok := d.found[key]; ok {
+		return nil
+	}
+	d.found[key] = b1.Height
+
+	return &types.ConsensusFault{
+		Type:     typ,
+		Miner:    b1.Miner,
+		Epoch:    b1.Height,
+		Block1:   b1,
+		Block2:   b2,
+		Extra:    extra,
+		Detected: time.Now(),
+	}
+}
+
+// prune removes the block headers older than the kept epochs.
+func (d *Detector) prune() {
+	for h, blks := range d.heights {
+		if h > d.highest-d.epochs {
+			continue
+		}
+		for _, blk := range blks {
+			delete(d.byEpoch, minerEpoch{miner: blk.Miner, epoch: blk.Height})
+			mpKey := minerParents{miner: blk.Miner, parents: types.NewTipSetKey(blk.Parents...)}
+			if d.byParents[mpKey] == blk {
+				delete(d.byParents, mpKey)
+			}
+			delete(d.byCid, blk.Cid())
+			for _, p := range blk.Parents {
+				d.children[p] = removeHeader(d.children[p], blk)
+				if len(d.children[p]) == 0 {
+					delete(d.children, p)
+				}
+			}
+		}
+		delete(d.heights, h)
+	}
+	for key, h := range d.found {
+		if h <= d.highest-d.epochs {
+			delete(d.found, key)
+		}
+	}
+}
+
+func removeHeader(blks []*types.BlockHeader, blk *types.BlockHeader) []*types.BlockHeader {
+	for i, b := range blks {
+		if b == blk {
+			return append(blks[:i], blks[i+1:]...)
+		}
+	}
+	return blks
+}
+
+func (d *Detector) report(reporter Reporter, fault *types.ConsensusFault) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	c, err := reporter(ctx, fault)
+
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	if err != nil {
+		log.Errorf("failed to report consensus fault of miner %s at %d: %v", fault.Miner, fault.Epoch, err)
+		fault.ReportError = err.Error()
+		return
+	}
+	log.Infof("reported consensus fault of miner %s at %d in message %s", fault.Miner, fault.Epoch, c)
+	fault.Report = &c
+}
+
+// Faults returns the detected faults, most recent first.
+func (d *Detector) Faults() []*types.ConsensusFault {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	res := make([]*types.ConsensusFault, 0, len(d.faults))
+	for i := len(d.faults) - 1; i >= 0; i-- {
+		f := *d.faults[i]
+		res = append(res, &f)
+	}
+	return res
+}
+
+// NewReportMessage returns the ReportConsensusFault message reporting the fault to the miner actor.
+func NewReportMessage(from address.Address, fault *types.ConsensusFault) (*types.Message, error) {
+	h1, err := fault.Block1.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	h2, err := fault.Block2.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	var extra []byte
+	if fault.Extra != nil {
+		if extra, err = fault.Extra.Serialize(); err != nil {
+			return nil, err
+		}
+	}
+	params, aerr := actors.SerializeParams(&types.ReportConsensusFaultParams{
+		BlockHeader1:     h1,
+		BlockHeader2:     h2,
+		BlockHeaderExtra: extra,
+	})
+	if aerr != nil {
+		return nil, fmt.Errorf("failed to serialize params: %w", aerr)
+	}
+
+	return &types.Message{
+		To:     fault.Miner,
+		From:   from,
+		Value:  big.Zero(),
+		Method: builtintypes.MethodsMiner.ReportConsensusFault,
+		Params: params,
+	}, nil
+}
+
+type messageCaller interface {
+	StateCall(ctx context.Context, msg *types.Message, tsk types.TipSetKey) (*types.InvocResult, error)
+}
+
+type messagePusher interface {
+	MpoolPushMessage(ctx context.Context, msg *types.Message, spec *types.MessageSendSpec) (*types.SignedMessage, error)
+}
+
+// NewMessageReporter returns a Reporter sending the ReportConsensusFault messages from the
+// address, once the report is checked against the head.
+func NewMessageReporter(from address.Address, maxFee types.FIL, caller messageCaller, pusher messagePusher) Reporter {
+	return func(ctx context.Context, fault *types.ConsensusFault) (cid.Cid, error) {
+		msg, err := NewReportMessage(from, fault)
+		if err != nil {
+			return cid.Undef, err
+		}
+		res, err := caller.StateCall(ctx, msg, types.EmptyTSK)
+		if err != nil {
+			return cid.Undef, fmt.Errorf("failed to simulate report: %w", err)
+		}
+		if res.MsgRct.ExitCode.IsError() {
+			return cid.Undef, fmt.Errorf("report would fail with exit code %d: %s", res.MsgRct.ExitCode, res.Error)
+		}
+		smsg, err := pusher.MpoolPushMessage(ctx, msg, &types.MessageSendSpec{MaxFee: types.BigInt(maxFee)})
+		if err != nil {
+			return cid.Undef, err
+		}
+		return smsg.Cid(), nil
+	}
+}
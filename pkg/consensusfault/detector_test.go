@@ -0,0 +1,201 @@
+package consensusfault
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func mkBlock(t *testing.T, miner uint64, height abi.ChainEpoch, parent cid.Cid, ticket string) *types.BlockHeader {
+	addr, err := address.NewIDAddress(miner)
+	require.NoError(t, err)
+	c, err := cid.Decode("bafyreicmaj5hhoy5mgqvamfhgexxyergw7hdeshizghodwkjg6qmpoco7i")
+	require.NoError(t, err)
+
+	var parents []cid.Cid
+	if parent.Defined() {
+		parents = append(parents, parent)
+	}
+	return &types.BlockHeader{
+		Miner:                 addr,
+		Ticket:                &types.Ticket{VRFProof: []byte(ticket)},
+		ElectionProof:         &types.ElectionProof{VRFProof: []byte(ticket)},
+		Parents:               parents,
+		ParentWeight:          big.Zero(),
+		Height:                height,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+		ParentBaseFee:         big.Zero(),
+	}
+}
+
+// fixedClock is a chain clock at a fixed epoch.
+type fixedClock abi.ChainEpoch
+
+func (c fixedClock) EpochAtTime(time.Time) abi.ChainEpoch {
+	return abi.ChainEpoch(c)
+}
+
+func TestDetector(t *testing.T) {
+	tf.UnitTest(t)
+
+	parent1 := mkBlock(t, 1, 9, cid.Undef, "p1").Cid()
+	parent2 := mkBlock(t, 1, 9, cid.Undef, "p2").Cid()
+	d := NewDetector(10, fixedClock(30))
+
+	blk := mkBlock(t, 1000, 10, parent1, "a")
+	assert.Nil(t, d.AddBlock(blk))
+	assert.Nil(t, d.AddBlock(blk))
+	// other miners don't equivocate
+	assert.Nil(t, d.AddBlock(mkBlock(t, 1001, 10, parent1, "b")))
+
+	t.Run("double fork mining", func(t *testing.T) {
+		other := mkBlock(t, 1000, 10, parent2, "c")
+		fault := d.AddBlock(other)
+		require.NotNil(t, fault)
+		assert.Equal(t, FaultDoubleForkMining, fault.Type)
+		assert.Equal(t, blk.Miner, fault.Miner)
+		assert.Equal(t, abi.ChainEpoch(10), fault.Epoch)
+		assert.ElementsMatch(t, []cid.Cid{blk.Cid(), other.Cid()}, []cid.Cid{fault.Block1.Cid(), fault.Block2.Cid()})
+
+		// a fault is found once
+		assert.Nil(t, d.AddBlock(other))
+	})
+
+	t.Run("time offset mining", func(t *testing.T) {
+		other := mkBlock(t, 1000, 11, parent1, "d")
+		fault := d.AddBlock(other)
+		require.NotNil(t, fault)
+		assert.Equal(t, FaultTimeOffsetMining, fault.Type)
+		// the blocks are ordered by epoch
+		assert.Equal(t, blk.Cid(), fault.Block1.Cid())
+		assert.Equal(t, other.Cid(), fault.Block2.Cid())
+	})
+
+	faults := d.Faults()
+	require.Len(t, faults, 2)
+	assert.Equal(t, FaultTimeOffsetMining, faults[0].Type)
+	assert.Equal(t, FaultDoubleForkMining, faults[1].Type)
+
+	t.Run("blocks ahead of the chain clock are dropped", func(t *testing.T) {
+		assert.Nil(t, d.AddBlock(mkBlock(t, 1003, 1000, parent1, "g")))
+		assert.Equal(t, abi.ChainEpoch(11), d.highest)
+		assert.NotNil(t, d.byEpoch[minerEpoch{miner: blk.Miner, epoch: 10}])
+	})
+
+	t.Run("old blocks are pruned", func(t *testing.T) {
+		assert.Nil(t, d.AddBlock(mkBlock(t, 1002, 30, parent1, "e")))
+		assert.Nil(t, d.AddBlock(mkBlock(t, 1000, 10, parent2, "f")))
+		assert.Empty(t, d.byEpoch[minerEpoch{miner: blk.Miner, epoch: 10}])
+		assert.Len(t, d.Faults(), 2)
+	})
+}
+
+func TestDetectorReporter(t *testing.T) {
+	tf.UnitTest(t)
+
+	parent := mkBlock(t, 1, 9, cid.Undef, "p").Cid()
+	d := NewDetector(10, fixedClock(10))
+	reported := make(chan *types.Message, 1)
+	d.SetReporter(func(ctx context.Context, fault *types.ConsensusFault) (cid.Cid, error) {
+		msg, err := NewReportMessage(fault.Miner, fault)
+		if err != nil {
+			return cid.Undef, err
+		}
+		reported <- msg
+		return msg.Cid(), nil
+	})
+
+	blk1 := mkBlock(t, 1000, 10, parent, "a")
+	blk2 := mkBlock(t, 1000, 10, parent, "b")
+	assert.Nil(t, d.AddBlock(blk1))
+	require.NotNil(t, d.AddBlock(blk2))
+
+	var msg *types.Message
+	select {
+	case msg = <-reported:
+	case <-time.After(10 * time.Second):
+		t.Fatal("fault wasn't reported")
+	}
+	assert.Equal(t, blk1.Miner, msg.To)
+	assert.Equal(t, builtintypes.MethodsMiner.ReportConsensusFault, msg.Method)
+
+	var params types.ReportConsensusFaultParams
+	require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+	h1, err := blk1.Serialize()
+	require.NoError(t, err)
+	h2, err := blk2.Serialize()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][]byte{h1, h2}, [][]byte{params.BlockHeader1, params.BlockHeader2})
+
+	require.Eventually(t, func() bool {
+		return d.Faults()[0].Report != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, msg.Cid(), *d.Faults()[0].Report)
+}
+
+func TestDetectorParentGrinding(t *testing.T) {
+	tf.UnitTest(t)
+
+	parent := mkBlock(t, 1, 9, cid.Undef, "p").Cid()
+	// the miner 1000 mines b2 on the tipset of the block of 1001 at 10, omitting its own block b1
+	b1 := mkBlock(t, 1000, 10, parent, "a")
+	extra := mkBlock(t, 1001, 10, parent, "b")
+	b2 := mkBlock(t, 1000, 11, extra.Cid(), "c")
+	// the block of the miner 1002 on the same tipset doesn't omit a block of its own
+	other := mkBlock(t, 1002, 11, extra.Cid(), "d")
+
+	for name, order := range map[string][]*types.BlockHeader{
+		"extra, b1, b2": {extra, b1, other, b2},
+		"b1, b2, extra": {b1, other, b2, extra},
+		"b2, extra, b1": {b2, other, extra, b1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := NewDetector(10, fixedClock(11))
+			var fault *types.ConsensusFault
+			for i, blk := range order {
+				fault = d.AddBlock(blk)
+				if i < len(order)-1 {
+					require.Nil(t, fault)
+				}
+			}
+			require.NotNil(t, fault)
+			assert.Equal(t, FaultParentGrinding, fault.Type)
+			assert.Equal(t, b1.Miner, fault.Miner)
+			assert.Equal(t, b1.Cid(), fault.Block1.Cid())
+			assert.Equal(t, b2.Cid(), fault.Block2.Cid())
+			assert.Equal(t, extra.Cid(), fault.Extra.Cid())
+
+			msg, err := NewReportMessage(b1.Miner, fault)
+			require.NoError(t, err)
+			var params types.ReportConsensusFaultParams
+			require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+			h, err := extra.Serialize()
+			require.NoError(t, err)
+			assert.Equal(t, h, params.BlockHeaderExtra)
+		})
+	}
+
+	// a block including its own block isn't a fault
+	d := NewDetector(10, fixedClock(11))
+	assert.Nil(t, d.AddBlock(b1))
+	assert.Nil(t, d.AddBlock(extra))
+	assert.Nil(t, d.AddBlock(mkBlock(t, 1000, 11, b1.Cid(), "e")))
+	assert.Empty(t, d.Faults())
+}
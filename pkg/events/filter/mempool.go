@@ -91,7 +91,7 @@ func (m *MemPoolFilterManager) WaitForMpoolUpdates(ctx context.Context, ch <-cha
 
 func (m *MemPoolFilterManager) processUpdate(ctx context.Context, u types.MpoolUpdate) {
 	// only process added messages
-	if u.Type == types.MpoolRemove {
+	if u.Type != types.MpoolAdd {
 		return
 	}
 
@@ -0,0 +1,280 @@
+package messagepool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// FeeBumpInterval is the interval the pending local messages are checked at.
+var FeeBumpInterval = time.Duration(constants.MainNetBlockDelaySecs) * time.Second
+
+// WalletSigner signs the messages created by the message pool, like the bumped messages, with the keys
+// of the wallet.
+type WalletSigner interface {
+	WalletSign(ctx context.Context, k address.Address, msg []byte, meta types.MsgMeta) (*crypto.Signature, error)
+}
+
+// FeeBumper watches the pending messages of the local addresses, and replaces the messages pending for
+// FeeBump.StuckEpochs of the config with messages paying a premium bumped by the replace by fee ratio.
+// Only the underpriced messages are bumped: the ones whose fee cap is below the base fee, or whose
+// premium is below the premiums included by the recent blocks. The messages behind a nonce gap, or
+// which the balance can't cover, are left alone since no fee gets them included.
+// The fee cap of a bumped message covers the current base fee, and its fee is capped by FeeBump.MaxFee
+// or the default max fee.
+type FeeBumper struct {
+	mp     *MessagePool
+	signer WalletSigner
+
+	// pending records the epoch the pending local messages were first seen at
+	pending map[address.Address]map[uint64]pendingMsg
+}
+
+type pendingMsg struct {
+	cid   cid.Cid
+	since abi.ChainEpoch
+}
+
+// NewFeeBumper creates a fee bumper of the local messages of mp, signing the bumped messages with signer.
+func NewFeeBumper(mp *MessagePool, signer WalletSigner) *FeeBumper {
+	return &FeeBumper{
+		mp:      mp,
+		signer:  signer,
+		pending: make(map[address.Address]map[uint64]pendingMsg),
+	}
+}
+
+// Run checks the local messages every FeeBumpInterval until ctx is done or the message pool is closed.
+func (fb *FeeBumper) Run(ctx context.Context) {
+	if fb.mp.api.IsLite() {
+		return
+	}
+
+	tk := constants.Clock.Ticker(FeeBumpInterval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			if _, err := fb.bumpStuck(ctx); err != nil {
+				log.Errorf("error while bumping stuck messages: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		case <-fb.mp.closer:
+			return
+		}
+	}
+}
+
+// bumpStuck bumps the fee of the local messages pending for FeeBump.StuckEpochs, and returns the bumped messages.
+func (fb *FeeBumper) bumpStuck(ctx context.Context) ([]*types.SignedMessage, error) {
+	cfg := fb.mp.GetConfig()
+	if cfg.FeeBump.StuckEpochs <= 0 {
+		fb.pending = make(map[address.Address]map[uint64]pendingMsg)
+		return nil, nil
+	}
+
+	fb.mp.curTSLk.Lock()
+	ts := fb.mp.curTS
+	fb.mp.curTSLk.Unlock()
+
+	baseFee, err := fb.mp.api.ChainComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("computing basefee: %w", err)
+	}
+
+	fb.mp.lk.Lock()
+	msgs := make(map[address.Address][]*types.SignedMessage)
+	fb.mp.forEachLocal(ctx, func(ctx context.Context, addr address.Address) {
+		mset, ok, err := fb.mp.getPendingMset(ctx, addr)
+		if err != nil || !ok {
+			return
+		}
+		for _, m := range mset.msgs {
+			msgs[addr] = append(msgs[addr], m)
+		}
+	})
+	fb.mp.lk.Unlock()
+
+	// the lowest premium included by the recent blocks, a stuck message paying less is outbid
+	minIncluded, err := fb.minIncludedPremium(ctx, ts, int(cfg.FeeBump.StuckEpochs))
+	if err != nil {
+		return nil, fmt.Errorf("loading the recent gas premiums: %w", err)
+	}
+
+	// the messages which left the pool are dropped, the new ones are pending since the current epoch
+	type stuckMsg struct {
+		addr address.Address
+		msg  *types.SignedMessage
+	}
+	pending := make(map[address.Address]map[uint64]pendingMsg, len(msgs))
+	var stuck []stuckMsg
+	for addr, ms := range msgs {
+		pending[addr] = make(map[uint64]pendingMsg, len(ms))
+		for _, m := range ms {
+			p, ok := fb.pending[addr][m.Message.Nonce]
+			if !ok || p.cid != m.Cid() {
+				p = pendingMsg{cid: m.Cid(), since: ts.Height()}
+			}
+			pending[addr][m.Message.Nonce] = p
+		}
+
+		includable, err := fb.includable(ctx, addr, ms, ts)
+		if err != nil {
+			log.Warnf("failed to check the pending messages of %s: %s", addr, err)
+			continue
+		}
+		for _, m := range includable {
+			if ts.Height()-pending[addr][m.Message.Nonce].since < cfg.FeeBump.StuckEpochs {
+				continue
+			}
+			// a message paying the base fee and the premium of the recent blocks waits for room
+			if m.Message.GasFeeCap.GreaterThanEqual(baseFee) && (minIncluded.Nil() || m.Message.GasPremium.GreaterThanEqual(minIncluded)) {
+				continue
+			}
+			stuck = append(stuck, stuckMsg{addr: addr, msg: m})
+		}
+	}
+	fb.pending = pending
+
+	var bumped []*types.SignedMessage
+	for _, s := range stuck {
+		m := s.msg
+		nm, err := fb.bump(ctx, m, baseFee, cfg)
+		if err != nil {
+			log.Warnf("failed to bump the fee of message %s from %s with nonce %d: %s", m.Cid(), m.Message.From, m.Message.Nonce, err)
+			continue
+		}
+		log.Infow("bumped the fee of a stuck message", "from", m.Message.From, "nonce", m.Message.Nonce,
+			"old", m.Cid(), "new", nm.Cid(), "premium", nm.Message.GasPremium, "feecap", nm.Message.GasFeeCap)
+		fb.pending[s.addr][m.Message.Nonce] = pendingMsg{cid: nm.Cid(), since: ts.Height()}
+		bumped = append(bumped, nm)
+	}
+
+	return bumped, nil
+}
+
+// includable returns the pending messages of addr which can be included on ts: the messages following
+// the state nonce without gap, as long as the balance covers them.
+func (fb *FeeBumper) includable(ctx context.Context, addr address.Address, ms []*types.SignedMessage, ts *types.TipSet) ([]*types.SignedMessage, error) {
+	nonce, err := fb.mp.getStateNonce(ctx, addr, ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the state nonce: %w", err)
+	}
+	balance, err := fb.mp.getStateBalance(ctx, addr, ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the state balance: %w", err)
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Message.Nonce < ms[j].Message.Nonce
+	})
+
+	var out []*types.SignedMessage
+	for _, m := range ms {
+		if m.Message.Nonce < nonce {
+			continue
+		}
+		if m.Message.Nonce > nonce {
+			break // nonce gap
+		}
+		balance = big.Sub(balance, big.Add(m.Message.RequiredFunds(), m.Message.Value))
+		if balance.LessThan(big.Zero()) {
+			break
+		}
+		out = append(out, m)
+		nonce++
+	}
+	return out, nil
+}
+
+// minIncludedPremium returns the lowest premium of the messages included by the n parents of ts, or
+// an undefined amount if they include none.
+func (fb *FeeBumper) minIncludedPremium(ctx context.Context, ts *types.TipSet, n int) (abi.TokenAmount, error) {
+	window, err := fb.mp.gasStatsWindow(ctx, fb.mp.PriceCache, ts, n)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	var lowest abi.TokenAmount
+	for _, stats := range window {
+		if len(stats.Prices) == 0 {
+			continue
+		}
+		if premium := lowestGasPremium(stats.Prices); lowest.Nil() || premium.LessThan(lowest) {
+			lowest = premium
+		}
+	}
+	return lowest, nil
+}
+
+// bump signs and pushes the replacement of m paying a bumped premium.
+func (fb *FeeBumper) bump(ctx context.Context, m *types.SignedMessage, baseFee abi.TokenAmount, cfg *MpoolConfig) (*types.SignedMessage, error) {
+	msg := m.Message
+	msg.GasPremium = ComputeRBF(msg.GasPremium, cfg.ReplaceByFeeRatio)
+	msg.GasFeeCap = big.Max(msg.GasFeeCap, big.Add(baseFee, msg.GasPremium))
+	CapGasFee(fb.mp.GetMaxFee, &msg, &types.MessageSendSpec{MaxFee: cfg.FeeBump.MaxFee})
+
+	if minPremium := ComputeMinRBF(m.Message.GasPremium); msg.GasPremium.LessThan(minPremium) {
+		return nil, fmt.Errorf("the max fee caps the premium to %s, less than the %s required to replace the message", msg.GasPremium, minPremium)
+	}
+
+	nm, err := signMessage(ctx, fb.signer, &msg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fb.mp.Push(ctx, nm); err != nil {
+		return nil, fmt.Errorf("failed to push the bumped message: %w", err)
+	}
+
+	fb.mp.changes.Pub(types.MpoolUpdate{
+		Type:    types.MpoolBump,
+		Message: nm,
+	}, localUpdates)
+
+	fb.mp.journal.RecordEvent(fb.mp.evtTypes[evtTypeMpoolBump], func() interface{} {
+		return MessagePoolEvt{
+			Action: "bump",
+			Messages: []MessagePoolEvtMessage{
+				{Message: m.Message, CID: m.Cid()},
+				{Message: nm.Message, CID: nm.Cid()},
+			},
+		}
+	})
+
+	return nm, nil
+}
+
+// signMessage signs msg with signer, the way the MessageSigner does.
+func signMessage(ctx context.Context, signer WalletSigner, msg *types.Message) (*types.SignedMessage, error) {
+	sb, err := msg.SigningBytes(types.AddressProtocol2SignType(msg.From.Protocol()))
+	if err != nil {
+		return nil, err
+	}
+	mb, err := msg.ToStorageBlock()
+	if err != nil {
+		return nil, fmt.Errorf("serializing message: %w", err)
+	}
+	sig, err := signer.WalletSign(ctx, msg.From, sb, types.MsgMeta{
+		Type:  types.MTChainMsg,
+		Extra: mb.RawData(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	return &types.SignedMessage{
+		Message:   *msg,
+		Signature: *sig,
+	}, nil
+}
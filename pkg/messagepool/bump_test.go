@@ -0,0 +1,171 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestFeeBumper(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tma := newTestMpoolAPI()
+	w, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+
+	sender, err := w.NewAddress(ctx, address.SECP256K1)
+	require.NoError(t, err)
+	tma.setStateNonce(sender, 0)
+
+	msg := mkMessage(sender, mkAddress(1001), 0, w)
+	_, err = mp.Push(ctx, msg)
+	require.NoError(t, err)
+
+	fb := NewFeeBumper(mp, w)
+
+	// disabled by default
+	bumped, err := fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	cfg := mp.GetConfig()
+	cfg.FeeBump = types.MpoolFeeBump{StuckEpochs: 2}
+	require.NoError(t, mp.SetConfig(ctx, cfg))
+	// a config without max fee can be loaded back
+	loaded, err := loadConfig(ctx, mp.ds)
+	require.NoError(t, err)
+	assert.Equal(t, mp.GetConfig().FeeBump, loaded.FeeBump)
+
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	tma.applyBlock(t, tma.nextBlock())
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	updates, err := mp.Updates(ctx)
+	require.NoError(t, err)
+
+	// the fee cap of 100 covers the base fee, and the recent blocks include no message
+	tma.applyBlock(t, tma.nextBlock())
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	tma.baseFee = abi.NewTokenAmount(101)
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	require.Len(t, bumped, 1)
+
+	// 1 * 125% + 1, and the fee cap covers the base fee of 101
+	nm := bumped[0]
+	assert.Equal(t, abi.NewTokenAmount(2), nm.Message.GasPremium)
+	assert.Equal(t, abi.NewTokenAmount(103), nm.Message.GasFeeCap)
+	assert.Equal(t, msg.Message.Nonce, nm.Message.Nonce)
+	assert.NoError(t, mp.VerifyMsgSig(nm))
+
+	pending, _ := mp.PendingFor(ctx, sender)
+	require.Len(t, pending, 1)
+	assert.Equal(t, nm.Cid(), pending[0].Cid())
+
+	timeout := time.After(5 * time.Second)
+	for found := false; !found; {
+		select {
+		case u := <-updates:
+			found = u.Type == types.MpoolBump && u.Message.Cid() == nm.Cid()
+		case <-timeout:
+			t.Fatal("no bump update")
+		}
+	}
+
+	// the replacement is pending since its bump
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	// the max fee leaves no room for a premium high enough to replace the message
+	cfg.FeeBump.MaxFee = abi.NewTokenAmount(1)
+	require.NoError(t, mp.SetConfig(ctx, cfg))
+	tma.applyBlock(t, tma.nextBlock())
+	tma.applyBlock(t, tma.nextBlock())
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+}
+
+func TestFeeBumperUnderpricedMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	tma := newTestMpoolAPI()
+	w, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+
+	cfg := mp.GetConfig()
+	cfg.FeeBump = types.MpoolFeeBump{StuckEpochs: 1}
+	require.NoError(t, mp.SetConfig(ctx, cfg))
+
+	newSender := func() address.Address {
+		sender, err := w.NewAddress(ctx, address.SECP256K1)
+		require.NoError(t, err)
+		tma.setStateNonce(sender, 0)
+		return sender
+	}
+	push := func(sender address.Address, nonce uint64) *types.SignedMessage {
+		msg := mkMessage(sender, mkAddress(1001), nonce, w)
+		_, err := mp.Push(ctx, msg)
+		require.NoError(t, err)
+		return msg
+	}
+
+	outbid := newSender()
+	outbidMsg := push(outbid, 0)
+	// the nonce 0 is missing
+	gap := newSender()
+	push(gap, 1)
+	// the balance no longer covers the message
+	poor := newSender()
+	poorMsg := push(poor, 0)
+	tma.setBalanceRaw(poor, poorMsg.Message.RequiredFunds())
+
+	fb := NewFeeBumper(mp, w)
+	_, err := fb.bumpStuck(ctx)
+	require.NoError(t, err)
+
+	// the parent of the head includes messages paying a premium of 1, like the pending ones
+	other, err := w.NewAddress(ctx, address.SECP256K1)
+	require.NoError(t, err)
+	blk := tma.nextBlock()
+	tma.setBlockMessages(blk, mkMessage(other, mkAddress(1001), 0, w))
+	tma.applyBlock(t, blk)
+	tma.applyBlock(t, tma.nextBlock())
+	bumped, err := fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bumped, 0)
+
+	// the parent of the head includes higher premiums, only the includable message is bumped
+	msg := mkMessage(other, mkAddress(1001), 1, w)
+	msg.Message.GasPremium = abi.NewTokenAmount(10)
+	blk = tma.nextBlock()
+	tma.setBlockMessages(blk, msg)
+	tma.applyBlock(t, blk)
+	tma.applyBlock(t, tma.nextBlock())
+	bumped, err = fb.bumpStuck(ctx)
+	require.NoError(t, err)
+	require.Len(t, bumped, 1)
+	assert.Equal(t, outbidMsg.Message.From, bumped[0].Message.From)
+	assert.Equal(t, outbidMsg.Message.Nonce, bumped[0].Message.Nonce)
+}
@@ -2,6 +2,7 @@ package messagepool
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	stdbig "math/big"
 	"sort"
@@ -423,6 +424,23 @@ func (mp *MessagePool) checkMessages(ctx context.Context, msgs []*types.Message,
 		}
 
 		result[i] = append(result[i], check)
+
+		// 11. Policy, the admission rate doesn't apply to the messages already in the pool
+		check = types.MessageCheckStatus{
+			Cid: m.Cid(),
+			CheckStatus: types.CheckStatus{
+				Code: types.CheckStatusMessagePolicy,
+			},
+		}
+
+		if err := mp.checkPolicy(ctx, m, curTS, false); err != nil && !(interned && errors.Is(err, ErrSenderRateLimited)) {
+			check.OK = false
+			check.Err = err.Error()
+		} else {
+			check.OK = true
+		}
+
+		result[i] = append(result[i], check)
 	}
 
 	return result, nil
@@ -9,6 +9,7 @@ import (
 	"github.com/ipfs/go-datastore"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
@@ -34,6 +35,10 @@ type MpoolConfig struct {
 	ReplaceByFeeRatio      types.Percent
 	PruneCooldown          time.Duration
 	GasLimitOverestimation float64
+	Policy                 types.MpoolPolicy
+	FeeBump                types.MpoolFeeBump
+	// GasPremiumEstimator is the estimator of the gas premiums, like "percentile:75", the median by default
+	GasPremiumEstimator string
 }
 
 func (mc *MpoolConfig) Clone() *MpoolConfig {
@@ -57,8 +62,18 @@ func loadConfig(ctx context.Context, ds repo.Datastore) (*MpoolConfig, error) {
 		return nil, err
 	}
 	cfg := new(MpoolConfig)
-	err = json.Unmarshal(cfgBytes, cfg)
-	return cfg, err
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, err
+	}
+	normalizeConfig(cfg)
+	return cfg, nil
+}
+
+// normalizeConfig sets the amounts missing from cfg to zero, like in a config saved by an older version.
+func normalizeConfig(cfg *MpoolConfig) {
+	if cfg.FeeBump.MaxFee.Int == nil {
+		cfg.FeeBump.MaxFee = big.Zero()
+	}
 }
 
 func saveConfig(ctx context.Context, cfg *MpoolConfig, ds repo.Datastore) error {
@@ -83,7 +98,16 @@ func validateConfg(cfg *MpoolConfig) error {
 	if cfg.GasLimitOverestimation < 1 {
 		return fmt.Errorf("'GasLimitOverestimation' cannot be less than 1")
 	}
-	return nil
+	if cfg.FeeBump.StuckEpochs < 0 {
+		return fmt.Errorf("'FeeBump.StuckEpochs' cannot be negative")
+	}
+	if cfg.FeeBump.MaxFee.Int != nil && cfg.FeeBump.MaxFee.Sign() < 0 {
+		return fmt.Errorf("'FeeBump.MaxFee' cannot be negative")
+	}
+	if _, err := NewGasPremiumEstimator(cfg.GasPremiumEstimator); err != nil {
+		return fmt.Errorf("'GasPremiumEstimator' is invalid: %w", err)
+	}
+	return validatePolicy(&cfg.Policy)
 }
 
 func (mp *MessagePool) SetConfig(ctx context.Context, cfg *MpoolConfig) error {
@@ -91,9 +115,11 @@ func (mp *MessagePool) SetConfig(ctx context.Context, cfg *MpoolConfig) error {
 		return err
 	}
 	cfg = cfg.Clone()
+	normalizeConfig(cfg)
 
 	mp.cfgLk.Lock()
 	mp.cfg = cfg
+	mp.policy.reset()
 	err := saveConfig(ctx, cfg, mp.ds)
 	if err != nil {
 		log.Warnf("error persisting mpool config: %s", err)
@@ -110,5 +136,6 @@ func DefaultConfig() *MpoolConfig {
 		ReplaceByFeeRatio:      ReplaceByFeePercentageDefault,
 		PruneCooldown:          PruneCooldownDefault,
 		GasLimitOverestimation: GasLimitOverestimation,
+		FeeBump:                types.MpoolFeeBump{MaxFee: big.Zero()},
 	}
 }
@@ -122,6 +122,8 @@ func medianGasPremium(prices []GasMeta, blocks int) abi.TokenAmount {
 	return premium
 }
 
+// GasEstimateGasPremium estimates the premium to be included within nblocksincl epochs with the gas
+// premium estimator of the config.
 func (mp *MessagePool) GasEstimateGasPremium(
 	ctx context.Context,
 	nblocksincl uint64,
@@ -130,58 +132,73 @@ func (mp *MessagePool) GasEstimateGasPremium(
 	_ types.TipSetKey,
 	cache *GasPriceCache,
 ) (big.Int, error) {
+	return mp.estimateGasPremium(ctx, nblocksincl, cache, mp.GetConfig().GasPremiumEstimator)
+}
+
+// estimateGasPremium estimates the premium to be included within nblocksincl epochs with the gas premium
+// estimator named estimator.
+func (mp *MessagePool) estimateGasPremium(ctx context.Context, nblocksincl uint64, cache *GasPriceCache, estimator string) (big.Int, error) {
 	if nblocksincl == 0 {
 		nblocksincl = 1
 	}
 
-	var prices []GasMeta
-	var blocks int
+	est, err := NewGasPremiumEstimator(estimator)
+	if err != nil {
+		return big.Int{}, err
+	}
 
 	ts, err := mp.api.ChainHead(ctx)
 	if err != nil {
 		return big.Int{}, err
 	}
 
-	for i := uint64(0); i < nblocksincl*2; i++ {
+	window, err := mp.gasStatsWindow(ctx, cache, ts, est.Window(nblocksincl))
+	if err != nil {
+		return big.Int{}, err
+	}
+
+	premium := floorGasPremium(est.Estimate(nblocksincl, window), nblocksincl)
+
+	// add some noise to normalize behaviour of message selection
+	const precision = 32
+	// mean 1, stddev 0.005 => 95% within +-1%
+	noise := 1 + rand.NormFloat64()*0.005
+	premium = types.BigMul(premium, types.NewInt(uint64(noise*(1<<precision))+1))
+	premium = types.BigDiv(premium, types.NewInt(1<<precision))
+	return premium, nil
+}
+
+// specGasPremiumEstimator returns the gas premium estimator of spec, or of the config if spec sets none.
+func (mp *MessagePool) specGasPremiumEstimator(spec *types.MessageSendSpec) string {
+	if spec != nil && spec.GasPremiumEstimator != "" {
+		return spec.GasPremiumEstimator
+	}
+	return mp.GetConfig().GasPremiumEstimator
+}
+
+// gasStatsWindow returns the gas stats of the n parents of ts, the most recent first.
+func (mp *MessagePool) gasStatsWindow(ctx context.Context, cache *GasPriceCache, ts *types.TipSet, n int) ([]TipSetGasStats, error) {
+	window := make([]TipSetGasStats, 0, n)
+	for i := 0; i < n; i++ {
 		if ts.Height() == 0 {
 			break // genesis
 		}
 
 		pts, err := mp.api.LoadTipSet(ctx, ts.Parents())
 		if err != nil {
-			return types.BigInt{}, err
+			return nil, err
 		}
 
-		blocks += len(pts.Blocks())
 		meta, err := cache.GetTSGasStats(ctx, mp.api, pts)
 		if err != nil {
-			return types.BigInt{}, err
+			return nil, err
 		}
-		prices = append(prices, meta...)
+		window = append(window, TipSetGasStats{Height: pts.Height(), Blocks: len(pts.Blocks()), Prices: meta})
 
 		ts = pts
 	}
 
-	premium := medianGasPremium(prices, blocks)
-
-	if big.Cmp(premium, big.NewInt(MinGasPremium)) < 0 {
-		switch nblocksincl {
-		case 1:
-			premium = big.NewInt(2 * MinGasPremium)
-		case 2:
-			premium = big.NewInt(1.5 * MinGasPremium)
-		default:
-			premium = big.NewInt(MinGasPremium)
-		}
-	}
-
-	// add some noise to normalize behaviour of message selection
-	const precision = 32
-	// mean 1, stddev 0.005 => 95% within +-1%
-	noise := 1 + rand.NormFloat64()*0.005
-	premium = types.BigMul(premium, types.NewInt(uint64(noise*(1<<precision))+1))
-	premium = types.BigDiv(premium, types.NewInt(1<<precision))
-	return premium, nil
+	return window, nil
 }
 
 func (mp *MessagePool) GasEstimateGasLimit(ctx context.Context, msgIn *types.Message, tsk types.TipSetKey) (int64, error) {
@@ -375,7 +392,7 @@ func (mp *MessagePool) GasEstimateMessageGas(ctx context.Context, estimateMessag
 	}
 
 	if estimateMessage.Msg.GasPremium == types.EmptyInt || types.BigCmp(estimateMessage.Msg.GasPremium, types.NewInt(0)) == 0 {
-		gasPremium, err := mp.GasEstimateGasPremium(ctx, 10, estimateMessage.Msg.From, estimateMessage.Msg.GasLimit, types.TipSetKey{}, mp.PriceCache)
+		gasPremium, err := mp.estimateGasPremium(ctx, 10, mp.PriceCache, mp.specGasPremiumEstimator(estimateMessage.Spec))
 		if err != nil {
 			return nil, fmt.Errorf("estimating gas price: %w", err)
 		}
@@ -444,7 +461,7 @@ func (mp *MessagePool) GasBatchEstimateMessageGas(ctx context.Context, estimateM
 		}
 
 		if estimateMsg.GasPremium == types.EmptyInt || types.BigCmp(estimateMsg.GasPremium, types.NewInt(0)) == 0 {
-			gasPremium, err := mp.GasEstimateGasPremium(ctx, 10, estimateMsg.From, estimateMsg.GasLimit, types.TipSetKey{}, mp.PriceCache)
+			gasPremium, err := mp.estimateGasPremium(ctx, 10, mp.PriceCache, mp.specGasPremiumEstimator(estimateMessage.Spec))
 			if err != nil {
 				estimateMsg.Nonce = 0
 				estimateResults = append(estimateResults, &types.EstimateResult{
@@ -0,0 +1,135 @@
+package messagepool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/venus/venus-shared/actors/policy"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// backtestWaitFactor bounds the epochs an estimated premium waits for its inclusion in a backtest to
+// backtestWaitFactor times the targeted epochs.
+const backtestWaitFactor = 10
+
+// The backtests load the gas stats of every replayed tipset, their length is capped to a few finalities.
+const (
+	maxBacktestEpochs      = int(3 * policy.ChainFinality)
+	maxBacktestNBlocksIncl = uint64(policy.ChainFinality / backtestWaitFactor)
+)
+
+// BacktestGasPremium replays the last epochs tipsets before ts: on each of them, it estimates the premium
+// to be included within nblocksincl epochs with each of the estimators, and checks how many epochs the
+// premium would have waited for a tipset including a premium as low, and how much it would have overpaid.
+// All the gas premium estimators are replayed if estimators is empty. epochs is capped to three finalities,
+// and nblocksincl to a tenth of a finality.
+func (mp *MessagePool) BacktestGasPremium(ctx context.Context, ts *types.TipSet, estimators []string, nblocksincl uint64, epochs int) ([]*types.GasBacktestResult, error) {
+	if nblocksincl == 0 {
+		nblocksincl = 1
+	}
+	if epochs <= 0 {
+		return nil, fmt.Errorf("the number of epochs to replay must be positive")
+	}
+	if epochs > maxBacktestEpochs {
+		return nil, fmt.Errorf("the number of epochs to replay %d exceeds the maximum of %d", epochs, maxBacktestEpochs)
+	}
+	if nblocksincl > maxBacktestNBlocksIncl {
+		return nil, fmt.Errorf("the targeted inclusion epochs %d exceed the maximum of %d", nblocksincl, maxBacktestNBlocksIncl)
+	}
+	if len(estimators) == 0 {
+		estimators = GasPremiumEstimators
+	}
+
+	ests := make([]GasPremiumEstimator, len(estimators))
+	window := 0
+	for i, name := range estimators {
+		est, err := NewGasPremiumEstimator(name)
+		if err != nil {
+			return nil, err
+		}
+		ests[i] = est
+		if w := est.Window(nblocksincl); w > window {
+			window = w
+		}
+	}
+
+	maxWait := abi.ChainEpoch(backtestWaitFactor * nblocksincl)
+	// a fresh cache, the replayed tipsets would evict the recent ones from the price cache
+	history, err := mp.gasStatsWindow(ctx, NewGasPriceCache(), ts, int(maxWait)+epochs+window)
+	if err != nil {
+		return nil, fmt.Errorf("loading the gas stats of the replayed tipsets: %w", err)
+	}
+
+	res := make([]*types.GasBacktestResult, len(ests))
+	for i, est := range ests {
+		res[i] = backtestGasPremium(history, est, nblocksincl, epochs, maxWait)
+		res[i].Estimator = estimators[i]
+	}
+
+	return res, nil
+}
+
+// backtestGasPremium replays the estimator on the epochs tipsets of history, the most recent first, which
+// are followed by maxWait epochs of tipsets.
+func backtestGasPremium(history []TipSetGasStats, est GasPremiumEstimator, nblocksincl uint64, epochs int, maxWait abi.ChainEpoch) *types.GasBacktestResult {
+	res := &types.GasBacktestResult{
+		MaxWait:     maxWait,
+		MeanPremium: big.Zero(),
+		MeanOverpay: big.Zero(),
+	}
+	if len(history) == 0 {
+		return res
+	}
+
+	lowest := make([]abi.TokenAmount, len(history))
+	for i, ts := range history {
+		lowest[i] = lowestGasPremium(ts.Prices)
+	}
+
+	w := est.Window(nblocksincl)
+	premiums, overpaid := big.Zero(), big.Zero()
+	var latency abi.ChainEpoch
+	for i := 0; i < len(history) && res.Samples < epochs; i++ {
+		// only the tipsets followed by maxWait epochs are replayed
+		if history[0].Height-history[i].Height < maxWait {
+			continue
+		}
+
+		end := i + w
+		if end > len(history) {
+			end = len(history)
+		}
+		premium := floorGasPremium(est.Estimate(nblocksincl, history[i:end]), nblocksincl)
+		res.Samples++
+		premiums = big.Add(premiums, premium)
+
+		for j := i - 1; j >= 0; j-- {
+			wait := history[j].Height - history[i].Height
+			if wait > maxWait {
+				break
+			}
+			if lowest[j].LessThanEqual(premium) {
+				res.Included++
+				if wait <= abi.ChainEpoch(nblocksincl) {
+					res.InTarget++
+				}
+				latency += wait
+				overpaid = big.Add(overpaid, big.Sub(premium, lowest[j]))
+				break
+			}
+		}
+	}
+
+	if res.Samples > 0 {
+		res.MeanPremium = big.Div(premiums, big.NewInt(int64(res.Samples)))
+	}
+	if res.Included > 0 {
+		res.MeanLatency = float64(latency) / float64(res.Included)
+		res.MeanOverpay = big.Div(overpaid, big.NewInt(int64(res.Included)))
+	}
+
+	return res
+}
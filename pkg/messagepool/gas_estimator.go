@@ -0,0 +1,202 @@
+package messagepool
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+// the names of the gas premium estimators
+const (
+	// GasEstimatorMedian pays the premium at the 55th percentile of the gas target of the last
+	// 2*nblocksincl tipsets, the default
+	GasEstimatorMedian = "median"
+	// GasEstimatorPercentile pays the premium at a percentile of the gas of the recent inclusions,
+	// like "percentile:75", the 50th by default
+	GasEstimatorPercentile = "percentile"
+	// GasEstimatorInclusion pays the lowest premium included within nblocksincl epochs with a
+	// probability, like "inclusion:0.95", 0.9 by default
+	GasEstimatorInclusion = "inclusion"
+)
+
+// GasPremiumEstimators are the names of the gas premium estimators.
+var GasPremiumEstimators = []string{GasEstimatorMedian, GasEstimatorPercentile, GasEstimatorInclusion}
+
+const (
+	defaultPremiumPercentile    = 50
+	defaultInclusionProbability = 0.9
+	// recentInclusionsWindow is the minimal number of tipsets the premiums of the recent inclusions
+	// are taken from
+	recentInclusionsWindow = 20
+)
+
+// GasPremiumEstimator estimates the premium a message pays to be included within nblocksincl epochs,
+// from the gas stats of the recent tipsets.
+type GasPremiumEstimator interface {
+	// Window returns the number of recent tipsets an estimate for nblocksincl is based on.
+	Window(nblocksincl uint64) int
+	// Estimate estimates the premium from the gas stats of the window, the most recent tipset first.
+	Estimate(nblocksincl uint64, window []TipSetGasStats) abi.TokenAmount
+}
+
+// TipSetGasStats are the gas stats of the messages of a tipset.
+type TipSetGasStats struct {
+	Height abi.ChainEpoch
+	Blocks int
+	Prices []GasMeta
+}
+
+// NewGasPremiumEstimator returns the estimator named name, with its parameter after a colon, like
+// "percentile:75". The median estimator is returned for an empty name.
+func NewGasPremiumEstimator(name string) (GasPremiumEstimator, error) {
+	kind, param, hasParam := strings.Cut(name, ":")
+	switch kind {
+	case "", GasEstimatorMedian:
+		if hasParam {
+			return nil, fmt.Errorf("gas premium estimator %q takes no parameter", kind)
+		}
+		return medianEstimator{}, nil
+	case GasEstimatorPercentile:
+		p := float64(defaultPremiumPercentile)
+		if hasParam {
+			var err error
+			if p, err = strconv.ParseFloat(param, 64); err != nil || p <= 0 || p > 100 {
+				return nil, fmt.Errorf("invalid percentile %q, expected a number in (0, 100]", param)
+			}
+		}
+		return percentileEstimator{percentile: p}, nil
+	case GasEstimatorInclusion:
+		q := defaultInclusionProbability
+		if hasParam {
+			var err error
+			if q, err = strconv.ParseFloat(param, 64); err != nil || q <= 0 || q >= 1 {
+				return nil, fmt.Errorf("invalid inclusion probability %q, expected a number in (0, 1)", param)
+			}
+		}
+		return inclusionEstimator{probability: q}, nil
+	}
+	return nil, fmt.Errorf("unknown gas premium estimator %q, expected one of %v", name, GasPremiumEstimators)
+}
+
+type medianEstimator struct{}
+
+func (medianEstimator) Window(nblocksincl uint64) int {
+	return int(nblocksincl * 2)
+}
+
+func (medianEstimator) Estimate(_ uint64, window []TipSetGasStats) abi.TokenAmount {
+	var prices []GasMeta
+	var blocks int
+	for _, ts := range window {
+		blocks += ts.Blocks
+		prices = append(prices, ts.Prices...)
+	}
+	return medianGasPremium(prices, blocks)
+}
+
+// percentileEstimator pays the premium at a percentile of the premiums of the recent inclusions,
+// weighted by their gas limit.
+type percentileEstimator struct {
+	percentile float64
+}
+
+func (e percentileEstimator) Window(nblocksincl uint64) int {
+	return recentInclusionsWindow + int(nblocksincl*2)
+}
+
+func (e percentileEstimator) Estimate(_ uint64, window []TipSetGasStats) abi.TokenAmount {
+	var prices []GasMeta
+	var gas int64
+	for _, ts := range window {
+		for _, p := range ts.Prices {
+			prices = append(prices, p)
+			gas += p.Limit
+		}
+	}
+	if len(prices) == 0 {
+		return big.Zero()
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Price.LessThan(prices[j].Price)
+	})
+
+	at := int64(math.Ceil(float64(gas) * e.percentile / 100))
+	for _, p := range prices {
+		at -= p.Limit
+		if at <= 0 {
+			return p.Price
+		}
+	}
+	return prices[len(prices)-1].Price
+}
+
+// inclusionEstimator pays the lowest premium which would have been included within nblocksincl epochs
+// with a probability. A premium is included in an epoch with the probability that the epoch has a block
+// times the share of the recent tipsets which included a premium as low.
+type inclusionEstimator struct {
+	probability float64
+}
+
+func (e inclusionEstimator) Window(nblocksincl uint64) int {
+	return recentInclusionsWindow + int(nblocksincl*2)
+}
+
+func (e inclusionEstimator) Estimate(nblocksincl uint64, window []TipSetGasStats) abi.TokenAmount {
+	lowest := make([]abi.TokenAmount, 0, len(window))
+	for _, ts := range window {
+		lowest = append(lowest, lowestGasPremium(ts.Prices))
+	}
+	if len(lowest) == 0 {
+		return big.Zero()
+	}
+	sort.Slice(lowest, func(i, j int) bool {
+		return lowest[i].LessThan(lowest[j])
+	})
+
+	if nblocksincl == 0 {
+		nblocksincl = 1
+	}
+	withBlock := 1 - noWinnersProb()[0]
+	for i, premium := range lowest {
+		inEpoch := withBlock * float64(i+1) / float64(len(lowest))
+		if 1-math.Pow(1-inEpoch, float64(nblocksincl)) >= e.probability {
+			return premium
+		}
+	}
+	return lowest[len(lowest)-1]
+}
+
+// lowestGasPremium returns the lowest premium included in a tipset, zero for a tipset without message
+// as any premium would have been included.
+func lowestGasPremium(prices []GasMeta) abi.TokenAmount {
+	if len(prices) == 0 {
+		return big.Zero()
+	}
+	lowest := prices[0].Price
+	for _, p := range prices[1:] {
+		if p.Price.LessThan(lowest) {
+			lowest = p.Price
+		}
+	}
+	return lowest
+}
+
+// floorGasPremium raises premium to MinGasPremium, more for a quicker inclusion.
+func floorGasPremium(premium abi.TokenAmount, nblocksincl uint64) abi.TokenAmount {
+	if big.Cmp(premium, big.NewInt(MinGasPremium)) < 0 {
+		switch nblocksincl {
+		case 1:
+			premium = big.NewInt(2 * MinGasPremium)
+		case 2:
+			premium = big.NewInt(1.5 * MinGasPremium)
+		default:
+			premium = big.NewInt(MinGasPremium)
+		}
+	}
+	return premium
+}
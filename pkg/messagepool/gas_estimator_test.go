@@ -0,0 +1,170 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestNewGasPremiumEstimator(t *testing.T) {
+	tf.UnitTest(t)
+
+	for name, expected := range map[string]GasPremiumEstimator{
+		"":                 medianEstimator{},
+		"median":           medianEstimator{},
+		"percentile":       percentileEstimator{percentile: 50},
+		"percentile:75":    percentileEstimator{percentile: 75},
+		"inclusion":        inclusionEstimator{probability: 0.9},
+		"inclusion:0.95":   inclusionEstimator{probability: 0.95},
+		"percentile:100.0": percentileEstimator{percentile: 100},
+	} {
+		est, err := NewGasPremiumEstimator(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, est, name)
+	}
+
+	for _, name := range []string{"mean", "median:5", "percentile:0", "percentile:101", "percentile:x", "inclusion:1", "inclusion:-0.5"} {
+		_, err := NewGasPremiumEstimator(name)
+		assert.Error(t, err, name)
+	}
+
+	ctx := context.Background()
+	tma := newTestMpoolAPI()
+	_, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+
+	cfg := mp.GetConfig()
+	cfg.GasPremiumEstimator = "percentile:200"
+	assert.Error(t, mp.SetConfig(ctx, cfg))
+	cfg.GasPremiumEstimator = "inclusion:0.8"
+	require.NoError(t, mp.SetConfig(ctx, cfg))
+	loaded, err := loadConfig(ctx, mp.ds)
+	require.NoError(t, err)
+	assert.Equal(t, "inclusion:0.8", loaded.GasPremiumEstimator)
+}
+
+func gasStats(height abi.ChainEpoch, premiums ...int64) TipSetGasStats {
+	stats := TipSetGasStats{Height: height, Blocks: 1}
+	for _, p := range premiums {
+		stats.Prices = append(stats.Prices, GasMeta{Price: abi.NewTokenAmount(p), Limit: 10})
+	}
+	return stats
+}
+
+func TestGasPremiumEstimators(t *testing.T) {
+	tf.UnitTest(t)
+
+	median := medianEstimator{}
+	assert.Equal(t, 20, median.Window(10))
+	assert.Equal(t, abi.NewTokenAmount(100), median.Estimate(10, []TipSetGasStats{{
+		Blocks: 1,
+		Prices: []GasMeta{
+			{Price: abi.NewTokenAmount(50), Limit: constants.BlockGasTarget},
+			{Price: abi.NewTokenAmount(100), Limit: constants.BlockGasTarget},
+		},
+	}}))
+
+	// the premiums are weighted by their gas limit
+	window := []TipSetGasStats{gasStats(2, 3, 3), gasStats(1, 1, 2)}
+	for p, expected := range map[float64]int64{25: 1, 50: 2, 75: 3, 100: 3} {
+		est := percentileEstimator{percentile: p}
+		assert.Equal(t, abi.NewTokenAmount(expected), est.Estimate(10, window), p)
+	}
+	assert.Equal(t, abi.NewTokenAmount(0), percentileEstimator{percentile: 50}.Estimate(10, nil))
+
+	// the lowest premiums of the tipsets are 20, 10, 5 and 0 for the empty tipset
+	window = []TipSetGasStats{gasStats(4, 30, 20), gasStats(3, 10), gasStats(2, 5, 50), gasStats(1)}
+	for _, c := range []struct {
+		probability float64
+		nblocksincl uint64
+		expected    int64
+	}{
+		{0.9, 1, 20},
+		{0.9, 3, 10},
+		{0.5, 3, 0},
+		{0.99, 10, 5},
+		{0.999, 1, 20},
+	} {
+		est := inclusionEstimator{probability: c.probability}
+		assert.Equal(t, abi.NewTokenAmount(c.expected), est.Estimate(c.nblocksincl, window), c)
+	}
+}
+
+type fixedEstimator int64
+
+func (fixedEstimator) Window(uint64) int { return 1 }
+
+func (e fixedEstimator) Estimate(uint64, []TipSetGasStats) abi.TokenAmount {
+	return abi.NewTokenAmount(int64(e))
+}
+
+func TestBacktestGasPremium(t *testing.T) {
+	tf.UnitTest(t)
+
+	// the tipsets from 30 down to 1 include premiums from 2e6, but for 500e3 at 22 and the empty tipset at 25
+	var history []TipSetGasStats
+	for h := abi.ChainEpoch(30); h > 0; h-- {
+		switch h {
+		case 25:
+			history = append(history, gasStats(h))
+		case 22:
+			history = append(history, gasStats(h, 500e3, 3e6))
+		default:
+			history = append(history, gasStats(h, 2e6, 3e6))
+		}
+	}
+
+	// the tipsets from 20 to 11 are followed by 10 epochs, the ones up to 12 include the premium at 22
+	res := backtestGasPremium(history, fixedEstimator(1e6), 1, 10, 10)
+	assert.Equal(t, 10, res.Samples)
+	assert.Equal(t, 9, res.Included)
+	assert.Equal(t, 0, res.InTarget)
+	assert.Equal(t, abi.ChainEpoch(10), res.MaxWait)
+	assert.Equal(t, float64(2+3+4+5+6+7+8+9+10)/9, res.MeanLatency)
+	assert.Equal(t, abi.NewTokenAmount(1e6), res.MeanPremium)
+	assert.Equal(t, abi.NewTokenAmount(500e3), res.MeanOverpay)
+
+	// the next tipset includes the premium
+	res = backtestGasPremium(history, fixedEstimator(3e6), 1, 10, 10)
+	assert.Equal(t, 10, res.Samples)
+	assert.Equal(t, 10, res.Included)
+	assert.Equal(t, 10, res.InTarget)
+	assert.Equal(t, float64(1), res.MeanLatency)
+	assert.Equal(t, abi.NewTokenAmount(1e6), res.MeanOverpay)
+
+	// the premium is floored
+	res = backtestGasPremium(history, fixedEstimator(1), 1, 10, 10)
+	assert.Equal(t, abi.NewTokenAmount(2*MinGasPremium), res.MeanPremium)
+
+	res = backtestGasPremium(nil, fixedEstimator(1), 1, 10, 10)
+	assert.Equal(t, 0, res.Samples)
+	assert.Equal(t, abi.NewTokenAmount(0), res.MeanPremium)
+}
+
+func TestBacktestGasPremiumLimits(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	tma := newTestMpoolAPI()
+	_, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+	ts := tma.tipsets[len(tma.tipsets)-1]
+
+	_, err := mp.BacktestGasPremium(ctx, ts, nil, 1, maxBacktestEpochs+1)
+	assert.Error(t, err)
+	_, err = mp.BacktestGasPremium(ctx, ts, nil, maxBacktestNBlocksIncl+1, 10)
+	assert.Error(t, err)
+
+	// the genesis has no history to replay
+	res, err := mp.BacktestGasPremium(ctx, ts, nil, maxBacktestNBlocksIncl, maxBacktestEpochs)
+	require.NoError(t, err)
+	require.Len(t, res, len(GasPremiumEstimators))
+	assert.Equal(t, 0, res[0].Samples)
+}
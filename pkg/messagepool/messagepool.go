@@ -106,6 +106,7 @@ const (
 	evtTypeMpoolAdd = iota
 	evtTypeMpoolRemove
 	evtTypeMpoolRepub
+	evtTypeMpoolBump
 )
 
 // MessagePoolEvt is the journal entry for message pool events.
@@ -149,6 +150,8 @@ type MessagePool struct {
 
 	cfgLk sync.Mutex
 	cfg   *MpoolConfig
+	// policy holds the admission rate state of the senders for cfg.Policy
+	policy *senderPolicy
 
 	api Provider
 
@@ -172,7 +175,7 @@ type MessagePool struct {
 
 	sigValCache *lru.TwoQueueCache[string, struct{}]
 
-	evtTypes [3]journal.EventType
+	evtTypes [4]journal.EventType
 	journal  journal.Journal
 
 	forkParams       *config.ForkUpgradeConfig
@@ -422,10 +425,12 @@ func New(ctx context.Context,
 		sm:            sm,
 		netName:       netName,
 		cfg:           cfg,
+		policy:        newSenderPolicy(),
 		evtTypes: [...]journal.EventType{
 			evtTypeMpoolAdd:    j.RegisterEventType("mpool", "add"),
 			evtTypeMpoolRemove: j.RegisterEventType("mpool", "remove"),
 			evtTypeMpoolRepub:  j.RegisterEventType("mpool", "repub"),
+			evtTypeMpoolBump:   j.RegisterEventType("mpool", "bump"),
 		},
 		journal:          j,
 		forkParams:       networkParams.ForkUpgradeParam,
@@ -900,6 +905,10 @@ func (mp *MessagePool) checkBalance(ctx context.Context, m *types.SignedMessage,
 }
 
 func (mp *MessagePool) addTS(ctx context.Context, m *types.SignedMessage, curTS *types.TipSet, local, untrusted bool) (bool, error) {
+	if err := mp.checkPolicy(ctx, &m.Message, curTS, true); err != nil {
+		return false, err
+	}
+
 	snonce, err := mp.getStateNonce(ctx, m.Message.From, curTS)
 	if err != nil {
 		return false, fmt.Errorf("failed to look up actor state nonce: %s: %w", err, ErrSoftValidationFailure)
@@ -91,6 +91,26 @@ func (ms *MessageSigner) SignMessage(ctx context.Context, msg *types.Message, cb
 	return smsg, nil
 }
 
+// NextNonce returns the nonce the next message signed for addr is assigned.
+func (ms *MessageSigner) NextNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	ms.lk.Lock()
+	defer ms.lk.Unlock()
+
+	return ms.nextNonce(ctx, addr)
+}
+
+// ResetNonce forgets the nonce tracked for addr, the nonce of the next message is the one of the
+// message pool again.
+func (ms *MessageSigner) ResetNonce(ctx context.Context, addr address.Address) error {
+	ms.lk.Lock()
+	defer ms.lk.Unlock()
+
+	if err := ms.ds.Delete(ctx, ms.dstoreKey(addr)); err != nil {
+		return fmt.Errorf("failed to delete nonce from datastore: %w", err)
+	}
+	return nil
+}
+
 // nextNonce gets the next nonce for the given address.
 // If there is no nonce in the datastore, gets the nonce from the message pool.
 func (ms *MessageSigner) nextNonce(ctx context.Context, addr address.Address) (uint64, error) {
@@ -0,0 +1,208 @@
+package messagepool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	builtin2 "github.com/filecoin-project/go-state-types/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// maxReportedNonceGaps bounds the gaps listed by a report, a signer nonce far ahead of the pool would
+// otherwise list an unbounded number of gaps.
+const maxReportedNonceGaps = 1000
+
+// NonceGaps compares the nonces of addr in the state, the pending messages and the local messages.
+// signerNonce is the nonce the signer of the local messages assigns next, the nonces missing below it
+// are gaps too.
+func (mp *MessagePool) NonceGaps(ctx context.Context, addr address.Address, signerNonce uint64) (*types.NonceGapReport, error) {
+	mp.curTSLk.Lock()
+	defer mp.curTSLk.Unlock()
+
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	return mp.nonceGapsLocked(ctx, addr, signerNonce, mp.curTS)
+}
+
+// FillNonceGaps pushes a zero value self-send of addr for each of the nonces, priced by the gas estimation
+// and signed by signer, and returns the pushed messages.
+func (mp *MessagePool) FillNonceGaps(ctx context.Context, addr address.Address, nonces []uint64, signer WalletSigner) ([]*types.SignedMessage, error) {
+	filled := make([]*types.SignedMessage, 0, len(nonces))
+	for _, nonce := range nonces {
+		msg, err := mp.GasEstimateMessageGas(ctx, &types.EstimateMessage{
+			Msg: &types.Message{
+				From:   addr,
+				To:     addr,
+				Value:  big.Zero(),
+				Method: builtin2.MethodSend,
+				Nonce:  nonce,
+			},
+		}, types.EmptyTSK)
+		if err != nil {
+			return filled, fmt.Errorf("estimating the gas of the filler with nonce %d: %w", nonce, err)
+		}
+
+		smsg, err := signMessage(ctx, signer, msg)
+		if err != nil {
+			return filled, err
+		}
+		if _, err := mp.Push(ctx, smsg); err != nil {
+			return filled, fmt.Errorf("failed to push the filler with nonce %d: %w", nonce, err)
+		}
+		log.Infow("filled nonce gap", "from", addr, "nonce", nonce, "cid", smsg.Cid())
+		filled = append(filled, smsg)
+	}
+
+	return filled, nil
+}
+
+// DropNonceOrphans removes the pending messages of addr stranded behind a gap, and deletes them and the
+// stale messages of addr from the local messages. It returns the dropped messages.
+func (mp *MessagePool) DropNonceOrphans(ctx context.Context, addr address.Address) ([]cid.Cid, error) {
+	mp.curTSLk.Lock()
+	defer mp.curTSLk.Unlock()
+
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	report, err := mp.nonceGapsLocked(ctx, addr, 0, mp.curTS)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nonce := range report.Pending {
+		if nonce > report.PoolNonce {
+			mp.remove(ctx, report.Address, nonce, false)
+		}
+	}
+
+	var dropped []cid.Cid
+	for _, cids := range [][]cid.Cid{report.Stranded, report.Stale} {
+		for _, c := range cids {
+			if err := mp.localMsgs.Delete(ctx, datastore.NewKey(string(c.Bytes()))); err != nil {
+				return dropped, fmt.Errorf("deleting local message %s: %w", c, err)
+			}
+			dropped = append(dropped, c)
+		}
+	}
+	log.Infow("dropped nonce orphans", "from", report.Address, "stranded", len(report.Stranded), "stale", len(report.Stale))
+
+	return dropped, nil
+}
+
+// nonceGapsLocked builds the nonce gap report of addr.
+func (mp *MessagePool) nonceGapsLocked(ctx context.Context, addr address.Address, signerNonce uint64, curTS *types.TipSet) (*types.NonceGapReport, error) {
+	ka, err := mp.resolveToKey(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolving key address of %s: %w", addr, err)
+	}
+	stateNonce, err := mp.getStateNonce(ctx, ka, curTS)
+	if err != nil {
+		return nil, fmt.Errorf("getting state nonce of %s: %w", addr, err)
+	}
+
+	report := &types.NonceGapReport{
+		Address:     ka,
+		StateNonce:  stateNonce,
+		SignerNonce: signerNonce,
+		Pending:     []uint64{},
+		Local:       []uint64{},
+		Gaps:        []uint64{},
+		Stranded:    []cid.Cid{},
+		Stale:       []cid.Cid{},
+	}
+
+	pending := make(map[uint64]*types.SignedMessage)
+	mset, ok, err := mp.getPendingMset(ctx, ka)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for nonce, m := range mset.msgs {
+			pending[nonce] = m
+			report.Pending = append(report.Pending, nonce)
+		}
+	}
+	sort.Slice(report.Pending, func(i, j int) bool { return report.Pending[i] < report.Pending[j] })
+
+	report.PoolNonce = stateNonce
+	for _, has := pending[report.PoolNonce]; has; _, has = pending[report.PoolNonce] {
+		report.PoolNonce++
+	}
+
+	end := signerNonce
+	if l := len(report.Pending); l > 0 && report.Pending[l-1]+1 > end {
+		end = report.Pending[l-1] + 1
+	}
+	for nonce := report.PoolNonce; nonce < end && len(report.Gaps) < maxReportedNonceGaps; nonce++ {
+		if _, has := pending[nonce]; !has {
+			report.Gaps = append(report.Gaps, nonce)
+		}
+	}
+	for _, nonce := range report.Pending {
+		if nonce > report.PoolNonce {
+			report.Stranded = append(report.Stranded, pending[nonce].Cid())
+		}
+	}
+
+	locals, err := mp.localMsgsFor(ctx, ka)
+	if err != nil {
+		return nil, err
+	}
+	stranded := make(map[cid.Cid]struct{}, len(report.Stranded))
+	for _, c := range report.Stranded {
+		stranded[c] = struct{}{}
+	}
+	for _, m := range locals {
+		nonce := m.Message.Nonce
+		report.Local = append(report.Local, nonce)
+
+		c := m.Cid()
+		if _, ok := stranded[c]; ok {
+			continue
+		}
+		if p, has := pending[nonce]; nonce < stateNonce || (has && p.Cid() != c) {
+			report.Stale = append(report.Stale, c)
+		}
+	}
+	sort.Slice(report.Local, func(i, j int) bool { return report.Local[i] < report.Local[j] })
+
+	return report, nil
+}
+
+// localMsgsFor returns the local messages persisted for the key address ka.
+func (mp *MessagePool) localMsgsFor(ctx context.Context, ka address.Address) ([]*types.SignedMessage, error) {
+	res, err := mp.localMsgs.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("query local messages: %v", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []*types.SignedMessage
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, fmt.Errorf("r.Error: %v", r.Error)
+		}
+
+		var sm types.SignedMessage
+		if err := sm.UnmarshalCBOR(bytes.NewReader(r.Value)); err != nil {
+			return nil, fmt.Errorf("unmarshaling local message: %v", err)
+		}
+		from, err := mp.resolveToKey(ctx, sm.Message.From)
+		if err != nil || from != ka {
+			continue
+		}
+		out = append(out, &sm)
+	}
+
+	return out, nil
+}
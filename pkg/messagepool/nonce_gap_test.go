@@ -0,0 +1,80 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestNonceGaps(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	tma := newTestMpoolAPI()
+	w, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+
+	sender, err := w.NewAddress(ctx, address.SECP256K1)
+	require.NoError(t, err)
+	tma.setStateNonce(sender, 0)
+	target := mkAddress(1001)
+
+	msgs := make(map[uint64]*types.SignedMessage)
+	for _, nonce := range []uint64{0, 1, 3, 4} {
+		msgs[nonce] = mkMessage(sender, target, nonce, w)
+		_, err := mp.Push(ctx, msgs[nonce])
+		require.NoError(t, err)
+	}
+
+	// the replaced message is left in the local messages
+	rbf := msgs[1].Message
+	rbf.GasPremium = abi.NewTokenAmount(2)
+	sig, err := w.WalletSign(ctx, sender, rbf.Cid().Bytes(), types.MsgMeta{})
+	require.NoError(t, err)
+	replacement := &types.SignedMessage{Message: rbf, Signature: *sig}
+	_, err = mp.Push(ctx, replacement)
+	require.NoError(t, err)
+
+	// the signer is ahead of the pool
+	report, err := mp.NonceGaps(ctx, sender, 7)
+	require.NoError(t, err)
+	assert.Equal(t, sender, report.Address)
+	assert.Equal(t, uint64(0), report.StateNonce)
+	assert.Equal(t, uint64(2), report.PoolNonce)
+	assert.Equal(t, uint64(7), report.SignerNonce)
+	assert.Equal(t, []uint64{0, 1, 3, 4}, report.Pending)
+	assert.Equal(t, []uint64{0, 1, 1, 3, 4}, report.Local)
+	assert.Equal(t, []uint64{2, 5, 6}, report.Gaps)
+	assert.Equal(t, []cid.Cid{msgs[3].Cid(), msgs[4].Cid()}, report.Stranded)
+	assert.Equal(t, []cid.Cid{msgs[1].Cid()}, report.Stale)
+
+	dropped, err := mp.DropNonceOrphans(ctx, sender)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []cid.Cid{msgs[1].Cid(), msgs[3].Cid(), msgs[4].Cid()}, dropped)
+
+	pending, _ := mp.PendingFor(ctx, sender)
+	require.Len(t, pending, 2)
+	assert.Equal(t, msgs[0].Cid(), pending[0].Cid())
+	assert.Equal(t, replacement.Cid(), pending[1].Cid())
+
+	report, err = mp.NonceGaps(ctx, sender, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), report.PoolNonce)
+	assert.Equal(t, []uint64{0, 1}, report.Local)
+	assert.Empty(t, report.Gaps)
+	assert.Empty(t, report.Stranded)
+	assert.Empty(t, report.Stale)
+
+	nonce, err := mp.GetNonce(ctx, sender, types.EmptyTSK)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), nonce)
+}
@@ -0,0 +1,233 @@
+package messagepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// policyBucketCacheSize is the number of token buckets of the senders kept by the policy, a sender
+// evicted from the cache starts again with a full bucket.
+const policyBucketCacheSize = 10000
+
+var (
+	ErrSenderBlocked       = errors.New("sender is blocked by the mpool policy")
+	ErrSenderNotAllowed    = errors.New("sender isn't allowed by the mpool policy")
+	ErrRecipientBlocked    = errors.New("recipient is blocked by the mpool policy")
+	ErrRecipientNotAllowed = errors.New("recipient isn't allowed by the mpool policy")
+	ErrRejectedByRule      = errors.New("message rejected by a rule of the mpool policy")
+	ErrSenderRateLimited   = errors.New("sender exceeded its admission rate")
+)
+
+// IsPolicyError returns true if err is a rejection of the mpool policy.
+func IsPolicyError(err error) bool {
+	for _, e := range []error{ErrSenderBlocked, ErrSenderNotAllowed, ErrRecipientBlocked, ErrRecipientNotAllowed,
+		ErrRejectedByRule, ErrSenderRateLimited} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// senderPolicy holds the admission rate state of the senders for the MpoolPolicy of the config.
+type senderPolicy struct {
+	lk      sync.Mutex
+	buckets *lru.Cache[string, *tokenBucket]
+}
+
+func newSenderPolicy() *senderPolicy {
+	buckets, _ := lru.New[string, *tokenBucket](policyBucketCacheSize)
+	return &senderPolicy{buckets: buckets}
+}
+
+// reset drops the token buckets, the limits of a reloaded policy start from full buckets.
+func (p *senderPolicy) reset() {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	p.buckets.Purge()
+}
+
+// admit returns whether each of the buckets has a token left, and takes them if consume is set.
+func (p *senderPolicy) admit(limits []bucketLimit, consume bool) bool {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	now := time.Now()
+	buckets := make([]*tokenBucket, len(limits))
+	for i, l := range limits {
+		b, ok := p.buckets.Get(l.key)
+		if !ok {
+			b = &tokenBucket{tokens: float64(l.burst), last: now}
+			if consume {
+				p.buckets.Add(l.key, b)
+			}
+		}
+		b.fill(l.rate, l.burst, now)
+		if b.tokens < 1 {
+			return false
+		}
+		buckets[i] = b
+	}
+	if consume {
+		for _, b := range buckets {
+			b.tokens--
+		}
+	}
+	return true
+}
+
+type bucketLimit struct {
+	key   string
+	rate  float64
+	burst int
+}
+
+// tokenBucket is refilled continuously up to the burst, a message takes a token.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) fill(rate float64, burst int, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+}
+
+func policyEnabled(p *types.MpoolPolicy) bool {
+	return len(p.SenderBlocklist) > 0 || len(p.SenderAllowlist) > 0 ||
+		len(p.RecipientBlocklist) > 0 || len(p.RecipientAllowlist) > 0 ||
+		len(p.Rules) > 0 || p.SenderRate > 0
+}
+
+func validatePolicy(p *types.MpoolPolicy) error {
+	for _, list := range [][]address.Address{p.SenderBlocklist, p.SenderAllowlist, p.RecipientBlocklist, p.RecipientAllowlist} {
+		for _, addr := range list {
+			if addr == address.Undef {
+				return fmt.Errorf("undefined address in the policy lists")
+			}
+		}
+	}
+	if p.SenderRate < 0 {
+		return fmt.Errorf("'SenderRate' cannot be negative")
+	}
+	if p.SenderRate > 0 && p.SenderBurst < 1 {
+		return fmt.Errorf("'SenderBurst' must be at least 1 with a sender rate")
+	}
+	for i, r := range p.Rules {
+		if r.Rate < 0 {
+			return fmt.Errorf("rule %d: 'Rate' cannot be negative", i)
+		}
+		if r.Rate > 0 && r.Burst < 1 {
+			return fmt.Errorf("rule %d: 'Burst' must be at least 1 with a rate", i)
+		}
+	}
+	return nil
+}
+
+// checkPolicy checks a message against the MpoolPolicy of the config. A token of the admission rate of the
+// sender is taken only if consume is set.
+func (mp *MessagePool) checkPolicy(ctx context.Context, m *types.Message, curTS *types.TipSet, consume bool) error {
+	policy := mp.GetConfig().Policy
+	if !policyEnabled(&policy) {
+		return nil
+	}
+
+	senders := []address.Address{m.From}
+	if ka, err := mp.api.StateAccountKeyAtFinality(ctx, m.From, curTS); err == nil && ka != m.From {
+		senders = append(senders, ka)
+	}
+	// the buckets of a sender are shared by its id and key addresses
+	sender := senders[len(senders)-1]
+
+	if containsAny(policy.SenderBlocklist, senders...) {
+		return fmt.Errorf("%s: %w", m.From, ErrSenderBlocked)
+	}
+	if len(policy.SenderAllowlist) > 0 && !containsAny(policy.SenderAllowlist, senders...) {
+		return fmt.Errorf("%s: %w", m.From, ErrSenderNotAllowed)
+	}
+	if containsAny(policy.RecipientBlocklist, m.To) {
+		return fmt.Errorf("%s: %w", m.To, ErrRecipientBlocked)
+	}
+	if len(policy.RecipientAllowlist) > 0 && !containsAny(policy.RecipientAllowlist, m.To) {
+		return fmt.Errorf("%s: %w", m.To, ErrRecipientNotAllowed)
+	}
+
+	var limits []bucketLimit
+	if len(policy.Rules) > 0 {
+		codeName := mp.actorCodeName(ctx, m.To, curTS)
+		for i, r := range policy.Rules {
+			if !ruleMatches(&r, codeName, m) {
+				continue
+			}
+			if r.Reject {
+				return fmt.Errorf("rule %d, actor %q method %d: %w", i, codeName, m.Method, ErrRejectedByRule)
+			}
+			if r.Rate > 0 {
+				limits = append(limits, bucketLimit{key: fmt.Sprintf("rule/%d/%s", i, sender), rate: r.Rate, burst: r.Burst})
+			}
+			break
+		}
+	}
+	if policy.SenderRate > 0 {
+		limits = append(limits, bucketLimit{key: "sender/" + sender.String(), rate: policy.SenderRate, burst: policy.SenderBurst})
+	}
+	if len(limits) > 0 && !mp.policy.admit(limits, consume) {
+		return fmt.Errorf("%s: %w", m.From, ErrSenderRateLimited)
+	}
+
+	return nil
+}
+
+// actorCodeName returns the name of the actor code of addr, like "multisig", or an empty string if the
+// actor doesn't exist.
+func (mp *MessagePool) actorCodeName(ctx context.Context, addr address.Address, curTS *types.TipSet) string {
+	act, err := mp.api.GetActorAfter(ctx, addr, curTS)
+	if err != nil {
+		return ""
+	}
+	if name, _, ok := actors.GetActorMetaByCode(act.Code); ok {
+		return name
+	}
+	// the names of the codes of the old actors versions are like fil/2/multisig
+	return path.Base(builtin.ActorNameByCode(act.Code))
+}
+
+func ruleMatches(r *types.MpoolPolicyRule, codeName string, m *types.Message) bool {
+	if r.ActorCode != "" && r.ActorCode != codeName {
+		return false
+	}
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, method := range r.Methods {
+		if method == m.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list []address.Address, addrs ...address.Address) bool {
+	for _, a := range list {
+		for _, addr := range addrs {
+			if a == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestMpoolPolicy(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	tma := newTestMpoolAPI()
+	w, mp := newWalletAndMpool(t, tma)
+	defer mp.Close() // nolint
+
+	newSender := func() address.Address {
+		sender, err := w.NewAddress(ctx, address.SECP256K1)
+		require.NoError(t, err)
+		tma.setStateNonce(sender, 0)
+		return sender
+	}
+	setPolicy := func(policy types.MpoolPolicy) {
+		cfg := mp.GetConfig()
+		cfg.Policy = policy
+		require.NoError(t, mp.SetConfig(ctx, cfg))
+	}
+	target := mkAddress(1001)
+
+	t.Run("blocklist and allowlist", func(t *testing.T) {
+		blocked, allowed := newSender(), newSender()
+
+		setPolicy(types.MpoolPolicy{SenderBlocklist: []address.Address{blocked}})
+		err := mp.Add(ctx, mkMessage(blocked, target, 0, w))
+		assert.True(t, errors.Is(err, ErrSenderBlocked), err)
+		_, err = mp.Push(ctx, mkMessage(blocked, target, 0, w))
+		assert.True(t, errors.Is(err, ErrSenderBlocked), err)
+		mustAdd(t, mp, mkMessage(allowed, target, 0, w))
+
+		setPolicy(types.MpoolPolicy{SenderAllowlist: []address.Address{allowed}})
+		err = mp.Add(ctx, mkMessage(blocked, target, 0, w))
+		assert.True(t, errors.Is(err, ErrSenderNotAllowed), err)
+		mustAdd(t, mp, mkMessage(allowed, target, 1, w))
+
+		setPolicy(types.MpoolPolicy{RecipientBlocklist: []address.Address{target}})
+		err = mp.Add(ctx, mkMessage(allowed, target, 2, w))
+		assert.True(t, errors.Is(err, ErrRecipientBlocked), err)
+		assert.True(t, IsPolicyError(err))
+
+		setPolicy(types.MpoolPolicy{})
+		mustAdd(t, mp, mkMessage(blocked, target, 0, w))
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		sender := newSender()
+		setPolicy(types.MpoolPolicy{Rules: []types.MpoolPolicyRule{
+			{ActorCode: "multisig", Reject: true},
+			{ActorCode: "account", Methods: []abi.MethodNum{2}, Reject: true},
+		}})
+
+		mustAdd(t, mp, mkMessage(sender, target, 0, w))
+
+		msg := mkMessage(sender, target, 1, w).Message
+		msg.Method = 2
+		err := mp.checkPolicy(ctx, &msg, mp.curTS, true)
+		assert.True(t, errors.Is(err, ErrRejectedByRule), err)
+
+		msg.Method = 3
+		assert.NoError(t, mp.checkPolicy(ctx, &msg, mp.curTS, true))
+	})
+
+	t.Run("sender rate", func(t *testing.T) {
+		sender, other := newSender(), newSender()
+		setPolicy(types.MpoolPolicy{SenderRate: 0.001, SenderBurst: 2})
+
+		mustAdd(t, mp, mkMessage(sender, target, 0, w))
+		mustAdd(t, mp, mkMessage(sender, target, 1, w))
+
+		// checking the messages doesn't take the tokens
+		res, err := mp.CheckMessages(ctx, []*types.MessagePrototype{{Message: mkMessage(other, target, 0, w).Message}})
+		require.NoError(t, err)
+		policyCheck := res[0][len(res[0])-1]
+		assert.Equal(t, types.CheckStatusMessagePolicy, policyCheck.Code)
+		assert.True(t, policyCheck.OK)
+
+		res, err = mp.CheckMessages(ctx, []*types.MessagePrototype{{Message: mkMessage(sender, target, 2, w).Message}})
+		require.NoError(t, err)
+		policyCheck = res[0][len(res[0])-1]
+		assert.False(t, policyCheck.OK)
+
+		err = mp.Add(ctx, mkMessage(sender, target, 2, w))
+		assert.True(t, errors.Is(err, ErrSenderRateLimited), err)
+		mustAdd(t, mp, mkMessage(other, target, 0, w))
+
+		// a reloaded policy starts from full buckets
+		setPolicy(types.MpoolPolicy{SenderRate: 0.001, SenderBurst: 1})
+		mustAdd(t, mp, mkMessage(sender, target, 2, w))
+		err = mp.Add(ctx, mkMessage(sender, target, 3, w))
+		assert.True(t, errors.Is(err, ErrSenderRateLimited), err)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		cfg := mp.GetConfig()
+		cfg.Policy = types.MpoolPolicy{SenderRate: 1}
+		assert.Error(t, mp.SetConfig(ctx, cfg))
+	})
+}
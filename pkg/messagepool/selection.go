@@ -23,6 +23,10 @@ var bigBlockGasLimit = big.NewInt(constants.BlockGasLimit)
 
 const MaxBlocks = 15
 
+// greedySelectionQuality is the ticket quality above which the first block is more likely than any other
+// block, and the messages are selected greedily.
+const greedySelectionQuality = 0.84
+
 type msgChain struct {
 	msgs         []*types.SignedMessage
 	gasReward    *big.Int
@@ -36,6 +40,7 @@ type msgChain struct {
 	next         *msgChain
 	prev         *msgChain
 	sigType      crypto.SigType
+	trace        *selectionTrace
 }
 
 func (mp *MessagePool) SelectMessages(ctx context.Context, ts *types.TipSet, tq float64) ([]*types.SignedMessage, error) {
@@ -45,20 +50,27 @@ func (mp *MessagePool) SelectMessages(ctx context.Context, ts *types.TipSet, tq
 	mp.lk.Lock()
 	defer mp.lk.Unlock()
 
+	return mp.selectMessages(ctx, ts, tq, nil)
+}
+
+// selectMessages selects the messages for a block on ts, recording the decisions in trace if it isn't nil.
+func (mp *MessagePool) selectMessages(ctx context.Context, ts *types.TipSet, tq float64, trace *selectionTrace) ([]*types.SignedMessage, error) {
 	// Load messages for the target tipset; if it is the same as the current tipset in the mpool
 	//    then this is just the pending messages
 	pending, err := mp.getPendingMessages(ctx, mp.curTS, ts)
 	if err != nil {
 		return nil, err
 	}
+	trace.setPending(pending)
+
 	// if the ticket quality is high enough that the first block has higher probability
 	// than any other block, then we don't bother with optimal selection because the
 	// first block will always have higher effective performance
 	var sm *selectedMessages
-	if tq > 0.84 {
-		sm, err = mp.selectMessagesGreedy(ctx, mp.curTS, ts, pending)
+	if tq > greedySelectionQuality {
+		sm, err = mp.selectMessagesGreedy(ctx, mp.curTS, ts, pending, trace)
 	} else {
-		sm, err = mp.selectMessagesOptimal(ctx, mp.curTS, ts, tq, pending)
+		sm, err = mp.selectMessagesOptimal(ctx, mp.curTS, ts, tq, pending, trace)
 	}
 
 	if err != nil {
@@ -155,6 +167,7 @@ func (sm *selectedMessages) tryToAddWithDeps(mc *msgChain, mp *MessagePool, base
 		// as it can never be included.
 		// Otherwise we can just trim and continue
 		if depGasLimit > sm.gasLimit || depMsgLimit >= smMsgLimit {
+			mc.trace.reject(mc.msgs, reasonDepsExceedLimits)
 			mc.Invalidate()
 		} else {
 			// dependencies fit, just trim it
@@ -202,7 +215,7 @@ func (sm *selectedMessages) trimChain(mc *msgChain, mp *MessagePool, baseFee typ
 	}
 }
 
-func (mp *MessagePool) selectMessagesOptimal(ctx context.Context, curTS, ts *types.TipSet, tq float64, pending map[address.Address]map[uint64]*types.SignedMessage) (*selectedMessages, error) {
+func (mp *MessagePool) selectMessagesOptimal(ctx context.Context, curTS, ts *types.TipSet, tq float64, pending map[address.Address]map[uint64]*types.SignedMessage, trace *selectionTrace) (*selectedMessages, error) {
 	start := time.Now()
 
 	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
@@ -221,7 +234,7 @@ func (mp *MessagePool) selectMessagesOptimal(ctx context.Context, curTS, ts *typ
 
 	// 0b. Select all priority messages that fit in the block
 	minGas := int64(gasguess.MinGas)
-	result := mp.selectPriorityMessages(ctx, pending, baseFee, ts)
+	result := mp.selectPriorityMessages(ctx, pending, baseFee, ts, trace)
 
 	// have we filled the block?
 	if result.gasLimit < minGas || len(result.msgs) >= constants.BlockMessageLimit {
@@ -232,7 +245,7 @@ func (mp *MessagePool) selectMessagesOptimal(ctx context.Context, curTS, ts *typ
 	startChains := time.Now()
 	var chains []*msgChain
 	for actor, mset := range pending {
-		next := mp.createMessageChains(ctx, actor, mset, baseFee, ts)
+		next := mp.createTracedMessageChains(ctx, actor, mset, baseFee, ts, trace)
 		chains = append(chains, next...)
 	}
 	if dt := time.Since(startChains); dt > time.Millisecond {
@@ -443,7 +456,7 @@ tailLoop:
 	return result, nil
 }
 
-func (mp *MessagePool) selectMessagesGreedy(ctx context.Context, curTS, ts *types.TipSet, pending map[address.Address]map[uint64]*types.SignedMessage) (*selectedMessages, error) {
+func (mp *MessagePool) selectMessagesGreedy(ctx context.Context, curTS, ts *types.TipSet, pending map[address.Address]map[uint64]*types.SignedMessage, trace *selectionTrace) (*selectedMessages, error) {
 	start := time.Now()
 
 	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
@@ -462,7 +475,7 @@ func (mp *MessagePool) selectMessagesGreedy(ctx context.Context, curTS, ts *type
 
 	// 0b. Select all priority messages that fit in the block
 	minGas := int64(gasguess.MinGas)
-	result := mp.selectPriorityMessages(ctx, pending, baseFee, ts)
+	result := mp.selectPriorityMessages(ctx, pending, baseFee, ts, trace)
 
 	// have we filled the block?
 	if result.gasLimit < minGas || len(result.msgs) > constants.BlockMessageLimit {
@@ -473,7 +486,7 @@ func (mp *MessagePool) selectMessagesGreedy(ctx context.Context, curTS, ts *type
 	startChains := time.Now()
 	var chains []*msgChain
 	for actor, mset := range pending {
-		next := mp.createMessageChains(ctx, actor, mset, baseFee, ts)
+		next := mp.createTracedMessageChains(ctx, actor, mset, baseFee, ts, trace)
 		chains = append(chains, next...)
 	}
 	if dt := time.Since(startChains); dt > time.Millisecond {
@@ -570,7 +583,7 @@ tailLoop:
 	return result, nil
 }
 
-func (mp *MessagePool) selectPriorityMessages(ctx context.Context, pending map[address.Address]map[uint64]*types.SignedMessage, baseFee types.BigInt, ts *types.TipSet) *selectedMessages {
+func (mp *MessagePool) selectPriorityMessages(ctx context.Context, pending map[address.Address]map[uint64]*types.SignedMessage, baseFee types.BigInt, ts *types.TipSet, trace *selectionTrace) *selectedMessages {
 	start := time.Now()
 	defer func() {
 		if dt := time.Since(start); dt > time.Millisecond {
@@ -601,7 +614,7 @@ func (mp *MessagePool) selectPriorityMessages(ctx context.Context, pending map[a
 			// remove actor from pending set as we are already processed these messages
 			delete(pending, pk)
 			// create chains for the priority actor
-			next := mp.createMessageChains(ctx, actor, mset, baseFee, ts)
+			next := mp.createTracedMessageChains(ctx, actor, mset, baseFee, ts, trace)
 			chains = append(chains, next...)
 		}
 	}
@@ -751,6 +764,12 @@ func (*MessagePool) getGasPerf(gasReward *big.Int, gasLimit int64) float64 {
 }
 
 func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Address, mset map[uint64]*types.SignedMessage, baseFee types.BigInt, ts *types.TipSet) []*msgChain {
+	return mp.createTracedMessageChains(ctx, actor, mset, baseFee, ts, nil)
+}
+
+// createTracedMessageChains creates the chains of the messages of actor, recording the dropped messages and
+// the chains in trace if it isn't nil.
+func (mp *MessagePool) createTracedMessageChains(ctx context.Context, actor address.Address, mset map[uint64]*types.SignedMessage, baseFee types.BigInt, ts *types.TipSet, trace *selectionTrace) []*msgChain {
 	// collect all messages
 	msgs := make([]*types.SignedMessage, 0, len(mset))
 	for _, m := range mset {
@@ -772,6 +791,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 	a, err := mp.api.GetActorAfter(ctx, actor, ts)
 	if err != nil {
 		log.Errorf("failed to load actor state, not building chain for %s: %v", actor, err)
+		trace.reject(msgs, reasonNoActorState)
 		return nil
 	}
 
@@ -780,6 +800,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 	gasLimit := int64(0)
 	skip := 0
 	i := 0
+	dropReason := ""
 	rewards := make([]*big.Int, 0, len(msgs))
 	for i = 0; i < len(msgs); i++ {
 		m := msgs[i]
@@ -787,27 +808,32 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 		if m.Message.Nonce < curNonce {
 			log.Warnf("encountered message from actor %s with nonce (%d) less than the current nonce (%d)",
 				actor, m.Message.Nonce, curNonce)
+			trace.reject([]*types.SignedMessage{m}, reasonNonceTooLow)
 			skip++
 			continue
 		}
 
 		if m.Message.Nonce != curNonce {
+			dropReason = reasonNonceGap
 			break
 		}
 		curNonce++
 
 		minGas := mp.gasPriceSchedule.PricelistByEpoch(ts.Height()).OnChainMessage(m.ChainLength()).Total()
 		if m.Message.GasLimit < minGas {
+			dropReason = reasonMinGas
 			break
 		}
 
 		gasLimit += m.Message.GasLimit
 		if gasLimit > constants.BlockGasLimit {
+			dropReason = reasonSenderGasLimit
 			break
 		}
 
 		required := m.Message.RequiredFunds().Int
 		if balance.Cmp(required) < 0 {
+			dropReason = reasonBalance
 			break
 		}
 
@@ -819,6 +845,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 		gasReward := mp.getGasReward(m, baseFee)
 		rewards = append(rewards, gasReward)
 	}
+	trace.drop(msgs[i:], dropReason)
 
 	// check we have a sane set of messages to construct the chains
 	if i > skip {
@@ -829,6 +856,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 
 	// if we have more messages from this sender than can fit in a block, drop the extra ones
 	if len(msgs) > constants.BlockMessageLimit {
+		trace.reject(msgs[constants.BlockMessageLimit:], reasonSenderMsgLimit)
 		msgs = msgs[:constants.BlockMessageLimit]
 	}
 
@@ -849,6 +877,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 		chain.gasPerf = mp.getGasPerf(chain.gasReward, chain.gasLimit)
 		chain.valid = true
 		chain.sigType = m.Signature.Type
+		chain.trace = trace
 		return chain
 	}
 
@@ -914,6 +943,7 @@ func (mp *MessagePool) createMessageChains(ctx context.Context, actor address.Ad
 	for i := len(chains) - 1; i > 0; i-- {
 		chains[i].prev = chains[i-1]
 	}
+	trace.addChains(chains)
 
 	return chains
 }
@@ -941,6 +971,7 @@ func (mc *msgChain) Trim(gasLimit int64, msgLimit int, mp *MessagePool, baseFee
 		i--
 	}
 
+	mc.trace.reject(mc.msgs[i+1:], reasonTrimmed)
 	if i < 0 {
 		mc.msgs = nil
 		mc.valid = false
@@ -956,6 +987,7 @@ func (mc *msgChain) Trim(gasLimit int64, msgLimit int, mp *MessagePool, baseFee
 }
 
 func (mc *msgChain) Invalidate() {
+	mc.trace.reject(mc.msgs, reasonDependency)
 	mc.valid = false
 	mc.msgs = nil
 	if mc.next != nil {
@@ -1035,7 +1067,7 @@ func (mp *MessagePool) MultipleSelectMessages(ctx context.Context, ts *types.Tip
 		}
 
 		var selMsg *selectedMessages
-		if tq > 0.84 {
+		if tq > greedySelectionQuality {
 			selMsg, err = mp.multiSelectMessagesGreedy(ctx, mp.curTS, ts, tq, pending)
 		} else {
 			selMsg, err = mp.multiSelectMessagesOptimal(ctx, mp.curTS, ts, tq, pending)
@@ -1062,9 +1094,9 @@ func (mp *MessagePool) MultipleSelectMessages(ctx context.Context, ts *types.Tip
 }
 
 func (mp *MessagePool) multiSelectMessagesGreedy(ctx context.Context, curTS, ts *types.TipSet, tq float64, pending map[address.Address]map[uint64]*types.SignedMessage) (*selectedMessages, error) {
-	return mp.selectMessagesGreedy(ctx, curTS, ts, pending)
+	return mp.selectMessagesGreedy(ctx, curTS, ts, pending, nil)
 }
 
 func (mp *MessagePool) multiSelectMessagesOptimal(ctx context.Context, curTS, ts *types.TipSet, tq float64, pending map[address.Address]map[uint64]*types.SignedMessage) (*selectedMessages, error) {
-	return mp.selectMessagesOptimal(ctx, curTS, ts, tq, pending)
+	return mp.selectMessagesOptimal(ctx, curTS, ts, tq, pending, nil)
 }
@@ -0,0 +1,175 @@
+package messagepool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// the reasons a pending message isn't selected
+const (
+	reasonNoActorState     = "failed to load the state of the sender"
+	reasonNonceTooLow      = "nonce below the state nonce of the sender"
+	reasonNonceGap         = "nonce gap, a prior nonce of the sender is missing"
+	reasonMinGas           = "gas limit below the minimum gas of the message"
+	reasonSenderGasLimit   = "the messages of the sender exceed the block gas limit"
+	reasonBalance          = "insufficient balance of the sender"
+	reasonSenderMsgLimit   = "the messages of the sender exceed the block message limit"
+	reasonDependency       = "a prior message of the sender wasn't selected"
+	reasonDepsExceedLimits = "the prior messages of the sender exceed the block limits"
+	reasonTrimmed          = "trimmed from its chain to fit the block limits"
+	reasonNegativePerf     = "negative gas performance"
+	reasonBlockLimits      = "doesn't fit in the remaining block limits"
+	reasonBlockFull        = "not considered, the block was full"
+)
+
+// selectionTrace records the decisions of a message selection.
+// The methods of a nil trace do nothing, so the selection records them unconditionally.
+type selectionTrace struct {
+	pending []*types.SignedMessage
+	reasons map[cid.Cid]string
+	chains  map[cid.Cid]*msgChain
+	// chainIdx is the position of a chain among the chains of its sender
+	chainIdx map[*msgChain]int
+}
+
+func newSelectionTrace() *selectionTrace {
+	return &selectionTrace{
+		reasons:  make(map[cid.Cid]string),
+		chains:   make(map[cid.Cid]*msgChain),
+		chainIdx: make(map[*msgChain]int),
+	}
+}
+
+// setPending records the messages the selection starts from, before it takes the priority messages out.
+func (t *selectionTrace) setPending(pending map[address.Address]map[uint64]*types.SignedMessage) {
+	if t == nil {
+		return
+	}
+	for _, mset := range pending {
+		for _, m := range mset {
+			t.pending = append(t.pending, m)
+		}
+	}
+}
+
+// reject records why msgs can't be selected, unless an earlier reason was recorded.
+func (t *selectionTrace) reject(msgs []*types.SignedMessage, reason string) {
+	if t == nil {
+		return
+	}
+	for _, m := range msgs {
+		c := m.Cid()
+		if _, ok := t.reasons[c]; !ok {
+			t.reasons[c] = reason
+		}
+	}
+}
+
+// drop records that the first of the ordered messages of a sender can't be selected for reason, and the
+// following ones because they depend on it. All the messages behind a nonce gap miss the gap.
+func (t *selectionTrace) drop(msgs []*types.SignedMessage, reason string) {
+	if t == nil || len(msgs) == 0 {
+		return
+	}
+	t.reject(msgs[:1], reason)
+	if reason != reasonNonceGap {
+		reason = reasonDependency
+	}
+	t.reject(msgs[1:], reason)
+}
+
+// addChains records the chains of the messages of a sender.
+func (t *selectionTrace) addChains(chains []*msgChain) {
+	if t == nil {
+		return
+	}
+	for i, chain := range chains {
+		t.chainIdx[chain] = i
+		for _, m := range chain.msgs {
+			t.chains[m.Cid()] = chain
+		}
+	}
+}
+
+// explain explains the selection of each pending message, selected being the messages finally selected.
+func (t *selectionTrace) explain(selected []*types.SignedMessage) []*types.MsgSelectExplain {
+	inBlock := make(map[cid.Cid]struct{}, len(selected))
+	for _, m := range selected {
+		inBlock[m.Cid()] = struct{}{}
+	}
+
+	out := make([]*types.MsgSelectExplain, 0, len(t.pending))
+	for _, m := range t.pending {
+		c := m.Cid()
+		e := &types.MsgSelectExplain{
+			Cid:   c,
+			From:  m.Message.From,
+			Nonce: m.Message.Nonce,
+			Chain: -1,
+		}
+		chain, inChain := t.chains[c]
+		if inChain {
+			e.Chain = t.chainIdx[chain]
+			e.GasPerf = chain.gasPerf
+			e.EffPerf = chain.effPerf
+		}
+
+		_, e.Selected = inBlock[c]
+		if !e.Selected {
+			reason, ok := t.reasons[c]
+			switch {
+			case ok:
+				e.Reason = reason
+			case !inChain:
+				e.Reason = reasonBlockFull
+			case chain.gasPerf < 0:
+				e.Reason = reasonNegativePerf
+			default:
+				e.Reason = reasonBlockLimits
+			}
+		}
+		out = append(out, e)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From.String() < out[j].From.String()
+		}
+		return out[i].Nonce < out[j].Nonce
+	})
+	return out
+}
+
+// SelectMessagesExplain runs the message selection of SelectMessages, and explains for each pending message
+// whether it was selected, or why not, with the performance of its chain.
+func (mp *MessagePool) SelectMessagesExplain(ctx context.Context, ts *types.TipSet, tq float64) (*types.MpoolSelectExplain, error) {
+	mp.curTSLk.Lock()
+	defer mp.curTSLk.Unlock()
+
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	baseFee, err := mp.api.ChainComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("computing basefee: %w", err)
+	}
+
+	trace := newSelectionTrace()
+	msgs, err := mp.selectMessages(ctx, ts, tq, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MpoolSelectExplain{
+		BaseFee:       baseFee,
+		TicketQuality: tq,
+		Greedy:        tq > greedySelectionQuality,
+		Messages:      trace.explain(msgs),
+	}, nil
+}
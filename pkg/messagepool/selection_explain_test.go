@@ -0,0 +1,89 @@
+// stm: #unit
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	tbig "github.com/filecoin-project/go-state-types/big"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/messagepool/gasguess"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestSelectMessagesExplain(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	mp, tma := makeTestMpool()
+
+	w1 := newWallet(t)
+	a1, err := w1.NewAddress(ctx, address.SECP256K1)
+	require.NoError(t, err)
+	w2 := newWallet(t)
+	a2, err := w2.NewAddress(ctx, address.SECP256K1)
+	require.NoError(t, err)
+
+	block := tma.nextBlock()
+	ts := mkTipSet(block)
+	tma.applyBlock(t, block)
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalance(a2, 1) // in FIL
+
+	m0 := makeTestMessage(w1, a1, a2, 0, gasLimit, 200)
+	m1 := makeTestMessage(w1, a1, a2, 1, gasLimit, 200)
+	gapped := makeTestMessage(w1, a1, a2, 3, gasLimit, 200)
+	cheap := makeTestMessage(w2, a2, a1, 0, gasLimit, 1)
+	for _, m := range []*types.SignedMessage{m0, m1, gapped, cheap} {
+		mustAdd(t, mp, m)
+	}
+	// the fee cap of the cheap message is below the new base fee
+	tma.baseFee = tbig.NewInt(200)
+
+	for _, tq := range []float64{1.0, 0.5} {
+		explain, err := mp.SelectMessagesExplain(ctx, ts, tq)
+		require.NoError(t, err)
+		assert.Equal(t, tq > greedySelectionQuality, explain.Greedy)
+		assert.Equal(t, tbig.NewInt(200), explain.BaseFee)
+
+		byCid := make(map[cid.Cid]*types.MsgSelectExplain)
+		for _, e := range explain.Messages {
+			byCid[e.Cid] = e
+		}
+		require.Len(t, byCid, 4)
+
+		for _, m := range []*types.SignedMessage{m0, m1} {
+			e := byCid[m.Cid()]
+			assert.True(t, e.Selected)
+			assert.Empty(t, e.Reason)
+			assert.Equal(t, 0, e.Chain)
+			assert.Greater(t, e.GasPerf, 0.0)
+		}
+
+		e := byCid[gapped.Cid()]
+		assert.False(t, e.Selected)
+		assert.Equal(t, reasonNonceGap, e.Reason)
+		assert.Equal(t, -1, e.Chain)
+
+		e = byCid[cheap.Cid()]
+		assert.False(t, e.Selected)
+		assert.Equal(t, reasonNegativePerf, e.Reason)
+		assert.Equal(t, 0, e.Chain)
+		assert.Less(t, e.GasPerf, 0.0)
+	}
+
+	// the explained selection is the one of SelectMessages
+	msgs, err := mp.SelectMessages(ctx, ts, 1.0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, m0.Cid(), msgs[0].Cid())
+	assert.Equal(t, m1.Cid(), msgs[1].Cid())
+}
@@ -1228,7 +1228,7 @@ func testCompetitiveMessageSelection(t *testing.T, rng *rand.Rand, getPremium fu
 	pending, err := mp.getPendingMessages(context.TODO(), mp.curTS, ts)
 	require.NoError(t, err)
 	// 1. greedy selection
-	gm, err := mp.selectMessagesGreedy(context.Background(), ts, ts, pending)
+	gm, err := mp.selectMessagesGreedy(context.Background(), ts, ts, pending, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,274 @@
+package msgindex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+var log = logging.Logger("msgindex")
+
+var ErrNotFound = errors.New("message not found")
+
+var pragmas = []string{
+	"PRAGMA synchronous = normal",
+	"PRAGMA temp_store = memory",
+	"PRAGMA mmap_size = 30000000000",
+	"PRAGMA page_size = 32768",
+	"PRAGMA auto_vacuum = NONE",
+	"PRAGMA automatic_index = OFF",
+	"PRAGMA journal_mode = WAL",
+	"PRAGMA read_uncommitted = ON",
+}
+
+var ddls = []string{
+	`CREATE TABLE IF NOT EXISTS messages (
+		cid TEXT PRIMARY KEY NOT NULL,
+		tipset_key BLOB NOT NULL,
+		epoch INTEGER NOT NULL,
+		msg_index INTEGER NOT NULL
+	)`,
+
+	`CREATE INDEX IF NOT EXISTS tipset_key_index ON messages (tipset_key)`,
+
+	// metadata containing version of schema
+	`CREATE TABLE IF NOT EXISTS _meta (
+    	version UINT64 NOT NULL UNIQUE
+	)`,
+
+	// version 1.
+	`INSERT OR IGNORE INTO _meta (version) VALUES (1)`,
+}
+
+const schemaVersion = 1
+
+const (
+	insertMessage = `INSERT OR REPLACE INTO messages
+	(cid, tipset_key, epoch, msg_index)
+	VALUES(?, ?, ?, ?)`
+
+	deleteTipSetMessages = `DELETE FROM messages WHERE tipset_key = ?`
+)
+
+// MsgInfo is the location of a message in the chain.
+type MsgInfo struct {
+	Message cid.Cid
+	// TipSet is the key of the tipset which includes the message
+	TipSet types.TipSetKey
+	Epoch  abi.ChainEpoch
+	// Index is the index of the message in the deduplicated messages of the tipset,
+	// which is also the index of its receipt
+	Index int
+}
+
+type chainReader interface {
+	GetTipSet(context.Context, types.TipSetKey) (*types.TipSet, error)
+}
+
+type messageLoader interface {
+	LoadTipSetMessage(ctx context.Context, ts *types.TipSet) ([]types.BlockMessagesInfo, error)
+}
+
+// MsgIndex is a sqlite index mapping a message cid to the tipset including it.
+type MsgIndex struct {
+	db *sql.DB
+
+	cs       chainReader
+	messages messageLoader
+}
+
+func NewMsgIndex(path string, cs chainReader, messages messageLoader) (*MsgIndex, error) {
+	db, err := sql.Open("sqlite3", path+"?mode=rwc")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite3 database: %w", err)
+	}
+
+	for _, pragma := range pragmas {
+		if _, err := db.Exec(pragma); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("exec pragma %q: %w", pragma, err)
+		}
+	}
+
+	q, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name='_meta';")
+	if err == sql.ErrNoRows || !q.Next() {
+		// empty database, create the schema
+		for _, ddl := range ddls {
+			if _, err := db.Exec(ddl); err != nil {
+				_ = db.Close()
+				return nil, fmt.Errorf("exec ddl %q: %w", ddl, err)
+			}
+		}
+	} else if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("looking for _meta table: %w", err)
+	} else {
+		// Ensure we don't open a database from a different schema version
+
+		row := db.QueryRow("SELECT max(version) FROM _meta")
+		var version int
+		err := row.Scan(&version)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("invalid database version: no version found")
+		}
+		if version != schemaVersion {
+			_ = db.Close()
+			return nil, fmt.Errorf("invalid database version: got %d, expected %d", version, schemaVersion)
+		}
+	}
+	if q != nil {
+		_ = q.Close()
+	}
+
+	return &MsgIndex{
+		db:       db,
+		cs:       cs,
+		messages: messages,
+	}, nil
+}
+
+// GetMsgInfo returns the location of the message m, ErrNotFound is returned if it isn't indexed.
+func (mi *MsgIndex) GetMsgInfo(ctx context.Context, m cid.Cid) (MsgInfo, error) {
+	row := mi.db.QueryRowContext(ctx, "SELECT tipset_key, epoch, msg_index FROM messages WHERE cid = ?", m.String())
+
+	var (
+		tskBytes []byte
+		epoch    int64
+		index    int
+	)
+	if err := row.Scan(&tskBytes, &epoch, &index); err != nil {
+		if err == sql.ErrNoRows {
+			return MsgInfo{}, ErrNotFound
+		}
+		return MsgInfo{}, err
+	}
+
+	tsk, err := types.TipSetKeyFromBytes(tskBytes)
+	if err != nil {
+		return MsgInfo{}, fmt.Errorf("decode tipset key: %w", err)
+	}
+
+	return MsgInfo{
+		Message: m,
+		TipSet:  tsk,
+		Epoch:   abi.ChainEpoch(epoch),
+		Index:   index,
+	}, nil
+}
+
+// HeadChange removes the messages of the reverted tipsets from the index and indexes the
+// messages of the applied ones, it is meant to be subscribed to the chain head changes.
+func (mi *MsgIndex) HeadChange(rev, app []*types.TipSet) error {
+	ctx := context.TODO()
+
+	tx, err := mi.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, ts := range rev {
+		if _, err := tx.ExecContext(ctx, deleteTipSetMessages, ts.Key().Bytes()); err != nil {
+			return fmt.Errorf("exec delete messages: %w", err)
+		}
+	}
+
+	for _, ts := range app {
+		if err := mi.indexTipSet(ctx, tx, ts); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (mi *MsgIndex) indexTipSet(ctx context.Context, tx *sql.Tx, ts *types.TipSet) error {
+	bmis, err := mi.messages.LoadTipSetMessage(ctx, ts)
+	if err != nil {
+		return fmt.Errorf("load messages of tipset %s: %w", ts.Key(), err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, insertMessage)
+	if err != nil {
+		return fmt.Errorf("prepare insert message: %w", err)
+	}
+	defer stmt.Close() //nolint:errcheck
+
+	// the messages are deduplicated in block order the way they are executed, so that the index of
+	// a message is the index of its receipt
+	seen := make(map[cid.Cid]struct{})
+	index := 0
+	for _, bmi := range bmis {
+		for _, msg := range append(bmi.BlsMessages, bmi.SecpkMessages...) {
+			mcid := msg.VMMessage().Cid()
+			if _, ok := seen[mcid]; ok {
+				continue
+			}
+			seen[mcid] = struct{}{}
+
+			if _, err := stmt.ExecContext(ctx, msg.Cid().String(), ts.Key().Bytes(), int64(ts.Height()), index); err != nil {
+				return fmt.Errorf("exec insert message: %w", err)
+			}
+			index++
+		}
+	}
+
+	return nil
+}
+
+// Backfill indexes the messages of the tipsets of the chain of from down to the epoch to, and
+// returns the number of indexed tipsets.
+func (mi *MsgIndex) Backfill(ctx context.Context, from *types.TipSet, to abi.ChainEpoch) (int, error) {
+	count := 0
+	for cur := from; cur.Height() >= to; {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		tx, err := mi.db.BeginTx(ctx, nil)
+		if err != nil {
+			return count, fmt.Errorf("begin transaction: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, deleteTipSetMessages, cur.Key().Bytes()); err != nil {
+			_ = tx.Rollback()
+			return count, fmt.Errorf("exec delete messages: %w", err)
+		}
+		if err := mi.indexTipSet(ctx, tx, cur); err != nil {
+			_ = tx.Rollback()
+			return count, err
+		}
+		if err := tx.Commit(); err != nil {
+			return count, fmt.Errorf("commit transaction: %w", err)
+		}
+		count++
+
+		if cur.Height() == 0 {
+			break
+		}
+		if cur, err = mi.cs.GetTipSet(ctx, cur.Parents()); err != nil {
+			return count, err
+		}
+	}
+
+	log.Infof("backfilled message index of %d tipsets from %d to %d", count, from.Height(), to)
+	return count, nil
+}
+
+func (mi *MsgIndex) Close() error {
+	if mi.db == nil {
+		return nil
+	}
+	return mi.db.Close()
+}
@@ -0,0 +1,123 @@
+package msgindex_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	_ "github.com/filecoin-project/venus/pkg/crypto/bls"
+	_ "github.com/filecoin-project/venus/pkg/crypto/secp"
+	"github.com/filecoin-project/venus/pkg/msgindex"
+	"github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+func TestMsgIndex(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	signer, _ := testhelpers.NewMockSignersAndKeyInfo(2)
+	newSignedMessage := testhelpers.NewSignedMessageForTestGetter(signer)
+
+	msgs := []*types.SignedMessage{newSignedMessage(0), newSignedMessage(1), newSignedMessage(2)}
+	link1 := builder.BuildOneOn(ctx, builder.Genesis(), func(bb *chain.BlockBuilder) {
+		bb.AddMessages(msgs[:2], nil)
+	})
+	link2 := builder.BuildOneOn(ctx, link1, func(bb *chain.BlockBuilder) {
+		bb.AddMessages(msgs[2:], nil)
+	})
+
+	mi, err := msgindex.NewMsgIndex(filepath.Join(t.TempDir(), "msgindex.db"), builder.Store(), builder)
+	require.NoError(t, err)
+	defer mi.Close() //nolint:errcheck
+
+	requireInfo := func(msg *types.SignedMessage, ts *types.TipSet, index int) {
+		info, err := mi.GetMsgInfo(ctx, msg.Cid())
+		require.NoError(t, err)
+		require.Equal(t, ts.Key(), info.TipSet)
+		require.Equal(t, ts.Height(), info.Epoch)
+		require.Equal(t, index, info.Index)
+	}
+
+	_, err = mi.GetMsgInfo(ctx, msgs[0].Cid())
+	require.ErrorIs(t, err, msgindex.ErrNotFound)
+
+	require.NoError(t, mi.HeadChange(nil, []*types.TipSet{link1, link2}))
+	requireInfo(msgs[0], link1, 0)
+	requireInfo(msgs[1], link1, 1)
+	requireInfo(msgs[2], link2, 0)
+
+	// the messages of a reverted tipset are removed
+	fork2 := builder.AppendOn(ctx, link1, 1)
+	require.NoError(t, mi.HeadChange([]*types.TipSet{link2}, []*types.TipSet{fork2}))
+	requireInfo(msgs[0], link1, 0)
+	_, err = mi.GetMsgInfo(ctx, msgs[2].Cid())
+	require.ErrorIs(t, err, msgindex.ErrNotFound)
+
+	// backfill indexes the chain down to the given epoch
+	count, err := mi.Backfill(ctx, link2, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	requireInfo(msgs[2], link2, 0)
+}
+
+// rawLoader loads all the messages of the blocks of a tipset, including the ones already included
+// by the previous blocks.
+type rawLoader struct {
+	*chain.Builder
+}
+
+func (l rawLoader) LoadTipSetMessage(ctx context.Context, ts *types.TipSet) ([]types.BlockMessagesInfo, error) {
+	bmis := make([]types.BlockMessagesInfo, 0, ts.Len())
+	for _, blk := range ts.Blocks() {
+		secpMsgs, _, err := l.LoadMetaMessages(ctx, blk.Messages)
+		if err != nil {
+			return nil, err
+		}
+		bmi := types.BlockMessagesInfo{Block: blk}
+		for _, msg := range secpMsgs {
+			bmi.SecpkMessages = append(bmi.SecpkMessages, msg)
+		}
+		bmis = append(bmis, bmi)
+	}
+	return bmis, nil
+}
+
+func TestMsgIndexDuplicateMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	signer, _ := testhelpers.NewMockSignersAndKeyInfo(2)
+	newSignedMessage := testhelpers.NewSignedMessageForTestGetter(signer)
+
+	msgs := []*types.SignedMessage{newSignedMessage(0), newSignedMessage(1), newSignedMessage(2)}
+	ts := builder.BuildOn(ctx, builder.Genesis(), 2, func(bb *chain.BlockBuilder, i int) {
+		bb.AddMessages(msgs[i:i+2], nil)
+	})
+
+	mi, err := msgindex.NewMsgIndex(filepath.Join(t.TempDir(), "msgindex.db"), builder.Store(), rawLoader{builder})
+	require.NoError(t, err)
+	defer mi.Close() //nolint:errcheck
+
+	require.NoError(t, mi.HeadChange(nil, []*types.TipSet{ts}))
+
+	// the message included by both blocks is indexed once, at its position in the first block
+	expected := []*types.SignedMessage{msgs[0], msgs[1], msgs[2]}
+	secpMsgs, _, err := builder.LoadMetaMessages(ctx, ts.At(0).Messages)
+	require.NoError(t, err)
+	if secpMsgs[0].Cid() != msgs[0].Cid() {
+		expected = []*types.SignedMessage{msgs[1], msgs[2], msgs[0]}
+	}
+	for index, msg := range expected {
+		info, err := mi.GetMsgInfo(ctx, msg.Cid())
+		require.NoError(t, err)
+		require.Equal(t, index, info.Index)
+	}
+}
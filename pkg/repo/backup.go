@@ -0,0 +1,320 @@
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/pkg/config"
+)
+
+// BackupVersion is the version of the backup format written by Backup.
+const BackupVersion = 1
+
+const (
+	backupMagic = "venus-backup"
+	// backupMaxLen bounds the size of the config, keys and values read from a backup
+	backupMaxLen = 1 << 30
+	// backupBatchSize is the number of entries written per batch when restoring
+	backupBatchSize = 1000
+)
+
+// backupDatastores returns the backed up datastores of r by name.
+func backupDatastores(r Repo) []struct {
+	name string
+	ds   Datastore
+} {
+	return []struct {
+		name string
+		ds   Datastore
+	}{
+		{walletDatastorePrefix, r.WalletDatastore()},
+		{chainDatastorePrefix, r.ChainDatastore()},
+		{metaDatastorePrefix, r.MetaDatastore()},
+		{paychDatastorePrefix, r.PaychDatastore()},
+	}
+}
+
+// Backup writes the config and the wallet, chain, meta and paych datastores of r to w.
+// The datastores supporting transactions are read from snapshots taken before any entry
+// is written, so the backup is consistent while the node is running.
+//
+// The backup is made of a header holding the backup version, the repo version and the
+// config, followed by the datastore entries and a sha256 checksum of everything before it.
+func Backup(ctx context.Context, r Repo, w io.Writer) error {
+	cfg, err := json.MarshalIndent(r.Config(), "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	type source struct {
+		name string
+		q    func(context.Context, query.Query) (query.Results, error)
+	}
+	var sources []source
+	for _, bds := range backupDatastores(r) {
+		if tds, ok := bds.ds.(datastore.TxnDatastore); ok {
+			txn, err := tds.NewTransaction(ctx, true)
+			if err != nil {
+				return fmt.Errorf("failed to open transaction on %s datastore: %w", bds.name, err)
+			}
+			defer txn.Discard(ctx)
+			sources = append(sources, source{name: bds.name, q: txn.Query})
+		} else {
+			sources = append(sources, source{name: bds.name, q: bds.ds.Query})
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bkw := &backupWriter{w: bw, h: sha256.New()}
+	bkw.writeBytes([]byte(backupMagic))
+	bkw.writeUint(BackupVersion)
+	bkw.writeUint(uint64(r.Version()))
+	bkw.writeBytes(cfg)
+
+	for _, src := range sources {
+		res, err := src.q(ctx, query.Query{})
+		if err != nil {
+			return fmt.Errorf("failed to query %s datastore: %w", src.name, err)
+		}
+		for entry := range res.Next() {
+			if entry.Error != nil {
+				_ = res.Close()
+				return fmt.Errorf("failed to read %s datastore: %w", src.name, entry.Error)
+			}
+			bkw.writeBytes([]byte(src.name))
+			bkw.writeBytes([]byte(entry.Key))
+			bkw.writeBytes(entry.Value)
+		}
+		if err := res.Close(); err != nil {
+			return err
+		}
+		if bkw.err != nil {
+			return bkw.err
+		}
+	}
+
+	// the end of the entries
+	bkw.writeBytes(nil)
+	if bkw.err != nil {
+		return bkw.err
+	}
+	if err := cbg.WriteByteArray(bw, bkw.h.Sum(nil)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// BackupToFile writes the backup of r to a new file at path.
+func BackupToFile(ctx context.Context, r Repo, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	if err := Backup(ctx, r, f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RestoreBackup creates a repo at repoPath from the backup read from rd. The repo keeps the
+// version it was backed up with, so it is upgraded by the migrations when it is next opened
+// by the daemon. The repo is removed if the backup can't be restored.
+func RestoreBackup(ctx context.Context, rd io.Reader, repoPath string) (err error) {
+	repoPath, err = homedir.Expand(repoPath)
+	if err != nil {
+		return err
+	}
+
+	bkr := &backupReader{r: bufio.NewReader(rd), h: sha256.New()}
+
+	if magic := bkr.readBytes(); bkr.err == nil && string(magic) != backupMagic {
+		return fmt.Errorf("not a venus backup")
+	}
+	version := bkr.readUint()
+	if bkr.err == nil && version > BackupVersion {
+		return fmt.Errorf("unsupported backup version %d, binary needs update", version)
+	}
+	repoVersion := bkr.readUint()
+	if bkr.err == nil && repoVersion > uint64(LatestVersion) {
+		return fmt.Errorf("backup of repo version %d, binary needs update to handle it", repoVersion)
+	}
+	cfgBytes := bkr.readBytes()
+	if bkr.err != nil {
+		return fmt.Errorf("failed to read backup header: %w", bkr.err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	if err := InitFSRepo(repoPath, uint(repoVersion), cfg); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rerr := os.RemoveAll(repoPath); rerr != nil {
+				log.Errorf("failed to remove repo %s: %v", repoPath, rerr)
+			}
+		}
+	}()
+
+	r, err := OpenFSRepo(repoPath, LatestVersion)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := r.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	dss := make(map[string]Datastore)
+	for _, bds := range backupDatastores(r) {
+		dss[bds.name] = bds.ds
+	}
+	batches := make(map[string]datastore.Batch)
+	defer func() {
+		// the batches can't be used after being committed, cancel the ones left on failure
+		for _, batch := range batches {
+			if c, ok := batch.(interface{ Cancel() error }); ok {
+				_ = c.Cancel()
+			}
+		}
+	}()
+	commit := func() error {
+		for name, batch := range batches {
+			if err := batch.Commit(ctx); err != nil {
+				return err
+			}
+			delete(batches, name)
+		}
+		return nil
+	}
+
+	count := 0
+	for {
+		name := bkr.readBytes()
+		if bkr.err != nil {
+			return fmt.Errorf("failed to read backup: %w", bkr.err)
+		}
+		if len(name) == 0 {
+			break
+		}
+		key, value := bkr.readBytes(), bkr.readBytes()
+		if bkr.err != nil {
+			return fmt.Errorf("failed to read backup: %w", bkr.err)
+		}
+
+		batch, ok := batches[string(name)]
+		if !ok {
+			ds, ok := dss[string(name)]
+			if !ok {
+				return fmt.Errorf("unknown datastore %s in backup", name)
+			}
+			if batch, err = ds.Batch(ctx); err != nil {
+				return err
+			}
+			batches[string(name)] = batch
+		}
+		if err := batch.Put(ctx, datastore.RawKey(string(key)), value); err != nil {
+			return err
+		}
+
+		count++
+		if count%backupBatchSize == 0 {
+			if err := commit(); err != nil {
+				return err
+			}
+		}
+	}
+
+	sum := bkr.h.Sum(nil)
+	expected, err := cbg.ReadByteArray(bkr.r, sha256.Size)
+	if err != nil {
+		return fmt.Errorf("failed to read backup checksum: %w", err)
+	}
+	if !bytes.Equal(sum, expected) {
+		return fmt.Errorf("backup checksum mismatch")
+	}
+	if err := commit(); err != nil {
+		return err
+	}
+
+	log.Infof("restored %d entries of a repo of version %d to %s", count, repoVersion, repoPath)
+	return nil
+}
+
+// backupWriter writes cbor items to w and hashes them, the first error is kept in err.
+type backupWriter struct {
+	w   io.Writer
+	h   hash.Hash
+	err error
+}
+
+func (bw *backupWriter) writeBytes(b []byte) {
+	if bw.err == nil {
+		bw.err = cbg.WriteByteArray(io.MultiWriter(bw.w, bw.h), b)
+	}
+}
+
+func (bw *backupWriter) writeUint(v uint64) {
+	if bw.err == nil {
+		bw.err = cbg.WriteMajorTypeHeader(io.MultiWriter(bw.w, bw.h), cbg.MajUnsignedInt, v)
+	}
+}
+
+// backupReader reads cbor items from r and hashes them, the first error is kept in err.
+type backupReader struct {
+	r   *bufio.Reader
+	h   hash.Hash
+	err error
+}
+
+func (br *backupReader) readBytes() []byte {
+	if br.err != nil {
+		return nil
+	}
+	b, err := cbg.ReadByteArray(io.TeeReader(br.r, br.h), backupMaxLen)
+	if err != nil {
+		br.err = err
+		return nil
+	}
+	return b
+}
+
+func (br *backupReader) readUint() uint64 {
+	if br.err != nil {
+		return 0
+	}
+	maj, v, err := cbg.CborReadHeader(io.TeeReader(br.r, br.h))
+	if err != nil {
+		br.err = err
+		return 0
+	}
+	if maj != cbg.MajUnsignedInt {
+		br.err = errors.New("expected an unsigned integer")
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+// stm: #unit
+package repo
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBackupRestore(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src")
+
+	cfg := config.NewDefaultConfig()
+	cfg.API.APIAddress = "/ip4/127.0.0.1/tcp/1235"
+	require.NoError(t, InitFSRepo(srcPath, LatestVersion, cfg))
+	r, err := OpenFSRepo(srcPath, LatestVersion)
+	require.NoError(t, err)
+
+	entries := map[string]map[ds.Key][]byte{
+		walletDatastorePrefix: {ds.NewKey("/wallet/key"): []byte("wallet")},
+		chainDatastorePrefix:  {ds.NewKey("/head"): []byte("head")},
+		metaDatastorePrefix:   {ds.NewKey("/meta"): []byte("meta")},
+		paychDatastorePrefix:  {ds.NewKey("/paych/ch"): []byte("paych")},
+	}
+	for _, bds := range backupDatastores(r) {
+		for k, v := range entries[bds.name] {
+			require.NoError(t, bds.ds.Put(ctx, k, v))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Backup(ctx, r, buf))
+	require.NoError(t, r.Close())
+	backup := buf.Bytes()
+
+	t.Run("restore", func(t *testing.T) {
+		dstPath := filepath.Join(dir, "dst")
+		require.NoError(t, RestoreBackup(ctx, bytes.NewReader(backup), dstPath))
+
+		r, err := OpenFSRepo(dstPath, LatestVersion)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, r.Close()) }()
+
+		require.Equal(t, cfg.API.APIAddress, r.Config().API.APIAddress)
+		for _, bds := range backupDatastores(r) {
+			for k, v := range entries[bds.name] {
+				val, err := bds.ds.Get(ctx, k)
+				require.NoError(t, err)
+				require.Equal(t, v, val)
+			}
+		}
+	})
+
+	t.Run("existing repo", func(t *testing.T) {
+		require.Error(t, RestoreBackup(ctx, bytes.NewReader(backup), srcPath))
+	})
+
+	t.Run("corrupted backup", func(t *testing.T) {
+		corrupted := append([]byte{}, backup...)
+		corrupted[len(corrupted)/2] ^= 0xff
+		dstPath := filepath.Join(dir, "corrupted")
+		require.Error(t, RestoreBackup(ctx, bytes.NewReader(corrupted), dstPath))
+		exists, err := fileExists(dstPath)
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("not a backup", func(t *testing.T) {
+		require.Error(t, RestoreBackup(ctx, bytes.NewReader([]byte("not a backup")), filepath.Join(dir, "invalid")))
+	})
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	blocks "github.com/ipfs/go-libipfs/blocks"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+// convertBatchSize is the number of objects written per batch when converting a blockstore
+const convertBatchSize = 1000
+
+// convertEnginePaths are the paths of the converted blockstores, relative to the repo
+var convertEnginePaths = map[string]string{
+	"badgerds": "badger",
+	"pebble":   "pebble",
+}
+
+// ConvertDatastore copies the blockstore of r into a new blockstore of the given engine and
+// switches the config of r to it. The copy reads the live blockstore, so it can be run by the
+// daemon: the source blockstore is recorded in the config and the objects written to it after
+// the copy are copied when the repo is opened next. The source blockstore is left in place and
+// can be removed once the conversion is finished. A blockstore left at the path of the new one by
+// a previous conversion is moved aside.
+func ConvertDatastore(ctx context.Context, r Repo, engine string) error {
+	fsr, ok := r.(*FSRepo)
+	if !ok {
+		return fmt.Errorf("repo of type %T doesn't support blockstore conversion", r)
+	}
+	if fsr.readonly {
+		return ErrReadOnly
+	}
+
+	cfg := fsr.Config()
+	relPath, ok := convertEnginePaths[engine]
+	if !ok {
+		return fmt.Errorf("unknown blockstore engine: %s", engine)
+	}
+	if _, ok := convertEnginePaths[cfg.Datastore.Type]; !ok {
+		return fmt.Errorf("blockstore of type %s can't be converted", cfg.Datastore.Type)
+	}
+	if cfg.Datastore.Type == engine {
+		return fmt.Errorf("blockstore is already of type %s", engine)
+	}
+	if cfg.Datastore.Convert != nil {
+		return fmt.Errorf("the conversion from %s is not finished, restart the daemon first", cfg.Datastore.Convert.Path)
+	}
+
+	path := filepath.Join(fsr.path, relPath)
+	exists, err := fileExists(path)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if path == filepath.Join(fsr.path, cfg.Datastore.Path) {
+			return fmt.Errorf("%s is the path of the current blockstore", path)
+		}
+		old := fmt.Sprintf("%s.old-%d", path, time.Now().Unix())
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", path, err)
+		}
+		log.Warnf("moved the blockstore left by a previous conversion at %s to %s, it can be removed", path, old)
+	}
+
+	dst, err := fsr.openBlockstore(engine, relPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("converting blockstore at %s to %s", cfg.Datastore.Path, path)
+	n, err := copyMissing(ctx, fsr.Datastore(), dst)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.RemoveAll(path)
+		return fmt.Errorf("failed to convert blockstore: %w", err)
+	}
+	log.Infof("copied %d objects to %s", n, path)
+
+	newCfg := *cfg
+	dsCfg := *cfg.Datastore
+	dsCfg.Convert = &config.DatastoreConvertConfig{Type: dsCfg.Type, Path: dsCfg.Path}
+	dsCfg.Type = engine
+	dsCfg.Path = relPath
+	newCfg.Datastore = &dsCfg
+	return fsr.ReplaceConfig(&newCfg)
+}
+
+// finishConvert copies to ds the objects written to the source blockstore of the conversion
+// after it started, and removes the conversion from the config.
+func (r *FSRepo) finishConvert(ctx context.Context, ds blockstoreutil.Blockstore) error {
+	conv := r.cfg.Datastore.Convert
+	if r.readonly {
+		return fmt.Errorf("the conversion of the blockstore from %s is not finished, open the repo in read-write mode first", conv.Path)
+	}
+
+	src, err := r.openBlockstore(conv.Type, conv.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open source blockstore of conversion: %w", err)
+	}
+	defer src.Close() // nolint: errcheck
+
+	n, err := copyMissing(ctx, src, ds)
+	if err != nil {
+		return fmt.Errorf("failed to finish blockstore conversion: %w", err)
+	}
+	log.Infof("blockstore conversion finished with %d new objects, %s can be removed", n, filepath.Join(r.path, conv.Path))
+
+	cfg := *r.cfg
+	dsCfg := *cfg.Datastore
+	dsCfg.Convert = nil
+	cfg.Datastore = &dsCfg
+	return r.ReplaceConfig(&cfg)
+}
+
+// copyMissing copies the objects of from missing in to, and returns the number of copied objects.
+func copyMissing(ctx context.Context, from, to blockstoreutil.Blockstore) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys, err := from.AllKeysChan(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	batch := make([]blocks.Block, 0, convertBatchSize)
+	for c := range keys {
+		has, err := to.Has(ctx, c)
+		if err != nil {
+			return n, err
+		}
+		if has {
+			continue
+		}
+
+		blk, err := from.Get(ctx, c)
+		if err != nil {
+			return n, err
+		}
+		batch = append(batch, blk)
+		if len(batch) == convertBatchSize {
+			if err := to.PutMany(ctx, batch); err != nil {
+				return n, err
+			}
+			n += len(batch)
+			batch = batch[:0]
+			if n%(100*convertBatchSize) == 0 {
+				log.Infof("copied %d objects", n)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return n, err
+	}
+	if err := to.PutMany(ctx, batch); err != nil {
+		return n, err
+	}
+	return n + len(batch), nil
+}
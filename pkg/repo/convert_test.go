@@ -0,0 +1,58 @@
+// stm: #unit
+package repo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestConvertDatastore(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, InitFSRepo(path, LatestVersion, config.NewDefaultConfig()))
+	r, err := OpenFSRepo(path, LatestVersion)
+	require.NoError(t, err)
+
+	blk1 := blocks.NewBlock([]byte("before"))
+	require.NoError(t, r.Datastore().Put(ctx, blk1))
+
+	require.Error(t, ConvertDatastore(ctx, r, "badgerds"))
+	require.Error(t, ConvertDatastore(ctx, r, "unknown"))
+	require.NoError(t, ConvertDatastore(ctx, r, "pebble"))
+	require.Equal(t, "pebble", r.Config().Datastore.Type)
+	require.Equal(t, &config.DatastoreConvertConfig{Type: "badgerds", Path: "badger"}, r.Config().Datastore.Convert)
+	require.Error(t, ConvertDatastore(ctx, r, "badgerds"))
+
+	// written by the daemon after the copy
+	blk2 := blocks.NewBlock([]byte("after"))
+	require.NoError(t, r.Datastore().Put(ctx, blk2))
+	require.NoError(t, r.Close())
+
+	r, err = OpenFSRepo(path, LatestVersion)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, r.Close()) }()
+
+	require.Nil(t, r.Config().Datastore.Convert)
+	for _, blk := range []blocks.Block{blk1, blk2} {
+		has, err := r.Datastore().Has(ctx, blk.Cid())
+		require.NoError(t, err)
+		require.True(t, has)
+	}
+
+	// converting back moves aside the badger blockstore left by the first conversion
+	require.NoError(t, ConvertDatastore(ctx, r, "badgerds"))
+	require.Equal(t, "badgerds", r.Config().Datastore.Type)
+	require.Equal(t, &config.DatastoreConvertConfig{Type: "pebble", Path: "pebble"}, r.Config().Datastore.Convert)
+	old, err := filepath.Glob(filepath.Join(path, "badger.old-*"))
+	require.NoError(t, err)
+	require.Len(t, old, 1)
+}
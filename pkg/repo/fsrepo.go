@@ -1,8 +1,10 @@
 package repo
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -43,6 +45,7 @@ const (
 	snapshotFilenamePrefix = "snapshot"
 	dataTransfer           = "data-transfer"
 	fsSqlite               = "sqlite"
+	splitStoreStateFile    = "splitstore.json"
 )
 
 var log = logging.Logger("repo")
@@ -57,7 +60,7 @@ type FSRepo struct {
 	lk  sync.RWMutex
 	cfg *config.Config
 
-	ds       *blockstoreutil.BadgerBlockstore
+	ds       closableBlockstore
 	keystore fskeystore.Keystore
 	walletDs Datastore
 	chainDs  Datastore
@@ -66,6 +69,8 @@ type FSRepo struct {
 	paychDs Datastore
 	// lockfile is the file system lock to prevent others from opening the same repo.
 	lockfile io.Closer
+	// readonly repos keep the data written by the node in memory
+	readonly bool
 
 	sqlPath string
 	sqlErr  error
@@ -74,6 +79,11 @@ type FSRepo struct {
 
 var _ Repo = (*FSRepo)(nil)
 
+type closableBlockstore interface {
+	blockstoreutil.Blockstore
+	io.Closer
+}
+
 // InitFSRepo initializes a new repo at the target path with the provided configuration.
 // The successful result creates a symlink at targetPath pointing to a sibling directory
 // named with a timestamp and repo version number.
@@ -151,6 +161,17 @@ func Exists(repoPath string) (bool, error) {
 // The provided path may be to a directory, or a symbolic link pointing at a directory, which
 // will be resolved just once at open.
 func OpenFSRepo(repoPath string, version uint) (*FSRepo, error) {
+	return openFSRepo(repoPath, version, false)
+}
+
+// OpenFSRepoReadOnly opens an initialized fsrepo without taking the repo lock, so it can be
+// inspected while stopped or copied. Nothing is written to the repo: the datastores are opened
+// read-only and the data written to them is only kept in memory until the repo is closed.
+func OpenFSRepoReadOnly(repoPath string, version uint) (*FSRepo, error) {
+	return openFSRepo(repoPath, version, true)
+}
+
+func openFSRepo(repoPath string, version uint, readonly bool) (*FSRepo, error) {
 	repoPath, err := homedir.Expand(repoPath)
 	if err != nil {
 		return nil, err
@@ -181,15 +202,19 @@ func OpenFSRepo(repoPath string, version uint) (*FSRepo, error) {
 		}
 	}
 
-	r := &FSRepo{path: actualPath, version: version}
+	r := &FSRepo{path: actualPath, version: version, readonly: readonly}
 
-	r.lockfile, err = lockfile.Lock(r.path, lockFile)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to take repo lock")
+	if !readonly {
+		r.lockfile, err = lockfile.Lock(r.path, lockFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to take repo lock")
+		}
 	}
 
 	if err := r.loadFromDisk(); err != nil {
-		_ = r.lockfile.Close()
+		if r.lockfile != nil {
+			_ = r.lockfile.Close()
+		}
 		return nil, err
 	}
 
@@ -264,6 +289,9 @@ func (r *FSRepo) Config() *config.Config {
 
 // ReplaceConfig replaces the current config with the newly passed in one.
 func (r *FSRepo) ReplaceConfig(cfg *config.Config) error {
+	if r.readonly {
+		return ErrReadOnly
+	}
 	if err := r.SnapshotConfig(r.Config()); err != nil {
 		log.Warnf("failed to create snapshot: %s", err.Error())
 	}
@@ -360,6 +388,10 @@ func (r *FSRepo) Close() error {
 		return errors.Wrap(err, "failed to close market datastore")
 	}*/
 
+	if r.readonly {
+		return nil
+	}
+
 	if err := r.removeAPIFile(); err != nil {
 		return errors.Wrap(err, "error removing API file")
 	}
@@ -412,24 +444,112 @@ func (r *FSRepo) readVersion() (uint, error) {
 }
 
 func (r *FSRepo) openDatastore() error {
+	var ds closableBlockstore
+	var err error
 	switch r.cfg.Datastore.Type {
-	case "badgerds":
-		path := filepath.Join(r.path, r.cfg.Datastore.Path)
-		opts, err := blockstoreutil.BadgerBlockstoreOptions(path, false)
-		if err != nil {
+	case "badgerds", "pebble":
+		ds, err = r.openBlockstore(r.cfg.Datastore.Type, r.cfg.Datastore.Path)
+	case "splitstore":
+		ds, err = r.openSplitStore()
+	case "remote":
+		ds, err = r.openRemoteStore()
+	default:
+		return fmt.Errorf("unknown datastore type in config: %s", r.cfg.Datastore.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.cfg.Datastore.Convert != nil {
+		if err := r.finishConvert(context.TODO(), ds); err != nil {
+			_ = ds.Close()
 			return err
 		}
-		opts.Prefix = bstore.BlockPrefix.String()
-		ds, err := blockstoreutil.Open(opts)
+	}
+
+	if r.readonly {
+		ds = newReadonlyBlockstore(ds)
+	} else if r.cfg.Datastore.LazyState {
+		ds = blockstoreutil.NewLazyStore(ds)
+	}
+	r.ds = ds
+	return nil
+}
+
+// openBlockstore opens the blockstore of an embedded engine, "badgerds" or "pebble".
+func (r *FSRepo) openBlockstore(typ, relPath string) (closableBlockstore, error) {
+	switch typ {
+	case "badgerds":
+		return r.openBadgerBlockstore(relPath)
+	case "pebble":
+		return blockstoreutil.OpenPebble(filepath.Join(r.path, relPath), r.readonly)
+	default:
+		return nil, fmt.Errorf("unknown blockstore engine: %s", typ)
+	}
+}
+
+func (r *FSRepo) openBadgerBlockstore(relPath string) (*blockstoreutil.BadgerBlockstore, error) {
+	path := filepath.Join(r.path, relPath)
+	opts, err := blockstoreutil.BadgerBlockstoreOptions(path, r.readonly)
+	if err != nil {
+		return nil, err
+	}
+	opts.Prefix = bstore.BlockPrefix.String()
+	return blockstoreutil.Open(opts)
+}
+
+func (r *FSRepo) openRemoteStore() (*blockstoreutil.ReadThroughStore, error) {
+	cfg := r.cfg.Datastore.Remote
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("remote datastore requires a url")
+	}
+
+	local, err := r.openBadgerBlockstore(r.cfg.Datastore.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if cfg.Token != "" {
+		header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	return blockstoreutil.NewReadThroughStore(local, blockstoreutil.NewWSNetworkStore(cfg.URL, header)), nil
+}
+
+func (r *FSRepo) openSplitStore() (*blockstoreutil.SplitStore, error) {
+	cfg := r.cfg.Datastore.SplitStore
+	if cfg == nil {
+		cfg = config.NewDefaultSplitStoreConfig()
+	}
+
+	hot, err := r.openBadgerBlockstore(r.cfg.Datastore.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cold blockstoreutil.Blockstore
+	switch cfg.ColdStoreType {
+	case "badgerds":
+		cold, err = r.openBadgerBlockstore(cfg.ColdStorePath)
 		if err != nil {
-			return err
+			_ = hot.Close()
+			return nil, err
 		}
-		r.ds = ds
+	case "discard":
 	default:
-		return fmt.Errorf("unknown datastore type in config: %s", r.cfg.Datastore.Type)
+		_ = hot.Close()
+		return nil, fmt.Errorf("unknown cold store type in config: %s", cfg.ColdStoreType)
 	}
 
-	return nil
+	ss, err := blockstoreutil.NewSplitStore(hot, cold, filepath.Join(r.path, splitStoreStateFile))
+	if err != nil {
+		_ = hot.Close()
+		if closer, ok := cold.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+	return ss, nil
 }
 
 func (r *FSRepo) openKeystore() error {
@@ -446,7 +566,7 @@ func (r *FSRepo) openKeystore() error {
 }
 
 func (r *FSRepo) openChainDatastore() error {
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, chainDatastorePrefix), badgerOptions())
+	ds, err := r.openBadgerDatastore(chainDatastorePrefix)
 	if err != nil {
 		return err
 	}
@@ -457,7 +577,7 @@ func (r *FSRepo) openChainDatastore() error {
 }
 
 func (r *FSRepo) openMetaDatastore() error {
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, metaDatastorePrefix), badgerOptions())
+	ds, err := r.openBadgerDatastore(metaDatastorePrefix)
 	if err != nil {
 		return err
 	}
@@ -469,7 +589,7 @@ func (r *FSRepo) openMetaDatastore() error {
 
 func (r *FSRepo) openPaychDataStore() error {
 	var err error
-	r.paychDs, err = badgerds.NewDatastore(filepath.Join(r.path, paychDatastorePrefix), badgerOptions())
+	r.paychDs, err = r.openBadgerDatastore(paychDatastorePrefix)
 	if err != nil {
 		return err
 	}
@@ -478,7 +598,7 @@ func (r *FSRepo) openPaychDataStore() error {
 
 func (r *FSRepo) openWalletDatastore() error {
 	// TODO: read wallet datastore info from config, use that to open it up
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, walletDatastorePrefix), badgerOptions())
+	ds, err := r.openBadgerDatastore(walletDatastorePrefix)
 	if err != nil {
 		return err
 	}
@@ -488,6 +608,20 @@ func (r *FSRepo) openWalletDatastore() error {
 	return nil
 }
 
+func (r *FSRepo) openBadgerDatastore(prefix string) (Datastore, error) {
+	if !r.readonly {
+		return badgerds.NewDatastore(filepath.Join(r.path, prefix), badgerOptions())
+	}
+
+	opts := *badgerOptions()
+	opts.ReadOnly = true
+	ds, err := badgerds.NewDatastore(filepath.Join(r.path, prefix), &opts)
+	if err != nil {
+		return nil, err
+	}
+	return newReadonlyDatastore(ds), nil
+}
+
 // WriteVersion writes the given version to the repo version file.
 func WriteVersion(p string, version uint) error {
 	return os.WriteFile(filepath.Join(p, versionFilename), []byte(strconv.Itoa(int(version))), 0o644)
@@ -594,6 +728,9 @@ func fileExists(file string) (bool, error) {
 // SetAPIAddr writes the address to the API file. SetAPIAddr expects parameter
 // `port` to be of the form `:<port>`.
 func (r *FSRepo) SetAPIAddr(maddr string) error {
+	if r.readonly {
+		return ErrReadOnly
+	}
 	f, err := os.Create(filepath.Join(r.path, apiFile))
 	if err != nil {
 		return errors.Wrap(err, "could not create API file")
@@ -628,6 +765,11 @@ func (r *FSRepo) JournalPath() string {
 }
 
 func (r *FSRepo) SqlitePath() (string, error) {
+	// the sqlite databases are written by the node
+	if r.readonly {
+		return "", ErrReadOnly
+	}
+
 	r.sqlOnce.Do(func() {
 		path := filepath.Join(r.path, fsSqlite)
 
@@ -692,6 +834,9 @@ func (r *FSRepo) APIAddr() (string, error) {
 }
 
 func (r *FSRepo) SetAPIToken(token []byte) error {
+	if r.readonly {
+		return ErrReadOnly
+	}
 	return os.WriteFile(filepath.Join(r.path, apiToken), token, 0o600)
 }
 
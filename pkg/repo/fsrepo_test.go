@@ -12,11 +12,14 @@ import (
 	"testing"
 
 	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	blocks "github.com/ipfs/go-libipfs/blocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/venus/pkg/config"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
 )
 
 func TestInitRepoDirect(t *testing.T) {
@@ -125,6 +128,79 @@ func TestFSRepoRoundtrip(t *testing.T) {
 	assert.NoError(t, r2.Close())
 }
 
+func TestFSRepoSplitStore(t *testing.T) {
+	tf.UnitTest(t)
+
+	cfg := config.NewDefaultConfig()
+	cfg.Datastore.Type = "splitstore"
+	cfg.Datastore.SplitStore = config.NewDefaultSplitStoreConfig()
+
+	repoPath := path.Join(t.TempDir(), "repo")
+	assert.NoError(t, InitFSRepo(repoPath, 42, cfg))
+
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+
+	_, ok := r.Datastore().(*blockstoreutil.SplitStore)
+	assert.True(t, ok)
+	assert.DirExists(t, filepath.Join(repoPath, cfg.Datastore.SplitStore.ColdStorePath))
+	assert.NoError(t, r.Close())
+
+	cfg.Datastore.SplitStore.ColdStoreType = "unknown"
+	assert.NoError(t, r.ReplaceConfig(cfg))
+	_, err = OpenFSRepo(repoPath, 42)
+	assert.Error(t, err)
+}
+
+func TestFSRepoReadOnly(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	repoPath := path.Join(t.TempDir(), "repo")
+	assert.NoError(t, InitFSRepo(repoPath, 42, config.NewDefaultConfig()))
+
+	key := ds.NewKey("/foo")
+	blk := blocks.NewBlock([]byte("foo"))
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	require.NoError(t, r.ChainDatastore().Put(ctx, key, []byte("bar")))
+	require.NoError(t, r.Datastore().Put(ctx, blk))
+	require.NoError(t, r.Close())
+
+	r, err = OpenFSRepoReadOnly(repoPath, 42)
+	require.NoError(t, err)
+	val, err := r.ChainDatastore().Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), val)
+	has, err := r.Datastore().Has(ctx, blk.Cid())
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	// writes are only visible until the repo is closed
+	require.NoError(t, r.ChainDatastore().Put(ctx, key, []byte("baz")))
+	require.NoError(t, r.ChainDatastore().Put(ctx, ds.NewKey("/foo2"), []byte("baz")))
+	val, err = r.ChainDatastore().Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("baz"), val)
+	res, err := r.ChainDatastore().Query(ctx, query.Query{Prefix: "/"})
+	require.NoError(t, err)
+	entries, err := res.Rest()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.ErrorIs(t, r.ReplaceConfig(config.NewDefaultConfig()), ErrReadOnly)
+	require.NoError(t, r.Close())
+
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	val, err = r.ChainDatastore().Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), val)
+	has, err = r.ChainDatastore().Has(ctx, ds.NewKey("/foo2"))
+	require.NoError(t, err)
+	assert.False(t, has)
+	require.NoError(t, r.Close())
+}
+
 func TestFSRepoReplaceAndSnapshotConfig(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+)
+
+// ErrReadOnly is returned when writing to the files of a repo opened read-only.
+var ErrReadOnly = errors.New("repo is opened read-only")
+
+// readonlyBlockstore keeps the objects written to a read-only blockstore in memory.
+type readonlyBlockstore struct {
+	*blockstoreutil.BufferedBS
+	base closableBlockstore
+}
+
+func newReadonlyBlockstore(base closableBlockstore) *readonlyBlockstore {
+	return &readonlyBlockstore{
+		BufferedBS: blockstoreutil.NewTieredBstore(base, blockstoreutil.NewTemporary()),
+		base:       base,
+	}
+}
+
+func (bs *readonlyBlockstore) Close() error {
+	return bs.base.Close()
+}
+
+// readonlyDatastore keeps the entries written to a read-only datastore in memory, so the
+// components loading from the repo can update their state without touching the disk.
+type readonlyDatastore struct {
+	base Datastore
+
+	lk      sync.RWMutex
+	mem     map[datastore.Key][]byte
+	deleted map[datastore.Key]struct{}
+}
+
+var _ datastore.Batching = (*readonlyDatastore)(nil)
+
+func newReadonlyDatastore(base Datastore) *readonlyDatastore {
+	return &readonlyDatastore{
+		base:    base,
+		mem:     make(map[datastore.Key][]byte),
+		deleted: make(map[datastore.Key]struct{}),
+	}
+}
+
+// local returns the value of key written in memory, the second value is false if the key
+// has never been written or deleted in memory.
+func (d *readonlyDatastore) local(key datastore.Key) ([]byte, bool, error) {
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+
+	if _, ok := d.deleted[key]; ok {
+		return nil, true, datastore.ErrNotFound
+	}
+	val, ok := d.mem[key]
+	return val, ok, nil
+}
+
+func (d *readonlyDatastore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	if val, ok, err := d.local(key); ok {
+		return val, err
+	}
+	return d.base.Get(ctx, key)
+}
+
+func (d *readonlyDatastore) Has(ctx context.Context, key datastore.Key) (bool, error) {
+	if _, ok, err := d.local(key); ok {
+		return err == nil, nil
+	}
+	return d.base.Has(ctx, key)
+}
+
+func (d *readonlyDatastore) GetSize(ctx context.Context, key datastore.Key) (int, error) {
+	if val, ok, err := d.local(key); ok {
+		if err != nil {
+			return -1, err
+		}
+		return len(val), nil
+	}
+	return d.base.GetSize(ctx, key)
+}
+
+func (d *readonlyDatastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	delete(d.deleted, key)
+	d.mem[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (d *readonlyDatastore) Delete(ctx context.Context, key datastore.Key) error {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+
+	delete(d.mem, key)
+	d.deleted[key] = struct{}{}
+	return nil
+}
+
+// Query merges the entries of the base datastore with the ones written in memory, it reads
+// all the entries under the prefix of q before applying the query.
+func (d *readonlyDatastore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	res, err := d.base.Query(ctx, query.Query{Prefix: q.Prefix})
+	if err != nil {
+		return nil, err
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	d.lk.RLock()
+	merged := make([]query.Entry, 0, len(entries)+len(d.mem))
+	for _, e := range entries {
+		key := datastore.NewKey(e.Key)
+		if _, ok := d.deleted[key]; ok {
+			continue
+		}
+		if _, ok := d.mem[key]; ok {
+			continue
+		}
+		merged = append(merged, e)
+	}
+	for key, val := range d.mem {
+		merged = append(merged, query.Entry{Key: key.String(), Value: val, Size: len(val)})
+	}
+	d.lk.RUnlock()
+
+	return query.NaiveQueryApply(q, query.ResultsWithEntries(q, merged)), nil
+}
+
+func (d *readonlyDatastore) Sync(ctx context.Context, prefix datastore.Key) error {
+	return nil
+}
+
+func (d *readonlyDatastore) Batch(ctx context.Context) (datastore.Batch, error) {
+	return datastore.NewBasicBatch(d), nil
+}
+
+func (d *readonlyDatastore) Close() error {
+	return d.base.Close()
+}
@@ -269,13 +269,20 @@ func (s *Stmgr) RunStateTransition(ctx context.Context, ts *types.TipSet, cb vm.
 		return ts.Blocks()[0].ParentStateRoot, ts.Blocks()[0].ParentMessageReceipts, nil
 	}
 
-	if root, receipts, err = s.cp.RunStateTransition(ctx, ts, cb, vmTracing); err != nil {
+	if root, receipts, err = s.runStateTransition(ctx, ts, cb, vmTracing); err != nil {
 		return cid.Undef, cid.Undef, err
 	}
 
 	return root, receipts, nil
 }
 
+// runStateTransition computes the state of ts, a prune of the chain store doesn't delete the old
+// state meanwhile.
+func (s *Stmgr) runStateTransition(ctx context.Context, ts *types.TipSet, cb vm.ExecCallBack, vmTracing bool) (cid.Cid, cid.Cid, error) {
+	defer s.cs.LockStateWrites()()
+	return s.cp.RunStateTransition(ctx, ts, cb, vmTracing)
+}
+
 // ctx context.Context, ts *types.TipSet, addr address.Address
 func (s *Stmgr) GetActorAtTsk(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*types.Actor, error) {
 	ts, err := s.cs.GetTipSet(ctx, tsk)
@@ -356,7 +363,7 @@ func (s *Stmgr) RunStateTransitionV2(ctx context.Context, ts *types.TipSet) (cid
 		return ts.Blocks()[0].ParentStateRoot, ts.Blocks()[0].ParentMessageReceipts, nil
 	}
 
-	if state.stateRoot, state.receipt, err = s.cp.RunStateTransition(ctx, ts, nil, false); err != nil {
+	if state.stateRoot, state.receipt, err = s.runStateTransition(ctx, ts, nil, false); err != nil {
 		return cid.Undef, cid.Undef, err
 	} else if err = s.cs.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
 		TipSet:          ts,
@@ -434,7 +441,7 @@ func (s *Stmgr) Replay(ctx context.Context, ts *types.TipSet, msgCID cid.Cid) (*
 		return nil
 	}
 
-	_, _, err := s.cp.RunStateTransition(ctx, ts, cb, true)
+	_, _, err := s.runStateTransition(ctx, ts, cb, true)
 	if err != nil && !errors.Is(err, errHaltExecution) {
 		return nil, nil, fmt.Errorf("unexpected error during execution: %w", err)
 	}
@@ -469,7 +476,7 @@ func (s *Stmgr) ExecutionTrace(ctx context.Context, ts *types.TipSet) (cid.Cid,
 		return nil
 	}
 
-	st, _, err := s.cp.RunStateTransition(ctx, ts, cb, true)
+	st, _, err := s.runStateTransition(ctx, ts, cb, true)
 	if err != nil {
 		return cid.Undef, nil, err
 	}
@@ -0,0 +1,229 @@
+package statemanger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/filecoin-project/venus/pkg/consensus"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// verifyRangeChunk is the number of epochs re-executed by a worker at a time
+const verifyRangeChunk = 100
+
+// ExecuteTipSet re-executes the messages of the tipset on top of its parent state, without using
+// or updating the computed states. The new state and the receipts are written to a scratch
+// blockstore buffered on top of the node blockstore, which is returned to read them.
+func (s *Stmgr) ExecuteTipSet(ctx context.Context, ts *types.TipSet) (cid.Cid, cid.Cid, blockstoreutil.Blockstore, error) {
+	cp, ok := s.cp.(consensus.StoreStateTransformer)
+	if !ok {
+		return cid.Undef, cid.Undef, nil, fmt.Errorf("state transformer %T can't run in a scratch blockstore", s.cp)
+	}
+
+	bs := blockstoreutil.NewBufferedBstore(s.cs.Blockstore())
+	root, receipts, err := cp.RunStateTransitionInStore(ctx, ts, bs)
+	if err != nil {
+		return cid.Undef, cid.Undef, nil, err
+	}
+	return root, receipts, bs, nil
+}
+
+// VerifyRange re-executes the tipsets of the chain of head between the epochs from and to, and
+// compares the results with the state roots and the receipts recorded in their children. The
+// workers re-execute chunks of the range in parallel, each tipset from the parent state recorded
+// in the chain. It returns the lowest diverging tipset, or nil, and reports the number of epochs
+// verified so far to progress.
+func (s *Stmgr) VerifyRange(ctx context.Context,
+	head *types.TipSet,
+	from, to abi.ChainEpoch,
+	workers int,
+	progress func(verified int),
+) (*types.StateDivergence, error) {
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid range from %d to %d", from, to)
+	}
+	if to >= head.Height() {
+		return nil, fmt.Errorf("the execution of a tipset is recorded in its child, to must be lower than the head height %d", head.Height())
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		lk       sync.Mutex
+		lowest   *types.StateDivergence
+		verified int
+	)
+	// diverged returns whether a divergence was found at or below the height
+	diverged := func(h abi.ChainEpoch) bool {
+		lk.Lock()
+		defer lk.Unlock()
+		return lowest != nil && lowest.Height <= h
+	}
+
+	chunks := make(chan [2]abi.ChainEpoch)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(chunks)
+		for lo := from; lo <= to; lo += verifyRangeChunk {
+			hi := lo + verifyRangeChunk - 1
+			if hi > to {
+				hi = to
+			}
+			select {
+			case chunks <- [2]abi.ChainEpoch{lo, hi}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := 0; i < workers; i++ {
+		eg.Go(func() error {
+			for chunk := range chunks {
+				if !diverged(chunk[0]) {
+					div, err := s.verifyChunk(ctx, head, chunk[0], chunk[1], diverged)
+					if err != nil {
+						return err
+					}
+					if div != nil {
+						s.log.Warnf("execution of tipset %s at %d diverges from the chain", div.TipSet, div.Height)
+					}
+					lk.Lock()
+					if div != nil && (lowest == nil || div.Height < lowest.Height) {
+						lowest = div
+					}
+					lk.Unlock()
+				}
+
+				lk.Lock()
+				verified += int(chunk[1] - chunk[0] + 1)
+				if progress != nil {
+					progress(verified)
+				}
+				lk.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return lowest, nil
+}
+
+// verifyChunk re-executes the tipsets between the epochs lo and hi in order, and returns the first
+// diverging one. It stops once a divergence was found at or below the height of the next tipset.
+func (s *Stmgr) verifyChunk(ctx context.Context,
+	head *types.TipSet,
+	lo, hi abi.ChainEpoch,
+	diverged func(abi.ChainEpoch) bool,
+) (*types.StateDivergence, error) {
+	child, err := s.cs.GetTipSetByHeight(ctx, head, hi+1, false)
+	if err != nil {
+		return nil, fmt.Errorf("loading tipset at %d: %w", hi+1, err)
+	}
+
+	// the tipsets of the chunk with their children, highest first
+	var pairs [][2]*types.TipSet
+	for child.Height() > 0 {
+		ts, err := s.cs.GetTipSet(ctx, child.Parents())
+		if err != nil {
+			return nil, fmt.Errorf("loading parent of %s: %w", child.Key(), err)
+		}
+		if ts.Height() < lo {
+			break
+		}
+		pairs = append(pairs, [2]*types.TipSet{ts, child})
+		child = ts
+	}
+
+	for i := len(pairs) - 1; i >= 0; i-- {
+		ts, child := pairs[i][0], pairs[i][1]
+		if diverged(ts.Height()) {
+			return nil, nil
+		}
+		div, err := s.verifyTipSet(ctx, ts, child)
+		if err != nil || div != nil {
+			return div, err
+		}
+	}
+	return nil, nil
+}
+
+// verifyTipSet re-executes the tipset and returns the divergence from its child, or nil
+func (s *Stmgr) verifyTipSet(ctx context.Context, ts, child *types.TipSet) (*types.StateDivergence, error) {
+	div := &types.StateDivergence{
+		TipSet:           ts.Key(),
+		Height:           ts.Height(),
+		Child:            child.Key(),
+		ClaimedStateRoot: child.At(0).ParentStateRoot,
+		ClaimedReceipts:  child.At(0).ParentMessageReceipts,
+	}
+
+	root, receipts, bs, err := s.ExecuteTipSet(ctx, ts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		div.Error = err.Error()
+		return div, nil
+	}
+	if root.Equals(div.ClaimedStateRoot) && receipts.Equals(div.ClaimedReceipts) {
+		return nil, nil
+	}
+
+	div.ComputedStateRoot = root
+	div.ComputedReceipts = receipts
+	if !receipts.Equals(div.ClaimedReceipts) {
+		if div.ReceiptDiffs, err = s.diffReceipts(ctx, ts, receipts, div.ClaimedReceipts, bs); err != nil {
+			return nil, fmt.Errorf("comparing receipts of %s: %w", ts.Key(), err)
+		}
+	}
+	return div, nil
+}
+
+// diffReceipts compares the receipts computed in bs with the receipts recorded in the chain
+func (s *Stmgr) diffReceipts(ctx context.Context, ts *types.TipSet, computed, claimed cid.Cid, bs blockstoreutil.Blockstore) ([]types.ReceiptDiff, error) {
+	msgs, err := s.ms.MessagesForTipset(ts)
+	if err != nil {
+		return nil, err
+	}
+	computedRcpts, err := s.ms.WithBlockstore(bs).LoadReceipts(ctx, computed)
+	if err != nil {
+		return nil, err
+	}
+	claimedRcpts, err := s.ms.LoadReceipts(ctx, claimed)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(computedRcpts)
+	if len(claimedRcpts) > n {
+		n = len(claimedRcpts)
+	}
+	var diffs []types.ReceiptDiff
+	for i := 0; i < n; i++ {
+		diff := types.ReceiptDiff{Index: i}
+		if i < len(msgs) {
+			diff.Message = msgs[i].Cid()
+		}
+		if i < len(computedRcpts) {
+			diff.Computed = &computedRcpts[i]
+		}
+		if i < len(claimedRcpts) {
+			diff.Claimed = &claimedRcpts[i]
+		}
+		if diff.Computed != nil && diff.Claimed != nil && diff.Computed.Equals(diff.Claimed) {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,78 @@
+package statemanger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	blockstoreutil "github.com/filecoin-project/venus/venus-shared/blockstore"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// divergingEvaluator computes a different state and receipts for a tipset
+type divergingEvaluator struct {
+	*chain.FakeStateEvaluator
+	diverging types.TipSetKey
+	root      cid.Cid
+}
+
+func (e *divergingEvaluator) RunStateTransitionInStore(ctx context.Context, ts *types.TipSet, bs blockstoreutil.Blockstore) (cid.Cid, cid.Cid, error) {
+	if ts.Key() != e.diverging {
+		return e.FakeStateEvaluator.RunStateTransitionInStore(ctx, ts, bs)
+	}
+	receipts, err := e.MessageStore.WithBlockstore(bs).StoreReceipts(ctx, []types.MessageReceipt{{GasUsed: 1}})
+	return e.root, receipts, err
+}
+
+func TestVerifyRange(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	base := builder.AppendManyOn(ctx, 150, builder.Genesis())
+	// null rounds after the diverging tipset
+	child := builder.BuildOneOn(ctx, base, func(b *chain.BlockBuilder) {
+		b.IncHeight(2)
+	})
+	head := builder.AppendManyOn(ctx, 130, child)
+
+	eval := &divergingEvaluator{
+		FakeStateEvaluator: builder.FakeStateEvaluator(),
+		diverging:          base.Key(),
+		root:               testhelpers.CidFromString(t, "bad"),
+	}
+	stmgr := NewStateManger(builder.Store(), builder.MessageStore(), eval, nil, nil, nil, nil, false)
+
+	for _, workers := range []int{1, 4} {
+		var last int
+		div, err := stmgr.VerifyRange(ctx, head, 1, base.Height()-1, workers, func(verified int) { last = verified })
+		require.NoError(t, err)
+		assert.Nil(t, div)
+		assert.Equal(t, int(base.Height()-1), last)
+
+		div, err = stmgr.VerifyRange(ctx, head, 0, head.Height()-1, workers, nil)
+		require.NoError(t, err)
+		require.NotNil(t, div)
+		assert.Equal(t, base.Key(), div.TipSet)
+		assert.Equal(t, child.Key(), div.Child)
+		assert.Equal(t, child.At(0).ParentStateRoot, div.ClaimedStateRoot)
+		assert.Equal(t, eval.root, div.ComputedStateRoot)
+		assert.Empty(t, div.Error)
+		require.Len(t, div.ReceiptDiffs, 1)
+		assert.Nil(t, div.ReceiptDiffs[0].Claimed)
+		assert.Equal(t, int64(1), div.ReceiptDiffs[0].Computed.GasUsed)
+	}
+
+	_, err := stmgr.VerifyRange(ctx, head, 10, head.Height(), 1, nil)
+	assert.Error(t, err)
+	_, err = stmgr.VerifyRange(ctx, head, 10, abi.ChainEpoch(9), 1, nil)
+	assert.Error(t, err)
+}
@@ -115,6 +115,7 @@ func main() {
 			dir: "../pkg/chain",
 			types: []interface{}{
 				chain.TSState{},
+				chain.SnapshotDiffHeader{},
 			},
 		},
 		{
@@ -468,6 +468,34 @@ func (mr *MockFullNodeMockRecorder) Concurrent(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Concurrent", reflect.TypeOf((*MockFullNode)(nil).Concurrent), arg0)
 }
 
+// ConvertBlockstore mocks base method.
+func (m *MockFullNode) ConvertBlockstore(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertBlockstore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConvertBlockstore indicates an expected call of ConvertBlockstore.
+func (mr *MockFullNodeMockRecorder) ConvertBlockstore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertBlockstore", reflect.TypeOf((*MockFullNode)(nil).ConvertBlockstore), arg0, arg1)
+}
+
+// CreateBackup mocks base method.
+func (m *MockFullNode) CreateBackup(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBackup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBackup indicates an expected call of CreateBackup.
+func (mr *MockFullNodeMockRecorder) CreateBackup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackup", reflect.TypeOf((*MockFullNode)(nil).CreateBackup), arg0, arg1)
+}
+
 // GasBatchEstimateMessageGas mocks base method.
 func (m *MockFullNode) GasBatchEstimateMessageGas(arg0 context.Context, arg1 []*types0.EstimateMessage, arg2 uint64, arg3 types0.TipSetKey) ([]*types0.EstimateResult, error) {
 	m.ctrl.T.Helper()
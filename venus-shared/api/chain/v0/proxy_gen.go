@@ -777,11 +777,19 @@ func (s *IWalletStruct) WalletState(p0 context.Context) int { return s.Internal.
 
 type ICommonStruct struct {
 	Internal struct {
-		StartTime func(context.Context) (time.Time, error)         `perm:"read"`
-		Version   func(ctx context.Context) (types.Version, error) `perm:"read"`
+		ConvertBlockstore func(ctx context.Context, engine string) error   `perm:"admin"`
+		CreateBackup      func(ctx context.Context, fpath string) error    `perm:"admin"`
+		StartTime         func(context.Context) (time.Time, error)         `perm:"read"`
+		Version           func(ctx context.Context) (types.Version, error) `perm:"read"`
 	}
 }
 
+func (s *ICommonStruct) ConvertBlockstore(p0 context.Context, p1 string) error {
+	return s.Internal.ConvertBlockstore(p0, p1)
+}
+func (s *ICommonStruct) CreateBackup(p0 context.Context, p1 string) error {
+	return s.Internal.CreateBackup(p0, p1)
+}
 func (s *ICommonStruct) StartTime(p0 context.Context) (time.Time, error) {
 	return s.Internal.StartTime(p0)
 }
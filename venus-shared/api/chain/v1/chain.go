@@ -105,6 +105,29 @@ type IChainInfo interface {
 	VerifyEntry(parent, child *types.BeaconEntry, height abi.ChainEpoch) bool                                                             //perm:read
 	ChainExport(context.Context, abi.ChainEpoch, bool, types.TipSetKey) (<-chan []byte, error)                                            //perm:read
 	ChainGetPath(ctx context.Context, from types.TipSetKey, to types.TipSetKey) ([]*types.HeadChange, error)                              //perm:read
+	// ChainExportDiff streams a car file holding the objects reachable from the chain of tsk above
+	// base which are not reachable from base, the root of the car file records both tipsets. The
+	// snapshot is imported on top of a repo whose head is base, base must be an ancestor of tsk.
+	ChainExportDiff(ctx context.Context, base, tsk types.TipSetKey) (<-chan []byte, error) //perm:read
+	// ChainPrune deletes from the blockstore the state trees and receipts older than the last opts.RetainState
+	// epochs which are not reachable from the head, keeping all the headers and messages, then runs the
+	// garbage collection of the blockstore. The headers and messages of the side forks are kept too, and the
+	// state computations wait while the old state is deleted. The progress is streamed until the prune is done.
+	ChainPrune(ctx context.Context, opts types.ChainPruneOpts) (<-chan types.ChainPruneProgress, error) //perm:admin
+	// ChainBackfillMsgIndex indexes the messages of the canonical chain from the epoch from down to
+	// the epoch to, and returns the number of indexed tipsets. The message index must be enabled.
+	ChainBackfillMsgIndex(ctx context.Context, from, to abi.ChainEpoch) (int, error) //perm:admin
+	// ChainCheck verifies that the objects referenced by the chain of tsk in the last opts.Epochs
+	// epochs are present in the blockstore and match their cid, the missing or corrupt objects are
+	// fetched from the network if opts.Repair is set.
+	ChainCheck(ctx context.Context, tsk types.TipSetKey, opts types.ChainCheckOpts) (*types.ChainCheckResult, error) //perm:admin
+	// ChainVerifyRange re-executes the tipsets of the canonical chain between the epochs opts.From and opts.To
+	// in a scratch blockstore, and compares the results with the state roots and the receipts recorded in
+	// the chain. The progress is streamed until the lowest diverging tipset, if any, is reported.
+	ChainVerifyRange(ctx context.Context, opts types.ChainVerifyRangeOpts) (<-chan types.ChainVerifyRangeProgress, error) //perm:admin
+	// ChainReorgHistory returns the last limit reorgs of the chain recorded by the node, the latest first.
+	// All the recorded reorgs are returned if limit is not positive.
+	ChainReorgHistory(ctx context.Context, limit int) ([]*types.ReorgEvent, error) //perm:read
 	// StateGetNetworkParams return current network params
 	StateGetNetworkParams(ctx context.Context) (*types.NetworkParams, error) //perm:read
 	// StateActorCodeCIDs returns the CIDs of all the builtin actors for the given network version
@@ -14,4 +14,10 @@ type ICommon interface {
 	NodeStatus(ctx context.Context, inclChainStatus bool) (types.NodeStatus, error) //perm:read
 	// StartTime returns node start time
 	StartTime(context.Context) (time.Time, error) //perm:read
+	// CreateBackup writes a backup of the repo metadata to the file at fpath, the path must be
+	// absolute and the file must not exist
+	CreateBackup(ctx context.Context, fpath string) error //perm:admin
+	// ConvertBlockstore copies the blockstore into a new blockstore of the given engine, "badgerds"
+	// or "pebble", which is used after the daemon is restarted
+	ConvertBlockstore(ctx context.Context, engine string) error //perm:admin
 }
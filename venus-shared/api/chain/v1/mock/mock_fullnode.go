@@ -70,6 +70,36 @@ func (mr *MockFullNodeMockRecorder) BlockTime(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockTime", reflect.TypeOf((*MockFullNode)(nil).BlockTime), arg0)
 }
 
+// ChainBackfillMsgIndex mocks base method.
+func (m *MockFullNode) ChainBackfillMsgIndex(arg0 context.Context, arg1, arg2 abi.ChainEpoch) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainBackfillMsgIndex", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainBackfillMsgIndex indicates an expected call of ChainBackfillMsgIndex.
+func (mr *MockFullNodeMockRecorder) ChainBackfillMsgIndex(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainBackfillMsgIndex", reflect.TypeOf((*MockFullNode)(nil).ChainBackfillMsgIndex), arg0, arg1, arg2)
+}
+
+// ChainCheck mocks base method.
+func (m *MockFullNode) ChainCheck(arg0 context.Context, arg1 types0.TipSetKey, arg2 types0.ChainCheckOpts) (*types0.ChainCheckResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainCheck", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*types0.ChainCheckResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainCheck indicates an expected call of ChainCheck.
+func (mr *MockFullNodeMockRecorder) ChainCheck(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainCheck", reflect.TypeOf((*MockFullNode)(nil).ChainCheck), arg0, arg1, arg2)
+}
+
 // ChainDeleteObj mocks base method.
 func (m *MockFullNode) ChainDeleteObj(arg0 context.Context, arg1 cid.Cid) error {
 	m.ctrl.T.Helper()
@@ -99,6 +129,21 @@ func (mr *MockFullNodeMockRecorder) ChainExport(arg0, arg1, arg2, arg3 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExport", reflect.TypeOf((*MockFullNode)(nil).ChainExport), arg0, arg1, arg2, arg3)
 }
 
+// ChainExportDiff mocks base method.
+func (m *MockFullNode) ChainExportDiff(arg0 context.Context, arg1, arg2 types0.TipSetKey) (<-chan []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainExportDiff", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan []byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainExportDiff indicates an expected call of ChainExportDiff.
+func (mr *MockFullNodeMockRecorder) ChainExportDiff(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExportDiff", reflect.TypeOf((*MockFullNode)(nil).ChainExportDiff), arg0, arg1, arg2)
+}
+
 // ChainGetBlock mocks base method.
 func (m *MockFullNode) ChainGetBlock(arg0 context.Context, arg1 cid.Cid) (*types0.BlockHeader, error) {
 	m.ctrl.T.Helper()
@@ -354,6 +399,21 @@ func (mr *MockFullNodeMockRecorder) ChainNotify(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainNotify", reflect.TypeOf((*MockFullNode)(nil).ChainNotify), arg0)
 }
 
+// ChainPrune mocks base method.
+func (m *MockFullNode) ChainPrune(arg0 context.Context, arg1 types0.ChainPruneOpts) (<-chan types0.ChainPruneProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainPrune", arg0, arg1)
+	ret0, _ := ret[0].(<-chan types0.ChainPruneProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainPrune indicates an expected call of ChainPrune.
+func (mr *MockFullNodeMockRecorder) ChainPrune(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainPrune", reflect.TypeOf((*MockFullNode)(nil).ChainPrune), arg0, arg1)
+}
+
 // ChainPutObj mocks base method.
 func (m *MockFullNode) ChainPutObj(arg0 context.Context, arg1 blocks.Block) error {
 	m.ctrl.T.Helper()
@@ -383,6 +443,21 @@ func (mr *MockFullNodeMockRecorder) ChainReadObj(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainReadObj", reflect.TypeOf((*MockFullNode)(nil).ChainReadObj), arg0, arg1)
 }
 
+// ChainReorgHistory mocks base method.
+func (m *MockFullNode) ChainReorgHistory(arg0 context.Context, arg1 int) ([]*types0.ReorgEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainReorgHistory", arg0, arg1)
+	ret0, _ := ret[0].([]*types0.ReorgEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainReorgHistory indicates an expected call of ChainReorgHistory.
+func (mr *MockFullNodeMockRecorder) ChainReorgHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainReorgHistory", reflect.TypeOf((*MockFullNode)(nil).ChainReorgHistory), arg0, arg1)
+}
+
 // ChainSetHead mocks base method.
 func (m *MockFullNode) ChainSetHead(arg0 context.Context, arg1 types0.TipSetKey) error {
 	m.ctrl.T.Helper()
@@ -441,6 +516,21 @@ func (mr *MockFullNodeMockRecorder) ChainTipSetWeight(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainTipSetWeight", reflect.TypeOf((*MockFullNode)(nil).ChainTipSetWeight), arg0, arg1)
 }
 
+// ChainVerifyRange mocks base method.
+func (m *MockFullNode) ChainVerifyRange(arg0 context.Context, arg1 types0.ChainVerifyRangeOpts) (<-chan types0.ChainVerifyRangeProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainVerifyRange", arg0, arg1)
+	ret0, _ := ret[0].(<-chan types0.ChainVerifyRangeProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainVerifyRange indicates an expected call of ChainVerifyRange.
+func (mr *MockFullNodeMockRecorder) ChainVerifyRange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainVerifyRange", reflect.TypeOf((*MockFullNode)(nil).ChainVerifyRange), arg0, arg1)
+}
+
 // Concurrent mocks base method.
 func (m *MockFullNode) Concurrent(arg0 context.Context) int64 {
 	m.ctrl.T.Helper()
@@ -455,6 +545,34 @@ func (mr *MockFullNodeMockRecorder) Concurrent(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Concurrent", reflect.TypeOf((*MockFullNode)(nil).Concurrent), arg0)
 }
 
+// ConvertBlockstore mocks base method.
+func (m *MockFullNode) ConvertBlockstore(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertBlockstore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConvertBlockstore indicates an expected call of ConvertBlockstore.
+func (mr *MockFullNodeMockRecorder) ConvertBlockstore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertBlockstore", reflect.TypeOf((*MockFullNode)(nil).ConvertBlockstore), arg0, arg1)
+}
+
+// CreateBackup mocks base method.
+func (m *MockFullNode) CreateBackup(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBackup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBackup indicates an expected call of CreateBackup.
+func (mr *MockFullNodeMockRecorder) CreateBackup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackup", reflect.TypeOf((*MockFullNode)(nil).CreateBackup), arg0, arg1)
+}
+
 // EthAccounts mocks base method.
 func (m *MockFullNode) EthAccounts(arg0 context.Context) ([]types.EthAddress, error) {
 	m.ctrl.T.Helper()
@@ -1010,6 +1128,21 @@ func (mr *MockFullNodeMockRecorder) FilecoinAddressToEthAddress(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilecoinAddressToEthAddress", reflect.TypeOf((*MockFullNode)(nil).FilecoinAddressToEthAddress), arg0, arg1)
 }
 
+// GasBacktestPremium mocks base method.
+func (m *MockFullNode) GasBacktestPremium(arg0 context.Context, arg1 []string, arg2 uint64, arg3 int) ([]*types0.GasBacktestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasBacktestPremium", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*types0.GasBacktestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GasBacktestPremium indicates an expected call of GasBacktestPremium.
+func (mr *MockFullNodeMockRecorder) GasBacktestPremium(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasBacktestPremium", reflect.TypeOf((*MockFullNode)(nil).GasBacktestPremium), arg0, arg1, arg2, arg3)
+}
+
 // GasBatchEstimateMessageGas mocks base method.
 func (m *MockFullNode) GasBatchEstimateMessageGas(arg0 context.Context, arg1 []*types0.EstimateMessage, arg2 uint64, arg3 types0.TipSetKey) ([]*types0.EstimateResult, error) {
 	m.ctrl.T.Helper()
@@ -1351,6 +1484,21 @@ func (mr *MockFullNodeMockRecorder) MpoolDeleteByAdress(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolDeleteByAdress", reflect.TypeOf((*MockFullNode)(nil).MpoolDeleteByAdress), arg0, arg1)
 }
 
+// MpoolFixNonceGaps mocks base method.
+func (m *MockFullNode) MpoolFixNonceGaps(arg0 context.Context, arg1 address.Address, arg2, arg3 bool) (*types0.NonceGapFix, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolFixNonceGaps", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*types0.NonceGapFix)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolFixNonceGaps indicates an expected call of MpoolFixNonceGaps.
+func (mr *MockFullNodeMockRecorder) MpoolFixNonceGaps(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolFixNonceGaps", reflect.TypeOf((*MockFullNode)(nil).MpoolFixNonceGaps), arg0, arg1, arg2, arg3)
+}
+
 // MpoolGetConfig mocks base method.
 func (m *MockFullNode) MpoolGetConfig(arg0 context.Context) (*types0.MpoolConfig, error) {
 	m.ctrl.T.Helper()
@@ -1381,6 +1529,21 @@ func (mr *MockFullNodeMockRecorder) MpoolGetNonce(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolGetNonce", reflect.TypeOf((*MockFullNode)(nil).MpoolGetNonce), arg0, arg1)
 }
 
+// MpoolNonceGaps mocks base method.
+func (m *MockFullNode) MpoolNonceGaps(arg0 context.Context, arg1 address.Address) (*types0.NonceGapReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolNonceGaps", arg0, arg1)
+	ret0, _ := ret[0].(*types0.NonceGapReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolNonceGaps indicates an expected call of MpoolNonceGaps.
+func (mr *MockFullNodeMockRecorder) MpoolNonceGaps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolNonceGaps", reflect.TypeOf((*MockFullNode)(nil).MpoolNonceGaps), arg0, arg1)
+}
+
 // MpoolPending mocks base method.
 func (m *MockFullNode) MpoolPending(arg0 context.Context, arg1 types0.TipSetKey) ([]*types.SignedMessage, error) {
 	m.ctrl.T.Helper()
@@ -1484,6 +1647,21 @@ func (mr *MockFullNodeMockRecorder) MpoolSelect(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolSelect", reflect.TypeOf((*MockFullNode)(nil).MpoolSelect), arg0, arg1, arg2)
 }
 
+// MpoolSelectExplain mocks base method.
+func (m *MockFullNode) MpoolSelectExplain(arg0 context.Context, arg1 types0.TipSetKey, arg2 float64) (*types0.MpoolSelectExplain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolSelectExplain", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*types0.MpoolSelectExplain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolSelectExplain indicates an expected call of MpoolSelectExplain.
+func (mr *MockFullNodeMockRecorder) MpoolSelectExplain(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolSelectExplain", reflect.TypeOf((*MockFullNode)(nil).MpoolSelectExplain), arg0, arg1, arg2)
+}
+
 // MpoolSelects mocks base method.
 func (m *MockFullNode) MpoolSelects(arg0 context.Context, arg1 types0.TipSetKey, arg2 []float64) ([][]*types.SignedMessage, error) {
 	m.ctrl.T.Helper()
@@ -2165,6 +2343,36 @@ func (mr *MockFullNodeMockRecorder) SetPassword(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockFullNode)(nil).SetPassword), arg0, arg1)
 }
 
+// SlashFilterCheckBlock mocks base method.
+func (m *MockFullNode) SlashFilterCheckBlock(arg0 context.Context, arg1 cid.Cid) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlashFilterCheckBlock", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlashFilterCheckBlock indicates an expected call of SlashFilterCheckBlock.
+func (mr *MockFullNodeMockRecorder) SlashFilterCheckBlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlashFilterCheckBlock", reflect.TypeOf((*MockFullNode)(nil).SlashFilterCheckBlock), arg0, arg1)
+}
+
+// SlashFilterListBlocks mocks base method.
+func (m *MockFullNode) SlashFilterListBlocks(arg0 context.Context, arg1 address.Address, arg2, arg3 abi.ChainEpoch) ([]*types0.MinedBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlashFilterListBlocks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*types0.MinedBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlashFilterListBlocks indicates an expected call of SlashFilterListBlocks.
+func (mr *MockFullNodeMockRecorder) SlashFilterListBlocks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlashFilterListBlocks", reflect.TypeOf((*MockFullNode)(nil).SlashFilterListBlocks), arg0, arg1, arg2, arg3)
+}
+
 // StartTime mocks base method.
 func (m *MockFullNode) StartTime(arg0 context.Context) (time.Time, error) {
 	m.ctrl.T.Helper()
@@ -3110,6 +3318,64 @@ func (mr *MockFullNodeMockRecorder) StateWaitMsg(arg0, arg1, arg2, arg3, arg4 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateWaitMsg", reflect.TypeOf((*MockFullNode)(nil).StateWaitMsg), arg0, arg1, arg2, arg3, arg4)
 }
 
+// SyncCheckBad mocks base method.
+func (m *MockFullNode) SyncCheckBad(arg0 context.Context, arg1 types0.TipSetKey) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCheckBad", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncCheckBad indicates an expected call of SyncCheckBad.
+func (mr *MockFullNodeMockRecorder) SyncCheckBad(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCheckBad", reflect.TypeOf((*MockFullNode)(nil).SyncCheckBad), arg0, arg1)
+}
+
+// SyncCheckpoint mocks base method.
+func (m *MockFullNode) SyncCheckpoint(arg0 context.Context, arg1 types0.TipSetKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCheckpoint", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncCheckpoint indicates an expected call of SyncCheckpoint.
+func (mr *MockFullNodeMockRecorder) SyncCheckpoint(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCheckpoint", reflect.TypeOf((*MockFullNode)(nil).SyncCheckpoint), arg0, arg1)
+}
+
+// SyncConsensusFaults mocks base method.
+func (m *MockFullNode) SyncConsensusFaults(arg0 context.Context) ([]*types0.ConsensusFault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncConsensusFaults", arg0)
+	ret0, _ := ret[0].([]*types0.ConsensusFault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncConsensusFaults indicates an expected call of SyncConsensusFaults.
+func (mr *MockFullNodeMockRecorder) SyncConsensusFaults(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncConsensusFaults", reflect.TypeOf((*MockFullNode)(nil).SyncConsensusFaults), arg0)
+}
+
+// SyncMarkBad mocks base method.
+func (m *MockFullNode) SyncMarkBad(arg0 context.Context, arg1 types0.TipSetKey, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncMarkBad", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncMarkBad indicates an expected call of SyncMarkBad.
+func (mr *MockFullNodeMockRecorder) SyncMarkBad(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncMarkBad", reflect.TypeOf((*MockFullNode)(nil).SyncMarkBad), arg0, arg1, arg2)
+}
+
 // SyncState mocks base method.
 func (m *MockFullNode) SyncState(arg0 context.Context) (*types0.SyncState, error) {
 	m.ctrl.T.Helper()
@@ -3139,6 +3405,50 @@ func (mr *MockFullNodeMockRecorder) SyncSubmitBlock(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncSubmitBlock", reflect.TypeOf((*MockFullNode)(nil).SyncSubmitBlock), arg0, arg1)
 }
 
+// SyncTargets mocks base method.
+func (m *MockFullNode) SyncTargets(arg0 context.Context) ([]*types0.SyncTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncTargets", arg0)
+	ret0, _ := ret[0].([]*types0.SyncTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncTargets indicates an expected call of SyncTargets.
+func (mr *MockFullNodeMockRecorder) SyncTargets(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncTargets", reflect.TypeOf((*MockFullNode)(nil).SyncTargets), arg0)
+}
+
+// SyncUnmarkBad mocks base method.
+func (m *MockFullNode) SyncUnmarkBad(arg0 context.Context, arg1 types0.TipSetKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncUnmarkBad", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncUnmarkBad indicates an expected call of SyncUnmarkBad.
+func (mr *MockFullNodeMockRecorder) SyncUnmarkBad(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncUnmarkBad", reflect.TypeOf((*MockFullNode)(nil).SyncUnmarkBad), arg0, arg1)
+}
+
+// SyncValidateTipset mocks base method.
+func (m *MockFullNode) SyncValidateTipset(arg0 context.Context, arg1 types0.TipSetKey) (*types0.TipSetValidation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncValidateTipset", arg0, arg1)
+	ret0, _ := ret[0].(*types0.TipSetValidation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncValidateTipset indicates an expected call of SyncValidateTipset.
+func (mr *MockFullNodeMockRecorder) SyncValidateTipset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncValidateTipset", reflect.TypeOf((*MockFullNode)(nil).SyncValidateTipset), arg0, arg1)
+}
+
 // SyncerTracker mocks base method.
 func (m *MockFullNode) SyncerTracker(arg0 context.Context) *types0.TargetTracker {
 	m.ctrl.T.Helper()
@@ -39,4 +39,16 @@ type IMessagePool interface {
 	MpoolCheckPendingMessages(ctx context.Context, addr address.Address) ([][]types.MessageCheckStatus, error) //perm:read
 	// MpoolCheckReplaceMessages performs logical checks on pending messages with replacement
 	MpoolCheckReplaceMessages(ctx context.Context, msg []*types.Message) ([][]types.MessageCheckStatus, error) //perm:read
+	// MpoolSelectExplain runs the message selection of MpoolSelect, and explains for each pending message whether it
+	// was selected or why not, with the gas performance and the effective performance of its chain
+	MpoolSelectExplain(ctx context.Context, tsk types.TipSetKey, ticketQuality float64) (*types.MpoolSelectExplain, error) //perm:read
+	// MpoolNonceGaps compares the nonces of an address in the state, the message pool, the local messages and the message signer
+	MpoolNonceGaps(ctx context.Context, addr address.Address) (*types.NonceGapReport, error) //perm:read
+	// MpoolFixNonceGaps fills the nonce gaps of an address with zero value self-sends if fill is set, and drops the
+	// messages stranded behind the gaps and the stale local messages if drop is set
+	MpoolFixNonceGaps(ctx context.Context, addr address.Address, fill, drop bool) (*types.NonceGapFix, error) //perm:sign
+	// GasBacktestPremium replays the last epochs tipsets with each of the gas premium estimators, all of them if none
+	// is given, and reports how long the premiums estimated for an inclusion within nblocksincl epochs waited and overpaid.
+	// epochs is capped to three finalities and nblocksincl to a tenth of a finality
+	GasBacktestPremium(ctx context.Context, estimators []string, nblocksincl uint64, epochs int) ([]*types.GasBacktestResult, error) //perm:read
 }
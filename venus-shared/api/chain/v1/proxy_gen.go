@@ -260,7 +260,10 @@ func (s *IMinerStateStruct) StateVerifiedClientStatus(p0 context.Context, p1 add
 type IChainInfoStruct struct {
 	Internal struct {
 		BlockTime                     func(ctx context.Context) time.Duration                                                                                                                      `perm:"read"`
+		ChainBackfillMsgIndex         func(ctx context.Context, from, to abi.ChainEpoch) (int, error)                                                                                              `perm:"admin"`
+		ChainCheck                    func(ctx context.Context, tsk types.TipSetKey, opts types.ChainCheckOpts) (*types.ChainCheckResult, error)                                                   `perm:"admin"`
 		ChainExport                   func(context.Context, abi.ChainEpoch, bool, types.TipSetKey) (<-chan []byte, error)                                                                          `perm:"read"`
+		ChainExportDiff               func(ctx context.Context, base, tsk types.TipSetKey) (<-chan []byte, error)                                                                                  `perm:"read"`
 		ChainGetBlock                 func(ctx context.Context, id cid.Cid) (*types.BlockHeader, error)                                                                                            `perm:"read"`
 		ChainGetBlockMessages         func(ctx context.Context, bid cid.Cid) (*types.BlockMessages, error)                                                                                         `perm:"read"`
 		ChainGetEvents                func(context.Context, cid.Cid) ([]types.Event, error)                                                                                                        `perm:"read"`
@@ -277,7 +280,10 @@ type IChainInfoStruct struct {
 		ChainHead                     func(ctx context.Context) (*types.TipSet, error)                                                                                                             `perm:"read"`
 		ChainList                     func(ctx context.Context, tsKey types.TipSetKey, count int) ([]types.TipSetKey, error)                                                                       `perm:"read"`
 		ChainNotify                   func(ctx context.Context) (<-chan []*types.HeadChange, error)                                                                                                `perm:"read"`
+		ChainPrune                    func(ctx context.Context, opts types.ChainPruneOpts) (<-chan types.ChainPruneProgress, error)                                                                `perm:"admin"`
+		ChainReorgHistory             func(ctx context.Context, limit int) ([]*types.ReorgEvent, error)                                                                                            `perm:"read"`
 		ChainSetHead                  func(ctx context.Context, key types.TipSetKey) error                                                                                                         `perm:"admin"`
+		ChainVerifyRange              func(ctx context.Context, opts types.ChainVerifyRangeOpts) (<-chan types.ChainVerifyRangeProgress, error)                                                    `perm:"admin"`
 		GetActor                      func(ctx context.Context, addr address.Address) (*types.Actor, error)                                                                                        `perm:"read"`
 		GetEntry                      func(ctx context.Context, height abi.ChainEpoch, round uint64) (*types.BeaconEntry, error)                                                                   `perm:"read"`
 		GetFullBlock                  func(ctx context.Context, id cid.Cid) (*types.FullBlock, error)                                                                                              `perm:"read"`
@@ -306,9 +312,18 @@ type IChainInfoStruct struct {
 func (s *IChainInfoStruct) BlockTime(p0 context.Context) time.Duration {
 	return s.Internal.BlockTime(p0)
 }
+func (s *IChainInfoStruct) ChainBackfillMsgIndex(p0 context.Context, p1, p2 abi.ChainEpoch) (int, error) {
+	return s.Internal.ChainBackfillMsgIndex(p0, p1, p2)
+}
+func (s *IChainInfoStruct) ChainCheck(p0 context.Context, p1 types.TipSetKey, p2 types.ChainCheckOpts) (*types.ChainCheckResult, error) {
+	return s.Internal.ChainCheck(p0, p1, p2)
+}
 func (s *IChainInfoStruct) ChainExport(p0 context.Context, p1 abi.ChainEpoch, p2 bool, p3 types.TipSetKey) (<-chan []byte, error) {
 	return s.Internal.ChainExport(p0, p1, p2, p3)
 }
+func (s *IChainInfoStruct) ChainExportDiff(p0 context.Context, p1, p2 types.TipSetKey) (<-chan []byte, error) {
+	return s.Internal.ChainExportDiff(p0, p1, p2)
+}
 func (s *IChainInfoStruct) ChainGetBlock(p0 context.Context, p1 cid.Cid) (*types.BlockHeader, error) {
 	return s.Internal.ChainGetBlock(p0, p1)
 }
@@ -357,9 +372,18 @@ func (s *IChainInfoStruct) ChainList(p0 context.Context, p1 types.TipSetKey, p2
 func (s *IChainInfoStruct) ChainNotify(p0 context.Context) (<-chan []*types.HeadChange, error) {
 	return s.Internal.ChainNotify(p0)
 }
+func (s *IChainInfoStruct) ChainPrune(p0 context.Context, p1 types.ChainPruneOpts) (<-chan types.ChainPruneProgress, error) {
+	return s.Internal.ChainPrune(p0, p1)
+}
+func (s *IChainInfoStruct) ChainReorgHistory(p0 context.Context, p1 int) ([]*types.ReorgEvent, error) {
+	return s.Internal.ChainReorgHistory(p0, p1)
+}
 func (s *IChainInfoStruct) ChainSetHead(p0 context.Context, p1 types.TipSetKey) error {
 	return s.Internal.ChainSetHead(p0, p1)
 }
+func (s *IChainInfoStruct) ChainVerifyRange(p0 context.Context, p1 types.ChainVerifyRangeOpts) (<-chan types.ChainVerifyRangeProgress, error) {
+	return s.Internal.ChainVerifyRange(p0, p1)
+}
 func (s *IChainInfoStruct) GetActor(p0 context.Context, p1 address.Address) (*types.Actor, error) {
 	return s.Internal.GetActor(p0, p1)
 }
@@ -460,6 +484,7 @@ func (s *IMiningStruct) MinerGetBaseInfo(p0 context.Context, p1 address.Address,
 
 type IMessagePoolStruct struct {
 	Internal struct {
+		GasBacktestPremium         func(ctx context.Context, estimators []string, nblocksincl uint64, epochs int) ([]*types.GasBacktestResult, error)                           `perm:"read"`
 		GasBatchEstimateMessageGas func(ctx context.Context, estimateMessages []*types.EstimateMessage, fromNonce uint64, tsk types.TipSetKey) ([]*types.EstimateResult, error) `perm:"read"`
 		GasEstimateFeeCap          func(ctx context.Context, msg *types.Message, maxqueueblks int64, tsk types.TipSetKey) (big.Int, error)                                      `perm:"read"`
 		GasEstimateGasLimit        func(ctx context.Context, msgIn *types.Message, tsk types.TipSetKey) (int64, error)                                                          `perm:"read"`
@@ -473,8 +498,10 @@ type IMessagePoolStruct struct {
 		MpoolCheckReplaceMessages  func(ctx context.Context, msg []*types.Message) ([][]types.MessageCheckStatus, error)                                                        `perm:"read"`
 		MpoolClear                 func(ctx context.Context, local bool) error                                                                                                  `perm:"write"`
 		MpoolDeleteByAdress        func(ctx context.Context, addr address.Address) error                                                                                        `perm:"admin"`
+		MpoolFixNonceGaps          func(ctx context.Context, addr address.Address, fill, drop bool) (*types.NonceGapFix, error)                                                 `perm:"sign"`
 		MpoolGetConfig             func(context.Context) (*types.MpoolConfig, error)                                                                                            `perm:"read"`
 		MpoolGetNonce              func(ctx context.Context, addr address.Address) (uint64, error)                                                                              `perm:"read"`
+		MpoolNonceGaps             func(ctx context.Context, addr address.Address) (*types.NonceGapReport, error)                                                               `perm:"read"`
 		MpoolPending               func(ctx context.Context, tsk types.TipSetKey) ([]*types.SignedMessage, error)                                                               `perm:"read"`
 		MpoolPublishByAddr         func(context.Context, address.Address) error                                                                                                 `perm:"write"`
 		MpoolPublishMessage        func(ctx context.Context, smsg *types.SignedMessage) error                                                                                   `perm:"write"`
@@ -482,12 +509,16 @@ type IMessagePoolStruct struct {
 		MpoolPushMessage           func(ctx context.Context, msg *types.Message, spec *types.MessageSendSpec) (*types.SignedMessage, error)                                     `perm:"sign"`
 		MpoolPushUntrusted         func(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error)                                                                        `perm:"write"`
 		MpoolSelect                func(context.Context, types.TipSetKey, float64) ([]*types.SignedMessage, error)                                                              `perm:"read"`
+		MpoolSelectExplain         func(ctx context.Context, tsk types.TipSetKey, ticketQuality float64) (*types.MpoolSelectExplain, error)                                     `perm:"read"`
 		MpoolSelects               func(context.Context, types.TipSetKey, []float64) ([][]*types.SignedMessage, error)                                                          `perm:"read"`
 		MpoolSetConfig             func(ctx context.Context, cfg *types.MpoolConfig) error                                                                                      `perm:"admin"`
 		MpoolSub                   func(ctx context.Context) (<-chan types.MpoolUpdate, error)                                                                                  `perm:"read"`
 	}
 }
 
+func (s *IMessagePoolStruct) GasBacktestPremium(p0 context.Context, p1 []string, p2 uint64, p3 int) ([]*types.GasBacktestResult, error) {
+	return s.Internal.GasBacktestPremium(p0, p1, p2, p3)
+}
 func (s *IMessagePoolStruct) GasBatchEstimateMessageGas(p0 context.Context, p1 []*types.EstimateMessage, p2 uint64, p3 types.TipSetKey) ([]*types.EstimateResult, error) {
 	return s.Internal.GasBatchEstimateMessageGas(p0, p1, p2, p3)
 }
@@ -527,12 +558,18 @@ func (s *IMessagePoolStruct) MpoolClear(p0 context.Context, p1 bool) error {
 func (s *IMessagePoolStruct) MpoolDeleteByAdress(p0 context.Context, p1 address.Address) error {
 	return s.Internal.MpoolDeleteByAdress(p0, p1)
 }
+func (s *IMessagePoolStruct) MpoolFixNonceGaps(p0 context.Context, p1 address.Address, p2, p3 bool) (*types.NonceGapFix, error) {
+	return s.Internal.MpoolFixNonceGaps(p0, p1, p2, p3)
+}
 func (s *IMessagePoolStruct) MpoolGetConfig(p0 context.Context) (*types.MpoolConfig, error) {
 	return s.Internal.MpoolGetConfig(p0)
 }
 func (s *IMessagePoolStruct) MpoolGetNonce(p0 context.Context, p1 address.Address) (uint64, error) {
 	return s.Internal.MpoolGetNonce(p0, p1)
 }
+func (s *IMessagePoolStruct) MpoolNonceGaps(p0 context.Context, p1 address.Address) (*types.NonceGapReport, error) {
+	return s.Internal.MpoolNonceGaps(p0, p1)
+}
 func (s *IMessagePoolStruct) MpoolPending(p0 context.Context, p1 types.TipSetKey) ([]*types.SignedMessage, error) {
 	return s.Internal.MpoolPending(p0, p1)
 }
@@ -554,6 +591,9 @@ func (s *IMessagePoolStruct) MpoolPushUntrusted(p0 context.Context, p1 *types.Si
 func (s *IMessagePoolStruct) MpoolSelect(p0 context.Context, p1 types.TipSetKey, p2 float64) ([]*types.SignedMessage, error) {
 	return s.Internal.MpoolSelect(p0, p1, p2)
 }
+func (s *IMessagePoolStruct) MpoolSelectExplain(p0 context.Context, p1 types.TipSetKey, p2 float64) (*types.MpoolSelectExplain, error) {
+	return s.Internal.MpoolSelectExplain(p0, p1, p2)
+}
 func (s *IMessagePoolStruct) MpoolSelects(p0 context.Context, p1 types.TipSetKey, p2 []float64) ([][]*types.SignedMessage, error) {
 	return s.Internal.MpoolSelects(p0, p1, p2)
 }
@@ -724,13 +764,22 @@ func (s *IPaychanStruct) PaychVoucherSubmit(p0 context.Context, p1 address.Addre
 
 type ISyncerStruct struct {
 	Internal struct {
-		ChainSyncHandleNewTipSet func(ctx context.Context, ci *types.ChainInfo) error            `perm:"write"`
-		ChainTipSetWeight        func(ctx context.Context, tsk types.TipSetKey) (big.Int, error) `perm:"read"`
-		Concurrent               func(ctx context.Context) int64                                 `perm:"read"`
-		SetConcurrent            func(ctx context.Context, concurrent int64) error               `perm:"admin"`
-		SyncState                func(ctx context.Context) (*types.SyncState, error)             `perm:"read"`
-		SyncSubmitBlock          func(ctx context.Context, blk *types.BlockMsg) error            `perm:"write"`
-		SyncerTracker            func(ctx context.Context) *types.TargetTracker                  `perm:"read"`
+		ChainSyncHandleNewTipSet func(ctx context.Context, ci *types.ChainInfo) error                                                   `perm:"write"`
+		ChainTipSetWeight        func(ctx context.Context, tsk types.TipSetKey) (big.Int, error)                                        `perm:"read"`
+		Concurrent               func(ctx context.Context) int64                                                                        `perm:"read"`
+		SetConcurrent            func(ctx context.Context, concurrent int64) error                                                      `perm:"admin"`
+		SlashFilterCheckBlock    func(ctx context.Context, blk cid.Cid) (string, error)                                                 `perm:"read"`
+		SlashFilterListBlocks    func(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error) `perm:"read"`
+		SyncCheckBad             func(ctx context.Context, tsk types.TipSetKey) (string, error)                                         `perm:"read"`
+		SyncCheckpoint           func(ctx context.Context, tsk types.TipSetKey) error                                                   `perm:"admin"`
+		SyncConsensusFaults      func(ctx context.Context) ([]*types.ConsensusFault, error)                                             `perm:"read"`
+		SyncMarkBad              func(ctx context.Context, tsk types.TipSetKey, reason string) error                                    `perm:"admin"`
+		SyncState                func(ctx context.Context) (*types.SyncState, error)                                                    `perm:"read"`
+		SyncSubmitBlock          func(ctx context.Context, blk *types.BlockMsg) error                                                   `perm:"write"`
+		SyncTargets              func(ctx context.Context) ([]*types.SyncTarget, error)                                                 `perm:"read"`
+		SyncUnmarkBad            func(ctx context.Context, tsk types.TipSetKey) error                                                   `perm:"admin"`
+		SyncValidateTipset       func(ctx context.Context, tsk types.TipSetKey) (*types.TipSetValidation, error)                        `perm:"admin"`
+		SyncerTracker            func(ctx context.Context) *types.TargetTracker                                                         `perm:"read"`
 	}
 }
 
@@ -744,12 +793,39 @@ func (s *ISyncerStruct) Concurrent(p0 context.Context) int64 { return s.Internal
 func (s *ISyncerStruct) SetConcurrent(p0 context.Context, p1 int64) error {
 	return s.Internal.SetConcurrent(p0, p1)
 }
+func (s *ISyncerStruct) SlashFilterCheckBlock(p0 context.Context, p1 cid.Cid) (string, error) {
+	return s.Internal.SlashFilterCheckBlock(p0, p1)
+}
+func (s *ISyncerStruct) SlashFilterListBlocks(p0 context.Context, p1 address.Address, p2, p3 abi.ChainEpoch) ([]*types.MinedBlock, error) {
+	return s.Internal.SlashFilterListBlocks(p0, p1, p2, p3)
+}
+func (s *ISyncerStruct) SyncCheckBad(p0 context.Context, p1 types.TipSetKey) (string, error) {
+	return s.Internal.SyncCheckBad(p0, p1)
+}
+func (s *ISyncerStruct) SyncCheckpoint(p0 context.Context, p1 types.TipSetKey) error {
+	return s.Internal.SyncCheckpoint(p0, p1)
+}
+func (s *ISyncerStruct) SyncConsensusFaults(p0 context.Context) ([]*types.ConsensusFault, error) {
+	return s.Internal.SyncConsensusFaults(p0)
+}
+func (s *ISyncerStruct) SyncMarkBad(p0 context.Context, p1 types.TipSetKey, p2 string) error {
+	return s.Internal.SyncMarkBad(p0, p1, p2)
+}
 func (s *ISyncerStruct) SyncState(p0 context.Context) (*types.SyncState, error) {
 	return s.Internal.SyncState(p0)
 }
 func (s *ISyncerStruct) SyncSubmitBlock(p0 context.Context, p1 *types.BlockMsg) error {
 	return s.Internal.SyncSubmitBlock(p0, p1)
 }
+func (s *ISyncerStruct) SyncTargets(p0 context.Context) ([]*types.SyncTarget, error) {
+	return s.Internal.SyncTargets(p0)
+}
+func (s *ISyncerStruct) SyncUnmarkBad(p0 context.Context, p1 types.TipSetKey) error {
+	return s.Internal.SyncUnmarkBad(p0, p1)
+}
+func (s *ISyncerStruct) SyncValidateTipset(p0 context.Context, p1 types.TipSetKey) (*types.TipSetValidation, error) {
+	return s.Internal.SyncValidateTipset(p0, p1)
+}
 func (s *ISyncerStruct) SyncerTracker(p0 context.Context) *types.TargetTracker {
 	return s.Internal.SyncerTracker(p0)
 }
@@ -820,12 +896,20 @@ func (s *IWalletStruct) WalletState(p0 context.Context) int { return s.Internal.
 
 type ICommonStruct struct {
 	Internal struct {
-		NodeStatus func(ctx context.Context, inclChainStatus bool) (types.NodeStatus, error) `perm:"read"`
-		StartTime  func(context.Context) (time.Time, error)                                  `perm:"read"`
-		Version    func(ctx context.Context) (types.Version, error)                          `perm:"read"`
+		ConvertBlockstore func(ctx context.Context, engine string) error                            `perm:"admin"`
+		CreateBackup      func(ctx context.Context, fpath string) error                             `perm:"admin"`
+		NodeStatus        func(ctx context.Context, inclChainStatus bool) (types.NodeStatus, error) `perm:"read"`
+		StartTime         func(context.Context) (time.Time, error)                                  `perm:"read"`
+		Version           func(ctx context.Context) (types.Version, error)                          `perm:"read"`
 	}
 }
 
+func (s *ICommonStruct) ConvertBlockstore(p0 context.Context, p1 string) error {
+	return s.Internal.ConvertBlockstore(p0, p1)
+}
+func (s *ICommonStruct) CreateBackup(p0 context.Context, p1 string) error {
+	return s.Internal.CreateBackup(p0, p1)
+}
 func (s *ICommonStruct) NodeStatus(p0 context.Context, p1 bool) (types.NodeStatus, error) {
 	return s.Internal.NodeStatus(p0, p1)
 }
@@ -3,7 +3,10 @@ package v1
 import (
 	"context"
 
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/venus/venus-shared/types"
 )
@@ -16,4 +19,27 @@ type ISyncer interface {
 	ChainTipSetWeight(ctx context.Context, tsk types.TipSetKey) (big.Int, error) //perm:read
 	SyncSubmitBlock(ctx context.Context, blk *types.BlockMsg) error              //perm:write
 	SyncState(ctx context.Context) (*types.SyncState, error)                     //perm:read
+	// SyncTargets returns the candidate heads of the syncer, the heaviest first, followed by the targets whose
+	// sync failed recently, with their weight, peers and state, and why they win or lose against the current head
+	SyncTargets(ctx context.Context) ([]*types.SyncTarget, error) //perm:read
+	// SyncMarkBad marks a tipset bad for the given reason, the syncer refuses to sync to it and to its descendants
+	SyncMarkBad(ctx context.Context, tsk types.TipSetKey, reason string) error //perm:admin
+	// SyncUnmarkBad removes a tipset from the bad tipsets
+	SyncUnmarkBad(ctx context.Context, tsk types.TipSetKey) error //perm:admin
+	// SyncCheckBad returns the reason a tipset was marked bad, or an empty string if it isn't bad
+	SyncCheckBad(ctx context.Context, tsk types.TipSetKey) (string, error) //perm:read
+	// SyncCheckpoint pins a tipset as final: the syncer refuses any chain that doesn't include it,
+	// and the head is switched to it if it isn't in the chain of the current head
+	SyncCheckpoint(ctx context.Context, tsk types.TipSetKey) error //perm:admin
+	// SyncValidateTipset runs the checks of the syncer on a tipset, re-executes its parent state transition
+	// and reports the result, without changing the head, the bad tipsets or the computed states
+	SyncValidateTipset(ctx context.Context, tsk types.TipSetKey) (*types.TipSetValidation, error) //perm:admin
+	// SyncConsensusFaults returns the consensus faults found in the block headers received from the network, most recent first
+	SyncConsensusFaults(ctx context.Context) ([]*types.ConsensusFault, error) //perm:read
+	// SlashFilterListBlocks lists the mined blocks recorded by the slash filter for the miner, or for all the
+	// miners if it is empty, between the epochs from and to included, a negative to means no upper bound
+	SlashFilterListBlocks(ctx context.Context, miner address.Address, from, to abi.ChainEpoch) ([]*types.MinedBlock, error) //perm:read
+	// SlashFilterCheckBlock checks a block against the blocks recorded by the slash filter, and returns the
+	// consensus fault it would trigger or an empty string
+	SlashFilterCheckBlock(ctx context.Context, blk cid.Cid) (string, error) //perm:read
 }
@@ -131,6 +131,7 @@ type BadgerBlockstore struct {
 var (
 	_ blockstore.Blockstore = (*BadgerBlockstore)(nil)
 	_ blockstore.Viewer     = (*BadgerBlockstore)(nil)
+	_ BlockstoreGC          = (*BadgerBlockstore)(nil)
 	_ io.Closer             = (*BadgerBlockstore)(nil)
 )
 
@@ -377,17 +378,23 @@ func (b *BadgerBlockstore) DeleteMany(ctx context.Context, cids []cid.Cid) error
 		return ErrBlockstoreClosed
 	}
 
+	batch := b.DB.NewWriteBatch()
+	defer batch.Cancel()
+
+	keys := make([]string, 0, len(cids))
 	for _, cid := range cids {
 		key := b.ConvertKey(cid)
-		if err := b.DB.Update(func(txn *badger.Txn) error {
-			err := txn.Delete(key.Bytes())
-			if err == nil {
-				b.cache.Remove(key.String())
-			}
-			return err
-		}); err != nil {
+		if err := batch.Delete(key.Bytes()); err != nil {
 			return err
 		}
+		keys = append(keys, key.String())
+	}
+
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("failed to delete blocks from badger blockstore: %w", err)
+	}
+	for _, key := range keys {
+		b.cache.Remove(key)
 	}
 	return nil
 }
@@ -400,6 +407,10 @@ func (b *BadgerBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, err
 
 	txn := b.DB.NewTransaction(false)
 	opts := badger.IteratorOptions{PrefetchSize: 100}
+	if b.keyTransform.Prefix.String() != "/" {
+		// only iterate over the keys of blocks
+		opts.Prefix = append(b.keyTransform.Prefix.Bytes(), '/')
+	}
 	iter := txn.NewIterator(opts)
 
 	ch := make(chan cid.Cid)
@@ -417,9 +428,9 @@ func (b *BadgerBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, err
 				// open iterators will run even after the database is closed...
 				return // closing, yield.
 			}
-			k := iter.Item().Key()
+			k := b.keyTransform.InvertKey(datastore.RawKey(string(iter.Item().Key())))
 			// need to convert to key.Key using key.KeyFromDsKey.
-			bk, err := dshelp.BinaryFromDsKey(datastore.RawKey(string(k)))
+			bk, err := dshelp.BinaryFromDsKey(k)
 			if err != nil {
 				log.Warnf("error parsing key from binary: %s", err)
 				continue
@@ -436,6 +447,48 @@ func (b *BadgerBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, err
 	return ch, nil
 }
 
+// CollectGarbage implements BlockstoreGC, it reclaims the space of deleted
+// blocks by rewriting badger value log files.
+func (b *BadgerBlockstore) CollectGarbage(options ...BlockstoreGCOption) error {
+	if atomic.LoadInt64(&b.state) != stateOpen {
+		return ErrBlockstoreClosed
+	}
+
+	var opts BlockstoreGCOptions
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.FullGC {
+		// compact the LSM tree first, so that deleted keys no longer
+		// reference their values in the value log.
+		if err := b.DB.Flatten(4); err != nil {
+			return fmt.Errorf("failed to flatten badger blockstore: %w", err)
+		}
+	}
+
+	// run value log gc until there is nothing left to rewrite.
+	var err error
+	for err == nil {
+		err = b.DB.RunValueLogGC(0.125)
+	}
+	if err == badger.ErrNoRewrite {
+		return nil
+	}
+	return err
+}
+
+// Sync flushes all pending writes of the blockstore to disk.
+func (b *BadgerBlockstore) Sync() error {
+	if atomic.LoadInt64(&b.state) != stateOpen {
+		return ErrBlockstoreClosed
+	}
+
+	return b.DB.Sync()
+}
+
 // HashOnRead implements blockstore.HashOnRead. It is not supported by this
 // blockstore.
 func (b *BadgerBlockstore) HashOnRead(_ bool) {
@@ -0,0 +1,109 @@
+package blockstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+)
+
+// LazyFetchTimeout is the time an object missing from the local store is searched on the network
+// before it is reported as not found.
+var LazyFetchTimeout = time.Minute
+
+// BlockGetter fetches objects from the network, like bitswap.
+type BlockGetter interface {
+	GetBlock(ctx context.Context, c cid.Cid) (blocks.Block, error)
+}
+
+// LazyStore is a local blockstore reading the objects missing from it through a BlockGetter,
+// which is set once the network is up. It lets a node which only synced the headers of the
+// chain fetch the state lazily as it is touched. The objects are missing until the getter is set.
+type LazyStore struct {
+	*ReadThroughStore
+	source *getterSource
+}
+
+var _ Blockstore = (*LazyStore)(nil)
+
+// NewLazyStore creates a blockstore fetching the objects missing from local from the network.
+func NewLazyStore(local Blockstore) *LazyStore {
+	src := &getterSource{}
+	return &LazyStore{
+		ReadThroughStore: NewReadThroughStore(local, src),
+		source:           src,
+	}
+}
+
+// SetGetter sets the getter the missing objects are fetched from.
+func (s *LazyStore) SetGetter(getter BlockGetter) {
+	s.source.lk.Lock()
+	defer s.source.lk.Unlock()
+	s.source.getter = getter
+}
+
+// Has implements blockstore.Has, it only checks the local store: the writers check whether
+// the objects they write exist, which must not wait for the network.
+func (s *LazyStore) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	return s.Local().Has(ctx, c)
+}
+
+// GetSize implements blockstore.GetSize, an object missing from the local store is fetched.
+func (s *LazyStore) GetSize(ctx context.Context, c cid.Cid) (int, error) {
+	size, err := s.Local().GetSize(ctx, c)
+	if err == nil || !ipld.IsNotFound(err) {
+		return size, err
+	}
+	blk, err := s.Get(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	return len(blk.RawData()), nil
+}
+
+// getterSource is the ReadThroughSource of a LazyStore.
+type getterSource struct {
+	lk     sync.RWMutex
+	getter BlockGetter
+}
+
+func (g *getterSource) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	_, err := g.Get(ctx, c)
+	if ipld.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (g *getterSource) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	g.lk.RLock()
+	getter := g.getter
+	g.lk.RUnlock()
+	if getter == nil {
+		return nil, ipld.ErrNotFound{Cid: c}
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, LazyFetchTimeout)
+	defer cancel()
+	blk, err := getter.GetBlock(fetchCtx, c)
+	if err != nil {
+		// the object couldn't be found on the network in time
+		if ctx.Err() == nil && fetchCtx.Err() != nil {
+			log.Warnf("failed to fetch %s from the network: %v", c, err)
+			return nil, ipld.ErrNotFound{Cid: c}
+		}
+		return nil, err
+	}
+	return blk, nil
+}
+
+func (g *getterSource) GetSize(ctx context.Context, c cid.Cid) (int, error) {
+	blk, err := g.Get(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	return len(blk.RawData()), nil
+}
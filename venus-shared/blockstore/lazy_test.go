@@ -0,0 +1,84 @@
+package blockstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	block "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+type storeGetter struct {
+	bs Blockstore
+}
+
+func (g storeGetter) GetBlock(ctx context.Context, c cid.Cid) (block.Block, error) {
+	blk, err := g.bs.Get(ctx, c)
+	if ipld.IsNotFound(err) {
+		// like bitswap, wait until the object is found
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return blk, err
+}
+
+func TestLazyStore(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	timeout := LazyFetchTimeout
+	LazyFetchTimeout = 0
+	defer func() { LazyFetchTimeout = timeout }()
+
+	remote := NewTemporarySync()
+	local := NewTemporarySync()
+	lazy := NewLazyStore(local)
+
+	tb1 := block.NewBlock([]byte("remote"))
+	require.NoError(t, remote.Put(ctx, tb1))
+
+	// the objects are missing until the getter is set
+	_, err := lazy.Get(ctx, tb1.Cid())
+	require.True(t, ipld.IsNotFound(err))
+	has, err := lazy.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+
+	lazy.SetGetter(storeGetter{bs: remote})
+	// Has doesn't fetch the missing objects
+	has, err = lazy.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+	blk, err := lazy.Get(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.Equal(t, tb1.RawData(), blk.RawData())
+	// the fetched object is cached in the local store
+	has, err = lazy.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+	has, err = local.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+	sz, err := lazy.GetSize(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.Equal(t, 6, sz)
+
+	// an object which can't be found in time is not found
+	tb2 := block.NewBlock([]byte("missing"))
+	_, err = lazy.Get(ctx, tb2.Cid())
+	require.True(t, ipld.IsNotFound(err))
+	has, err = lazy.Has(ctx, tb2.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// writes only go to the local store
+	tb3 := block.NewBlock([]byte("local"))
+	require.NoError(t, lazy.Put(ctx, tb3))
+	has, err = remote.Has(ctx, tb3.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+}
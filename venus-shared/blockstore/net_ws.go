@@ -3,8 +3,13 @@ package blockstore
 import (
 	"bytes"
 	"context"
+	"io"
+	"net/http"
+	"sync"
 
 	"github.com/gorilla/websocket"
+	"github.com/ipfs/go-cid"
+	blocks "github.com/ipfs/go-libipfs/blocks"
 	"github.com/libp2p/go-msgio"
 	"golang.org/x/xerrors"
 )
@@ -98,3 +103,101 @@ func HandleNetBstoreWS(ctx context.Context, bs Blockstore, wc *websocket.Conn) *
 func NewNetworkStoreWS(wc *websocket.Conn) *NetworkStore {
 	return NewNetworkStore(wsConnToMio(wc))
 }
+
+// WSNetworkStore is a client of a blockstore served over NetRPC on a websocket. The connection
+// is established on first use, and again on the next use after it is lost.
+type WSNetworkStore struct {
+	url    string
+	header http.Header
+
+	lk sync.Mutex
+	ns *NetworkStore
+	wc *websocket.Conn
+}
+
+var (
+	_ ReadThroughSource = (*WSNetworkStore)(nil)
+	_ io.Closer         = (*WSNetworkStore)(nil)
+)
+
+// NewWSNetworkStore creates a client of the blockstore served at url, header is sent with the
+// websocket handshake and usually holds the authorization token.
+func NewWSNetworkStore(url string, header http.Header) *WSNetworkStore {
+	return &WSNetworkStore{
+		url:    url,
+		header: header,
+	}
+}
+
+func (s *WSNetworkStore) store(ctx context.Context) (*NetworkStore, error) {
+	s.lk.Lock()
+	if s.ns != nil {
+		ns := s.ns
+		s.lk.Unlock()
+		return ns, nil
+	}
+
+	wc, resp, err := websocket.DefaultDialer.DialContext(ctx, s.url, s.header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		s.lk.Unlock()
+		return nil, xerrors.Errorf("dialing remote blockstore %s: %w", s.url, err)
+	}
+	ns := NewNetworkStoreWS(wc)
+	s.ns, s.wc = ns, wc
+	s.lk.Unlock()
+
+	// the callback runs right away if the connection is already closed, so s.lk must be released
+	ns.OnClose(func() {
+		s.lk.Lock()
+		defer s.lk.Unlock()
+		if s.ns == ns {
+			log.Warnf("connection to remote blockstore %s lost", s.url)
+			s.ns, s.wc = nil, nil
+		}
+	})
+	return ns, nil
+}
+
+// Has implements blockstore.Has.
+func (s *WSNetworkStore) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	ns, err := s.store(ctx)
+	if err != nil {
+		return false, err
+	}
+	return ns.Has(ctx, c)
+}
+
+// Get implements blockstore.Get.
+func (s *WSNetworkStore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	ns, err := s.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ns.Get(ctx, c)
+}
+
+// GetSize implements blockstore.GetSize.
+func (s *WSNetworkStore) GetSize(ctx context.Context, c cid.Cid) (int, error) {
+	ns, err := s.store(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return ns.GetSize(ctx, c)
+}
+
+// Close closes the connection to the remote blockstore.
+func (s *WSNetworkStore) Close() error {
+	s.lk.Lock()
+	wc := s.wc
+	s.ns, s.wc = nil, nil
+	s.lk.Unlock()
+
+	if wc == nil {
+		return nil
+	}
+	// the network store shuts down once its connection is closed
+	return wc.Close()
+}
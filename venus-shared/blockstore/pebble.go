@@ -0,0 +1,302 @@
+package blockstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/ipfs/go-cid"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	mh "github.com/multiformats/go-multihash"
+)
+
+// PebbleBlockstore is a blockstore backed by a pebble database. Objects are keyed by the
+// multihash of their cid, the database only holds objects.
+//
+// Unlike badger, pebble keeps the values in its LSM tree, so deleted objects are reclaimed by
+// the regular compactions and there is no value log to collect.
+type PebbleBlockstore struct {
+	DB *pebble.DB
+
+	// state is guarded by atomic.
+	state int64
+
+	// viewersLk orders the registration of the operations in flight with Close, which waits for
+	// them before closing the database; closing unblocks the iterations of AllKeysChan.
+	viewersLk sync.Mutex
+	viewers   sync.WaitGroup
+	closing   chan struct{}
+}
+
+var (
+	_ blockstore.Blockstore = (*PebbleBlockstore)(nil)
+	_ blockstore.Viewer     = (*PebbleBlockstore)(nil)
+	_ BlockstoreGC          = (*PebbleBlockstore)(nil)
+	_ io.Closer             = (*PebbleBlockstore)(nil)
+)
+
+// OpenPebble opens or creates the pebble blockstore in path.
+func OpenPebble(path string, readonly bool) (*PebbleBlockstore, error) {
+	db, err := pebble.Open(path, &pebble.Options{
+		ReadOnly: readonly,
+		Logger:   log,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pebble blockstore: %w", err)
+	}
+	return &PebbleBlockstore{DB: db, closing: make(chan struct{})}, nil
+}
+
+// access registers an operation in flight, it must be released with done. It fails once the
+// store is closing.
+func (b *PebbleBlockstore) access() error {
+	b.viewersLk.Lock()
+	defer b.viewersLk.Unlock()
+
+	if atomic.LoadInt64(&b.state) != stateOpen {
+		return ErrBlockstoreClosed
+	}
+	b.viewers.Add(1)
+	return nil
+}
+
+func (b *PebbleBlockstore) done() {
+	b.viewers.Done()
+}
+
+// Close closes the store once the operations in flight are done.
+func (b *PebbleBlockstore) Close() error {
+	b.viewersLk.Lock()
+	if !atomic.CompareAndSwapInt64(&b.state, stateOpen, stateClosing) {
+		b.viewersLk.Unlock()
+		return nil
+	}
+	close(b.closing)
+	b.viewersLk.Unlock()
+
+	b.viewers.Wait()
+
+	defer atomic.StoreInt64(&b.state, stateClosed)
+	return b.DB.Close()
+}
+
+// View implements blockstore.Viewer, fn must not retain the value.
+func (b *PebbleBlockstore) View(ctx context.Context, cid cid.Cid, fn func([]byte) error) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	val, closer, err := b.DB.Get(cid.Hash())
+	switch {
+	case err == nil:
+		defer closer.Close() // nolint: errcheck
+		return fn(val)
+	case errors.Is(err, pebble.ErrNotFound):
+		return ipld.ErrNotFound{Cid: cid}
+	default:
+		return fmt.Errorf("failed to view block from pebble blockstore: %w", err)
+	}
+}
+
+// Has implements blockstore.Has.
+func (b *PebbleBlockstore) Has(ctx context.Context, cid cid.Cid) (bool, error) {
+	err := b.View(ctx, cid, func([]byte) error { return nil })
+	switch {
+	case err == nil:
+		return true, nil
+	case ipld.IsNotFound(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Get implements blockstore.Get.
+func (b *PebbleBlockstore) Get(ctx context.Context, cid cid.Cid) (blocks.Block, error) {
+	if !cid.Defined() {
+		return nil, ipld.ErrNotFound{Cid: cid}
+	}
+
+	var val []byte
+	err := b.View(ctx, cid, func(data []byte) error {
+		val = append([]byte{}, data...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocks.NewBlockWithCid(val, cid)
+}
+
+// GetSize implements blockstore.GetSize.
+func (b *PebbleBlockstore) GetSize(ctx context.Context, cid cid.Cid) (int, error) {
+	size := -1
+	err := b.View(ctx, cid, func(data []byte) error {
+		size = len(data)
+		return nil
+	})
+	return size, err
+}
+
+// Put implements blockstore.Put.
+func (b *PebbleBlockstore) Put(ctx context.Context, block blocks.Block) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	if err := b.DB.Set(block.Cid().Hash(), block.RawData(), pebble.NoSync); err != nil {
+		return fmt.Errorf("failed to put block in pebble blockstore: %w", err)
+	}
+	return nil
+}
+
+// PutMany implements blockstore.PutMany.
+func (b *PebbleBlockstore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	batch := b.DB.NewBatch()
+	defer batch.Close() // nolint: errcheck
+
+	for _, block := range blks {
+		if err := batch.Set(block.Cid().Hash(), block.RawData(), nil); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(pebble.NoSync); err != nil {
+		return fmt.Errorf("failed to put blocks in pebble blockstore: %w", err)
+	}
+	return nil
+}
+
+// DeleteBlock implements blockstore.DeleteBlock.
+func (b *PebbleBlockstore) DeleteBlock(ctx context.Context, cid cid.Cid) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	if err := b.DB.Delete(cid.Hash(), pebble.NoSync); err != nil {
+		return fmt.Errorf("failed to delete block from pebble blockstore: %w", err)
+	}
+	return nil
+}
+
+// DeleteMany implements BatchDeleter.
+func (b *PebbleBlockstore) DeleteMany(ctx context.Context, cids []cid.Cid) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	batch := b.DB.NewBatch()
+	defer batch.Close() // nolint: errcheck
+
+	for _, cid := range cids {
+		if err := batch.Delete(cid.Hash(), nil); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(pebble.NoSync); err != nil {
+		return fmt.Errorf("failed to delete blocks from pebble blockstore: %w", err)
+	}
+	return nil
+}
+
+// AllKeysChan implements blockstore.AllKeysChan.
+func (b *PebbleBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	if err := b.access(); err != nil {
+		return nil, err
+	}
+
+	iter := b.DB.NewIter(nil)
+	ch := make(chan cid.Cid)
+	go func() {
+		defer b.done()
+		defer close(ch)
+		defer iter.Close() // nolint: errcheck
+
+		for iter.First(); iter.Valid(); iter.Next() {
+			// the key is only valid until the next call to Next
+			hash, err := mh.Cast(append([]byte{}, iter.Key()...))
+			if err != nil {
+				log.Warnf("error parsing key from pebble blockstore: %s", err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.closing:
+				return // closing, yield.
+			case ch <- cid.NewCidV1(cid.Raw, hash):
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// CollectGarbage implements BlockstoreGC, the deleted objects are reclaimed by the background
+// compactions, a full gc compacts the whole key space right away.
+func (b *PebbleBlockstore) CollectGarbage(options ...BlockstoreGCOption) error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	var opts BlockstoreGCOptions
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			return err
+		}
+	}
+	if !opts.FullGC {
+		return nil
+	}
+
+	iter := b.DB.NewIter(nil)
+	var first, last []byte
+	if iter.First() {
+		first = append(first, iter.Key()...)
+	}
+	if iter.Last() {
+		last = append(last, iter.Key()...)
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	if first == nil {
+		return nil
+	}
+	// the upper bound of Compact is exclusive
+	if err := b.DB.Compact(first, append(last, 0), true); err != nil {
+		return fmt.Errorf("failed to compact pebble blockstore: %w", err)
+	}
+	return nil
+}
+
+// Sync flushes all pending writes of the blockstore to disk.
+func (b *PebbleBlockstore) Sync() error {
+	if err := b.access(); err != nil {
+		return err
+	}
+	defer b.done()
+
+	return b.DB.Flush()
+}
+
+// HashOnRead implements blockstore.HashOnRead. It is not supported by this
+// blockstore.
+func (b *PebbleBlockstore) HashOnRead(_ bool) {
+	log.Warnf("called HashOnRead on pebble blockstore; function not supported; ignoring")
+}
@@ -0,0 +1,125 @@
+package blockstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	block "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestPebbleBlockstore(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	path := t.TempDir()
+
+	bs, err := OpenPebble(path, false)
+	require.NoError(t, err)
+
+	tb1 := block.NewBlock([]byte("aoeu"))
+	tb2 := block.NewBlock([]byte("snth"))
+	tb3 := block.NewBlock([]byte("gcrl"))
+
+	has, err := bs.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+	_, err = bs.Get(ctx, tb1.Cid())
+	require.True(t, ipld.IsNotFound(err))
+
+	require.NoError(t, bs.Put(ctx, tb1))
+	require.NoError(t, bs.PutMany(ctx, []block.Block{tb2, tb3}))
+
+	b, err := bs.Get(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.Equal(t, tb1.RawData(), b.RawData())
+	sz, err := bs.GetSize(ctx, tb2.Cid())
+	require.NoError(t, err)
+	require.Equal(t, 4, sz)
+	require.NoError(t, bs.View(ctx, tb3.Cid(), func(data []byte) error {
+		require.Equal(t, tb3.RawData(), data)
+		return nil
+	}))
+
+	keys := func() *cid.Set {
+		ch, err := bs.AllKeysChan(ctx)
+		require.NoError(t, err)
+		set := cid.NewSet()
+		for c := range ch {
+			set.Add(c)
+		}
+		return set
+	}
+	require.Equal(t, 3, keys().Len())
+
+	require.NoError(t, bs.DeleteBlock(ctx, tb1.Cid()))
+	require.NoError(t, bs.DeleteMany(ctx, []cid.Cid{tb2.Cid()}))
+	require.NoError(t, bs.CollectGarbage(WithFullGC(true)))
+	all := keys()
+	require.Equal(t, 1, all.Len())
+	require.True(t, all.Has(cid.NewCidV1(cid.Raw, tb3.Cid().Hash())))
+	require.NoError(t, bs.Close())
+
+	// the objects are persisted
+	bs, err = OpenPebble(path, true)
+	require.NoError(t, err)
+	has, err = bs.Has(ctx, tb3.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Error(t, bs.Put(ctx, tb1))
+	require.NoError(t, bs.Close())
+
+	_, err = bs.Get(ctx, tb3.Cid())
+	require.ErrorIs(t, err, ErrBlockstoreClosed)
+}
+
+func TestPebbleBlockstoreCloseWaitsForViewers(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	bs, err := OpenPebble(t.TempDir(), false)
+	require.NoError(t, err)
+	tb := block.NewBlock([]byte("aoeu"))
+	require.NoError(t, bs.Put(ctx, tb))
+
+	viewing, release := make(chan struct{}), make(chan struct{})
+	viewed := make(chan error, 1)
+	go func() {
+		viewed <- bs.View(ctx, tb.Cid(), func(data []byte) error {
+			close(viewing)
+			<-release
+			// the value is still valid, the database is not closed yet
+			require.Equal(t, tb.RawData(), data)
+			return nil
+		})
+	}()
+	<-viewing
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- bs.Close()
+	}()
+	select {
+	case <-closed:
+		t.Fatal("closed with a view in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	// no new operation is accepted while closing
+	require.ErrorIs(t, bs.Put(ctx, tb), ErrBlockstoreClosed)
+
+	close(release)
+	require.NoError(t, <-viewed)
+	require.NoError(t, <-closed)
+
+	// an abandoned iteration doesn't block Close
+	bs, err = OpenPebble(t.TempDir(), false)
+	require.NoError(t, err)
+	require.NoError(t, bs.PutMany(ctx, []block.Block{tb, block.NewBlock([]byte("snth"))}))
+	_, err = bs.AllKeysChan(ctx)
+	require.NoError(t, err)
+	require.NoError(t, bs.Close())
+}
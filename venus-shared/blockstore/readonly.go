@@ -0,0 +1,37 @@
+package blockstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ipfs/go-cid"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+)
+
+// ErrReadOnly is returned when writing to a read-only blockstore.
+var ErrReadOnly = errors.New("blockstore is read-only")
+
+type readOnlyBlockstore struct {
+	Blockstore
+}
+
+// NewReadOnlyBlockstore returns a view of bs which rejects the writes and the deletions.
+func NewReadOnlyBlockstore(bs Blockstore) Blockstore {
+	return &readOnlyBlockstore{Blockstore: bs}
+}
+
+func (bs *readOnlyBlockstore) Put(context.Context, blocks.Block) error {
+	return ErrReadOnly
+}
+
+func (bs *readOnlyBlockstore) PutMany(context.Context, []blocks.Block) error {
+	return ErrReadOnly
+}
+
+func (bs *readOnlyBlockstore) DeleteBlock(context.Context, cid.Cid) error {
+	return ErrReadOnly
+}
+
+func (bs *readOnlyBlockstore) DeleteMany(context.Context, []cid.Cid) error {
+	return ErrReadOnly
+}
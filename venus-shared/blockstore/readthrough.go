@@ -0,0 +1,159 @@
+package blockstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+)
+
+// ReadThroughSource is the remote tier of a ReadThroughStore.
+type ReadThroughSource interface {
+	Has(ctx context.Context, c cid.Cid) (bool, error)
+	Get(ctx context.Context, c cid.Cid) (blocks.Block, error)
+	GetSize(ctx context.Context, c cid.Cid) (int, error)
+}
+
+// ReadThroughStore is a local blockstore backed by a remote source. All writes go to the local
+// store, the objects missing from it are read from the remote source, verified against their
+// cid and cached in the local store.
+type ReadThroughStore struct {
+	local  Blockstore
+	remote ReadThroughSource
+}
+
+var (
+	_ Blockstore   = (*ReadThroughStore)(nil)
+	_ BlockstoreGC = (*ReadThroughStore)(nil)
+	_ io.Closer    = (*ReadThroughStore)(nil)
+)
+
+// NewReadThroughStore creates a blockstore reading through local to remote.
+func NewReadThroughStore(local Blockstore, remote ReadThroughSource) *ReadThroughStore {
+	return &ReadThroughStore{
+		local:  local,
+		remote: remote,
+	}
+}
+
+// Local returns the local store.
+func (s *ReadThroughStore) Local() Blockstore {
+	return s.local
+}
+
+// fetch reads c from the remote source and caches it in the local store.
+func (s *ReadThroughStore) fetch(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	blk, err := s.remote.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := c.Prefix().Sum(blk.RawData())
+	if err != nil {
+		return nil, err
+	}
+	if !sum.Equals(c) {
+		return nil, fmt.Errorf("remote object %s doesn't match its cid", c)
+	}
+
+	if err := s.local.Put(ctx, blk); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// Has implements blockstore.Has.
+func (s *ReadThroughStore) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	has, err := s.local.Has(ctx, c)
+	if err != nil || has {
+		return has, err
+	}
+	return s.remote.Has(ctx, c)
+}
+
+// Get implements blockstore.Get.
+func (s *ReadThroughStore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	blk, err := s.local.Get(ctx, c)
+	if err == nil || !ipld.IsNotFound(err) {
+		return blk, err
+	}
+	return s.fetch(ctx, c)
+}
+
+// GetSize implements blockstore.GetSize.
+func (s *ReadThroughStore) GetSize(ctx context.Context, c cid.Cid) (int, error) {
+	size, err := s.local.GetSize(ctx, c)
+	if err == nil || !ipld.IsNotFound(err) {
+		return size, err
+	}
+	return s.remote.GetSize(ctx, c)
+}
+
+// View implements blockstore.Viewer.
+func (s *ReadThroughStore) View(ctx context.Context, c cid.Cid, callback func([]byte) error) error {
+	err := s.local.View(ctx, c, callback)
+	if err == nil || !ipld.IsNotFound(err) {
+		return err
+	}
+
+	blk, err := s.fetch(ctx, c)
+	if err != nil {
+		return err
+	}
+	return callback(blk.RawData())
+}
+
+// Put implements blockstore.Put.
+func (s *ReadThroughStore) Put(ctx context.Context, blk blocks.Block) error {
+	return s.local.Put(ctx, blk)
+}
+
+// PutMany implements blockstore.PutMany.
+func (s *ReadThroughStore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	return s.local.PutMany(ctx, blks)
+}
+
+// DeleteBlock implements blockstore.DeleteBlock, the object is only deleted from the local store.
+func (s *ReadThroughStore) DeleteBlock(ctx context.Context, c cid.Cid) error {
+	return s.local.DeleteBlock(ctx, c)
+}
+
+// DeleteMany implements BatchDeleter, the objects are only deleted from the local store.
+func (s *ReadThroughStore) DeleteMany(ctx context.Context, cids []cid.Cid) error {
+	return s.local.DeleteMany(ctx, cids)
+}
+
+// AllKeysChan implements blockstore.AllKeysChan, it only returns the objects of the local store.
+func (s *ReadThroughStore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	return s.local.AllKeysChan(ctx)
+}
+
+// HashOnRead implements blockstore.HashOnRead, the remote objects are always verified.
+func (s *ReadThroughStore) HashOnRead(enabled bool) {
+	s.local.HashOnRead(enabled)
+}
+
+// CollectGarbage implements BlockstoreGC, it collects the garbage of the local store.
+func (s *ReadThroughStore) CollectGarbage(options ...BlockstoreGCOption) error {
+	if gc, ok := s.local.(BlockstoreGC); ok {
+		return gc.CollectGarbage(options...)
+	}
+	return nil
+}
+
+// Close closes the local store and the remote source.
+func (s *ReadThroughStore) Close() error {
+	var err error
+	if closer, ok := s.local.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if closer, ok := s.remote.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
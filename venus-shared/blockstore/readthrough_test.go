@@ -0,0 +1,97 @@
+package blockstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	block "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestReadThroughStore(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	remote := NewTemporarySync()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wc, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		HandleNetBstoreWS(ctx, NewReadOnlyBlockstore(remote), wc)
+	}))
+	defer srv.Close()
+
+	local := NewTemporarySync()
+	rts := NewReadThroughStore(local, NewWSNetworkStore("ws"+strings.TrimPrefix(srv.URL, "http"), nil))
+	defer func() { require.NoError(t, rts.Close()) }()
+
+	tb1 := block.NewBlock([]byte("remote"))
+	require.NoError(t, remote.Put(ctx, tb1))
+
+	has, err := rts.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+	sz, err := rts.GetSize(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.Equal(t, 6, sz)
+
+	// reading the object caches it in the local store
+	has, err = local.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+	b, err := rts.Get(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.Equal(t, tb1.RawData(), b.RawData())
+	has, err = local.Has(ctx, tb1.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+
+	// writes only go to the local store
+	tb2 := block.NewBlock([]byte("local"))
+	require.NoError(t, rts.Put(ctx, tb2))
+	has, err = remote.Has(ctx, tb2.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+	require.NoError(t, rts.View(ctx, tb2.Cid(), func(data []byte) error {
+		require.Equal(t, tb2.RawData(), data)
+		return nil
+	}))
+
+	_, err = rts.Get(ctx, block.NewBlock([]byte("missing")).Cid())
+	require.True(t, ipld.IsNotFound(err))
+}
+
+type badSource struct{}
+
+func (badSource) Has(context.Context, cid.Cid) (bool, error) { return true, nil }
+
+func (badSource) Get(_ context.Context, c cid.Cid) (block.Block, error) {
+	return block.NewBlockWithCid([]byte("tampered"), c)
+}
+
+func (badSource) GetSize(context.Context, cid.Cid) (int, error) { return 8, nil }
+
+func TestReadThroughStoreVerify(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	local := NewTemporarySync()
+	rts := NewReadThroughStore(local, badSource{})
+
+	c := block.NewBlock([]byte("expected")).Cid()
+	_, err := rts.Get(ctx, c)
+	require.Error(t, err)
+	has, err := local.Has(ctx, c)
+	require.NoError(t, err)
+	require.False(t, has)
+}
@@ -0,0 +1,506 @@
+package blockstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+)
+
+// ErrCompactionInProgress is returned by SplitStore.Compact when a compaction is already running.
+var ErrCompactionInProgress = errors.New("split store compaction in progress")
+
+// splitStoreBatchSize is the number of objects moved out of the hot store at once.
+const splitStoreBatchSize = 16384
+
+// LiveObjectWalker visits every object which must stay in the hot store,
+// cb must be called for each of them.
+type LiveObjectWalker func(ctx context.Context, cb func(cid.Cid) error) error
+
+// SplitStoreState is the compaction progress of a split store, it is persisted
+// next to the hot store so that an interrupted compaction is detected on restart.
+type SplitStoreState struct {
+	// BaseEpoch is the chain epoch at which the last compaction computed its live set.
+	BaseEpoch abi.ChainEpoch
+	// Compacting is set while a compaction is running.
+	Compacting bool
+}
+
+// SplitStore is a blockstore split in a hot and a cold tier. All writes go to
+// the hot store, reads fall through to the cold store. Compact moves the objects
+// which are no longer live out of the hot store, into the cold store or, when
+// the split store has no cold store, discards them.
+//
+// Compaction runs online: the objects written since the previous compaction
+// started, including the ones written while the compaction is running, are
+// protected and stay in the hot store whether they are live or not, like the
+// pending messages of the message pool or the blocks of a running sync. Objects
+// are only deleted from the hot store once they are persisted in the cold store,
+// so a crash at any point of a compaction leaves every object readable.
+//
+// The writes are only recorded in memory, the objects written before the split
+// store was opened are unknown, so the first compaction after it is opened
+// doesn't discard anything when there is no cold store.
+type SplitStore struct {
+	hot  Blockstore
+	cold Blockstore // nil when old objects are discarded
+
+	statePath   string
+	stateLk     sync.Mutex
+	state       SplitStoreState
+	interrupted bool
+
+	compacting int32
+
+	// txnLk serializes writes to the hot store against the deletion of
+	// objects. written records the objects written since the last compaction
+	// started, protected the objects written between the two last compactions
+	// while a compaction is running. tracked is set once written covers a full
+	// interval between two compactions.
+	txnLk     sync.RWMutex
+	protectLk sync.Mutex
+	written   map[string]struct{}
+	protected map[string]struct{}
+	tracked   bool
+}
+
+var (
+	_ Blockstore   = (*SplitStore)(nil)
+	_ BlockstoreGC = (*SplitStore)(nil)
+	_ io.Closer    = (*SplitStore)(nil)
+)
+
+// NewSplitStore creates a split store from a hot and an optional cold store, the
+// compaction state is persisted at statePath.
+func NewSplitStore(hot, cold Blockstore, statePath string) (*SplitStore, error) {
+	s := &SplitStore{
+		hot:       hot,
+		cold:      cold,
+		statePath: statePath,
+		written:   make(map[string]struct{}),
+	}
+
+	data, err := os.ReadFile(statePath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return nil, fmt.Errorf("failed to decode split store state: %w", err)
+		}
+	case os.IsNotExist(err):
+	default:
+		return nil, fmt.Errorf("failed to read split store state: %w", err)
+	}
+
+	if s.state.Compacting {
+		log.Warnf("split store compaction based at epoch %d was interrupted, it will be restarted", s.state.BaseEpoch)
+		s.interrupted = true
+	}
+
+	return s, nil
+}
+
+// Hot returns the hot store.
+func (s *SplitStore) Hot() Blockstore {
+	return s.hot
+}
+
+// Cold returns the cold store, it is nil when old objects are discarded.
+func (s *SplitStore) Cold() Blockstore {
+	return s.cold
+}
+
+// BaseEpoch returns the epoch of the last finished compaction.
+func (s *SplitStore) BaseEpoch() abi.ChainEpoch {
+	s.stateLk.Lock()
+	defer s.stateLk.Unlock()
+	return s.state.BaseEpoch
+}
+
+// Compacting returns true while a compaction is running.
+func (s *SplitStore) Compacting() bool {
+	return atomic.LoadInt32(&s.compacting) == 1
+}
+
+// ShouldCompact returns true if the last compaction was interrupted, or if the
+// chain advanced at least interval epochs since the last compaction.
+func (s *SplitStore) ShouldCompact(epoch, interval abi.ChainEpoch) bool {
+	if s.Compacting() {
+		return false
+	}
+
+	s.stateLk.Lock()
+	defer s.stateLk.Unlock()
+	return s.interrupted || epoch-s.state.BaseEpoch >= interval
+}
+
+// Compact moves every object of the hot store which is not visited by walk out
+// of the hot store. epoch is the chain epoch the live set is computed at.
+func (s *SplitStore) Compact(ctx context.Context, epoch abi.ChainEpoch, walk LiveObjectWalker) (err error) {
+	if !atomic.CompareAndSwapInt32(&s.compacting, 0, 1) {
+		return ErrCompactionInProgress
+	}
+	defer atomic.StoreInt32(&s.compacting, 0)
+	defer func() {
+		s.stateLk.Lock()
+		// retry as soon as possible if the compaction failed
+		s.interrupted = err != nil
+		s.stateLk.Unlock()
+	}()
+
+	start := time.Now()
+	log.Infow("split store compaction started", "epoch", epoch)
+
+	if err := s.updateState(func(st *SplitStoreState) { st.Compacting = true }); err != nil {
+		return err
+	}
+
+	tracked := s.beginProtect()
+	defer func() { s.endProtect(err != nil) }()
+
+	live := make(map[string]struct{})
+	if err := walk(ctx, func(c cid.Cid) error {
+		live[string(c.Hash())] = struct{}{}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to mark live objects: %w", err)
+	}
+	log.Infow("split store marked live objects", "count", len(live), "took", time.Since(start))
+
+	if s.cold == nil && !tracked {
+		log.Infow("split store discards nothing on the first compaction since it was opened, the objects written before are unknown")
+	} else {
+		moved, err := s.sweep(ctx, live)
+		if err != nil {
+			return fmt.Errorf("failed to move objects out of hot store: %w", err)
+		}
+		log.Infow("split store moved objects out of hot store", "count", moved, "discard", s.cold == nil, "took", time.Since(start))
+	}
+
+	if err := s.CollectGarbage(); err != nil {
+		log.Warnf("failed to collect garbage of hot store: %v", err)
+	}
+
+	if err := s.updateState(func(st *SplitStoreState) {
+		st.BaseEpoch = epoch
+		st.Compacting = false
+	}); err != nil {
+		return err
+	}
+
+	log.Infow("split store compaction finished", "epoch", epoch, "took", time.Since(start))
+	return nil
+}
+
+func (s *SplitStore) sweep(ctx context.Context, live map[string]struct{}) (int, error) {
+	ch, err := s.hot.AllKeysChan(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var moved int
+	batch := make([]cid.Cid, 0, splitStoreBatchSize)
+	for c := range ch {
+		if _, ok := live[string(c.Hash())]; ok {
+			continue
+		}
+		s.protectLk.Lock()
+		protected := s.isProtected(c)
+		s.protectLk.Unlock()
+		if protected {
+			continue
+		}
+
+		batch = append(batch, c)
+		if len(batch) < splitStoreBatchSize {
+			continue
+		}
+
+		n, err := s.moveBatch(ctx, batch)
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+		batch = batch[:0]
+	}
+	// AllKeysChan closes the channel early when the context is done
+	if ctx.Err() != nil {
+		return moved, ctx.Err()
+	}
+
+	n, err := s.moveBatch(ctx, batch)
+	return moved + n, err
+}
+
+func (s *SplitStore) moveBatch(ctx context.Context, batch []cid.Cid) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if s.cold != nil {
+		blks := make([]blocks.Block, 0, len(batch))
+		for _, c := range batch {
+			blk, err := s.hot.Get(ctx, c)
+			if err != nil {
+				if ipld.IsNotFound(err) {
+					continue
+				}
+				return 0, err
+			}
+			blks = append(blks, blk)
+		}
+
+		if err := s.cold.PutMany(ctx, blks); err != nil {
+			return 0, fmt.Errorf("failed to put objects to cold store: %w", err)
+		}
+		// objects must be persisted in the cold store before they are deleted from the hot store
+		if syncer, ok := s.cold.(interface{ Sync() error }); ok {
+			if err := syncer.Sync(); err != nil {
+				return 0, fmt.Errorf("failed to sync cold store: %w", err)
+			}
+		}
+	}
+
+	s.txnLk.Lock()
+	defer s.txnLk.Unlock()
+
+	dead := make([]cid.Cid, 0, len(batch))
+	s.protectLk.Lock()
+	for _, c := range batch {
+		if s.isProtected(c) {
+			continue
+		}
+		dead = append(dead, c)
+	}
+	s.protectLk.Unlock()
+
+	if err := s.hot.DeleteMany(ctx, dead); err != nil {
+		return 0, fmt.Errorf("failed to delete objects from hot store: %w", err)
+	}
+	return len(dead), nil
+}
+
+// beginProtect protects the objects written since the previous compaction, and starts recording the
+// writes anew. It returns whether the protected objects cover the full interval since the previous
+// compaction.
+func (s *SplitStore) beginProtect() bool {
+	s.protectLk.Lock()
+	defer s.protectLk.Unlock()
+
+	s.protected = s.written
+	s.written = make(map[string]struct{})
+	return s.tracked
+}
+
+// endProtect stops protecting the objects written before the compaction, they are protected by the
+// next compaction again if this one failed.
+func (s *SplitStore) endProtect(failed bool) {
+	s.protectLk.Lock()
+	defer s.protectLk.Unlock()
+	if failed {
+		for key := range s.protected {
+			s.written[key] = struct{}{}
+		}
+	} else {
+		s.tracked = true
+	}
+	s.protected = nil
+}
+
+// isProtected must be called with protectLk held.
+func (s *SplitStore) isProtected(c cid.Cid) bool {
+	key := string(c.Hash())
+	if _, ok := s.written[key]; ok {
+		return true
+	}
+	_, ok := s.protected[key]
+	return ok
+}
+
+func (s *SplitStore) protect(cids ...cid.Cid) {
+	s.protectLk.Lock()
+	defer s.protectLk.Unlock()
+	for _, c := range cids {
+		s.written[string(c.Hash())] = struct{}{}
+	}
+}
+
+func (s *SplitStore) updateState(update func(*SplitStoreState)) error {
+	s.stateLk.Lock()
+	defer s.stateLk.Unlock()
+
+	state := s.state
+	update(&state)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write split store state: %w", err)
+	}
+	if err := os.Rename(tmp, s.statePath); err != nil {
+		return fmt.Errorf("failed to write split store state: %w", err)
+	}
+	s.state = state
+	return nil
+}
+
+// Has implements blockstore.Has.
+func (s *SplitStore) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	has, err := s.hot.Has(ctx, c)
+	if err != nil || has || s.cold == nil {
+		return has, err
+	}
+	return s.cold.Has(ctx, c)
+}
+
+// Get implements blockstore.Get.
+func (s *SplitStore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	blk, err := s.hot.Get(ctx, c)
+	if err == nil || !ipld.IsNotFound(err) || s.cold == nil {
+		return blk, err
+	}
+	return s.cold.Get(ctx, c)
+}
+
+// GetSize implements blockstore.GetSize.
+func (s *SplitStore) GetSize(ctx context.Context, c cid.Cid) (int, error) {
+	size, err := s.hot.GetSize(ctx, c)
+	if err == nil || !ipld.IsNotFound(err) || s.cold == nil {
+		return size, err
+	}
+	return s.cold.GetSize(ctx, c)
+}
+
+// View implements blockstore.Viewer.
+func (s *SplitStore) View(ctx context.Context, c cid.Cid, callback func([]byte) error) error {
+	err := s.hot.View(ctx, c, callback)
+	if err == nil || !ipld.IsNotFound(err) || s.cold == nil {
+		return err
+	}
+	return s.cold.View(ctx, c, callback)
+}
+
+// Put implements blockstore.Put.
+func (s *SplitStore) Put(ctx context.Context, blk blocks.Block) error {
+	s.txnLk.RLock()
+	defer s.txnLk.RUnlock()
+
+	if err := s.hot.Put(ctx, blk); err != nil {
+		return err
+	}
+	s.protect(blk.Cid())
+	return nil
+}
+
+// PutMany implements blockstore.PutMany.
+func (s *SplitStore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	s.txnLk.RLock()
+	defer s.txnLk.RUnlock()
+
+	if err := s.hot.PutMany(ctx, blks); err != nil {
+		return err
+	}
+	cids := make([]cid.Cid, len(blks))
+	for i, blk := range blks {
+		cids[i] = blk.Cid()
+	}
+	s.protect(cids...)
+	return nil
+}
+
+// DeleteBlock implements blockstore.DeleteBlock.
+func (s *SplitStore) DeleteBlock(ctx context.Context, c cid.Cid) error {
+	if err := s.hot.DeleteBlock(ctx, c); err != nil {
+		return err
+	}
+	if s.cold == nil {
+		return nil
+	}
+	return s.cold.DeleteBlock(ctx, c)
+}
+
+// DeleteMany implements BatchDeleter.
+func (s *SplitStore) DeleteMany(ctx context.Context, cids []cid.Cid) error {
+	if err := s.hot.DeleteMany(ctx, cids); err != nil {
+		return err
+	}
+	if s.cold == nil {
+		return nil
+	}
+	return s.cold.DeleteMany(ctx, cids)
+}
+
+// AllKeysChan implements blockstore.AllKeysChan, objects which are both in the
+// hot and in the cold store may be returned twice.
+func (s *SplitStore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	hotCh, err := s.hot.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.cold == nil {
+		return hotCh, nil
+	}
+
+	coldCh, err := s.cold.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan cid.Cid)
+	go func() {
+		defer close(out)
+		for _, ch := range []<-chan cid.Cid{hotCh, coldCh} {
+			for c := range ch {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// HashOnRead implements blockstore.HashOnRead.
+func (s *SplitStore) HashOnRead(enabled bool) {
+	s.hot.HashOnRead(enabled)
+	if s.cold != nil {
+		s.cold.HashOnRead(enabled)
+	}
+}
+
+// CollectGarbage implements BlockstoreGC, it collects the garbage of the hot store.
+func (s *SplitStore) CollectGarbage(options ...BlockstoreGCOption) error {
+	if gc, ok := s.hot.(BlockstoreGC); ok {
+		return gc.CollectGarbage(options...)
+	}
+	return nil
+}
+
+// Close closes the hot and the cold store.
+func (s *SplitStore) Close() error {
+	var err error
+	if closer, ok := s.hot.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if closer, ok := s.cold.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
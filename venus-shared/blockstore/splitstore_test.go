@@ -0,0 +1,163 @@
+package blockstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/ipfs/go-cid"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	ipld "github.com/ipfs/go-ipld-format"
+	blocks "github.com/ipfs/go-libipfs/blocks"
+	"github.com/stretchr/testify/require"
+)
+
+func liveWalker(live ...blocks.Block) LiveObjectWalker {
+	return func(ctx context.Context, cb func(cid.Cid) error) error {
+		for _, blk := range live {
+			if err := cb(blk.Cid()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func requireHas(ctx context.Context, t *testing.T, bs Blockstore, has bool, blks ...blocks.Block) {
+	for _, blk := range blks {
+		ok, err := bs.Has(ctx, blk.Cid())
+		require.NoError(t, err)
+		require.Equal(t, has, ok, blk.Cid())
+	}
+}
+
+func TestSplitStoreCompact(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	hot, cold := NewMemory(), NewMemory()
+	statePath := filepath.Join(t.TempDir(), "splitstore.json")
+
+	// b0 and b1 are in the hot store before the split store is opened
+	require.NoError(t, hot.PutMany(ctx, []blocks.Block{b0, b1}))
+	ss, err := NewSplitStore(hot, cold, statePath)
+	require.NoError(t, err)
+
+	require.NoError(t, ss.Put(ctx, b2))
+	require.True(t, ss.ShouldCompact(10, 10))
+
+	// b2 is written since the split store was opened, b3 while the compaction is running, so
+	// both stay hot, only b1 which is unknown moves to the cold store
+	walk := func(ctx context.Context, cb func(cid.Cid) error) error {
+		require.NoError(t, ss.Put(ctx, b3))
+		return liveWalker(b0)(ctx, cb)
+	}
+	require.NoError(t, ss.Compact(ctx, 10, walk))
+	require.EqualValues(t, 10, ss.BaseEpoch())
+	require.False(t, ss.ShouldCompact(15, 10))
+	require.True(t, ss.ShouldCompact(20, 10))
+
+	requireHas(ctx, t, hot, true, b0, b2, b3)
+	requireHas(ctx, t, hot, false, b1)
+	requireHas(ctx, t, cold, true, b1)
+
+	// b2 is written before the previous compaction, b3 since it started
+	require.NoError(t, ss.Compact(ctx, 20, liveWalker(b0)))
+	requireHas(ctx, t, hot, true, b0, b3)
+	requireHas(ctx, t, hot, false, b1, b2)
+	requireHas(ctx, t, cold, true, b1, b2)
+
+	require.NoError(t, ss.Compact(ctx, 30, liveWalker(b0)))
+	requireHas(ctx, t, hot, true, b0)
+	requireHas(ctx, t, hot, false, b1, b2, b3)
+
+	// cold objects are still readable through the split store
+	for _, blk := range []blocks.Block{b0, b1, b2, b3} {
+		got, err := ss.Get(ctx, blk.Cid())
+		require.NoError(t, err)
+		require.Equal(t, blk.RawData(), got.RawData())
+	}
+
+	// the compaction state survives a restart
+	reopened, err := NewSplitStore(hot, cold, statePath)
+	require.NoError(t, err)
+	require.Equal(t, ss.BaseEpoch(), reopened.BaseEpoch())
+	require.False(t, reopened.ShouldCompact(35, 10))
+}
+
+func TestSplitStoreDiscard(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	hot := NewMemory()
+	require.NoError(t, hot.Put(ctx, b0))
+	ss, err := NewSplitStore(hot, nil, filepath.Join(t.TempDir(), "splitstore.json"))
+	require.NoError(t, err)
+
+	// the first compaction since the split store was opened discards nothing, b0 may have been
+	// written since the previous compaction before a restart
+	require.NoError(t, ss.PutMany(ctx, []blocks.Block{b1, b2}))
+	require.NoError(t, ss.Compact(ctx, 10, liveWalker(b2)))
+	requireHas(ctx, t, ss, true, b0, b1, b2)
+
+	// b1 is written before the previous compaction, b3 since it started
+	require.NoError(t, ss.Put(ctx, b3))
+	require.NoError(t, ss.Compact(ctx, 20, liveWalker(b2)))
+	requireHas(ctx, t, ss, false, b0, b1)
+	requireHas(ctx, t, ss, true, b2, b3)
+
+	_, err = ss.Get(ctx, b0.Cid())
+	require.True(t, ipld.IsNotFound(err))
+
+	// the objects written before a failed compaction stay protected by the next one
+	b4 := blocks.NewBlock([]byte("written before a failed compaction"))
+	require.NoError(t, ss.Put(ctx, b4))
+	failed := func(ctx context.Context, cb func(cid.Cid) error) error {
+		return errors.New("walk failed")
+	}
+	require.Error(t, ss.Compact(ctx, 30, failed))
+	require.True(t, ss.ShouldCompact(30, 10))
+	require.NoError(t, ss.Compact(ctx, 30, liveWalker(b2)))
+	requireHas(ctx, t, ss, false, b3)
+	requireHas(ctx, t, ss, true, b2, b4)
+
+	require.NoError(t, ss.Compact(ctx, 40, liveWalker(b2)))
+	requireHas(ctx, t, ss, false, b4)
+	requireHas(ctx, t, ss, true, b2)
+}
+
+func TestSplitStoreBadgerHotStore(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	opts, err := BadgerBlockstoreOptions(t.TempDir(), false)
+	require.NoError(t, err)
+	opts.Prefix = blockstore.BlockPrefix.String()
+	hot, err := Open(opts)
+	require.NoError(t, err)
+
+	// the objects in the hot store before the split store is opened aren't protected
+	require.NoError(t, hot.PutMany(ctx, []blocks.Block{b0, b1, b2}))
+	cold := NewMemory()
+	ss, err := NewSplitStore(hot, cold, filepath.Join(t.TempDir(), "splitstore.json"))
+	require.NoError(t, err)
+	defer ss.Close() //nolint:errcheck
+
+	require.NoError(t, ss.Compact(ctx, 10, liveWalker(b2)))
+
+	for _, blk := range []blocks.Block{b0, b1} {
+		has, err := hot.Has(ctx, blk.Cid())
+		require.NoError(t, err)
+		require.False(t, has)
+
+		got, err := ss.Get(ctx, blk.Cid())
+		require.NoError(t, err)
+		require.Equal(t, blk.RawData(), got.RawData())
+	}
+
+	has, err := hot.Has(ctx, b2.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+}
@@ -249,6 +249,8 @@ type MessageSendSpec struct {
 	MaxFee            abi.TokenAmount
 	GasOverEstimation float64
 	GasOverPremium    float64
+	// GasPremiumEstimator overrides the gas premium estimator of the message pool config, like "inclusion:0.95"
+	GasPremiumEstimator string
 }
 
 // Version provides various build-time information
@@ -352,6 +354,70 @@ type TargetTracker struct {
 	Buckets []*Target
 }
 
+// SyncTarget is a candidate head of the syncer, compared to the current head by the fork-choice rule
+type SyncTarget struct {
+	Head   TipSetKey
+	Height abi.ChainEpoch
+	// ParentWeight orders the targets in the syncer queue
+	ParentWeight BigInt
+	// Weight is the weight of the head, it is unknown until the parent state of the head is synced
+	Weight BigInt
+	// Source is the peer which announced the head and Sender the peer it was received from
+	Source peer.ID
+	Sender peer.ID
+	Stage  SyncStateStage
+	// Err is the error the sync failed with
+	Err string
+	// BadReason is the reason the head was marked bad
+	BadReason string
+	// Heavier is whether the head wins over the current head, Reason explains why it wins or loses
+	Heavier bool
+	Reason  string
+}
+
+// ConsensusFault is a consensus fault found in the block headers received from the network
+type ConsensusFault struct {
+	// Type is double-fork-mining, time-offset-mining or parent-grinding
+	Type  string
+	Miner address.Address
+	Epoch abi.ChainEpoch
+	// the blocks proving the fault, ordered by epoch
+	Block1 *BlockHeader
+	Block2 *BlockHeader
+	// Extra is the sibling of Block1 included in the parents of Block2 instead of it, for a
+	// parent-grinding fault
+	Extra    *BlockHeader `json:",omitempty"`
+	Detected time.Time
+	// Report is the cid of the ReportConsensusFault message reporting the fault, if it was reported
+	Report      *cid.Cid
+	ReportError string
+}
+
+// TipSetValidation is the report of the validation of a tipset against its parent
+type TipSetValidation struct {
+	TipSet TipSetKey
+	Height abi.ChainEpoch
+	// Valid is true if the state transition matches and all the blocks are valid
+	Valid bool
+
+	// the state and receipts roots computed by executing the parent, and the ones claimed by the blocks
+	ComputedStateRoot cid.Cid
+	ClaimedStateRoot  cid.Cid
+	ComputedReceipts  cid.Cid
+	ClaimedReceipts   cid.Cid
+	// StateTransitionError is set if the parent couldn't be executed
+	StateTransitionError string
+
+	Blocks []BlockValidation
+}
+
+// BlockValidation is the result of the validation of a block
+type BlockValidation struct {
+	Cid    cid.Cid
+	Miner  address.Address
+	Errors []string
+}
+
 type MsgGasCost struct {
 	Message            cid.Cid // Can be different than requested, in case it was replaced, but only gas values changed
 	GasUsed            abi.TokenAmount
@@ -446,3 +512,120 @@ type NodeChainStatus struct {
 	BlocksPerTipsetLast100      float64
 	BlocksPerTipsetLastFinality float64
 }
+
+type ChainPruneOpts struct {
+	// RetainState is the number of recent epochs whose state is kept, it must be at least one finality
+	RetainState abi.ChainEpoch
+	// DryRun only reports the objects that would be pruned without deleting them
+	DryRun bool
+}
+
+type ChainPruneProgress struct {
+	// Stage is one of list, mark, sweep, gc and done
+	Stage string
+	// Scanned is the number of objects of the state older than the retained epochs
+	Scanned uint64
+	// Marked is the number of live objects
+	Marked uint64
+	// Pruned is the number of objects deleted, or that would be deleted in a dry run
+	Pruned uint64
+	// PrunedBytes is the size of the objects deleted, or that would be reclaimed in a dry run
+	PrunedBytes uint64
+	Done        bool
+	Err         string
+}
+
+type ChainCheckOpts struct {
+	// Epochs is the number of epochs checked back from the tipset
+	Epochs abi.ChainEpoch
+	// Repair fetches the missing or corrupt objects from the network
+	Repair bool
+}
+
+type ChainCheckObject struct {
+	Cid cid.Cid
+	// Problem is either missing or corrupt
+	Problem  string
+	Repaired bool
+	// Err is the reason the object could not be repaired
+	Err string
+}
+
+type ChainCheckResult struct {
+	// From and To are the heights of the first and the last checked tipsets
+	From abi.ChainEpoch
+	To   abi.ChainEpoch
+	// Checked is the number of objects checked
+	Checked uint64
+	// Bad are the missing or corrupt objects
+	Bad []ChainCheckObject
+}
+
+type ChainVerifyRangeOpts struct {
+	// From and To are the heights of the lowest and the highest re-executed tipsets
+	From abi.ChainEpoch
+	To   abi.ChainEpoch
+	// Workers is the number of tipsets re-executed in parallel
+	Workers int
+}
+
+type ChainVerifyRangeProgress struct {
+	// Verified is the number of tipsets re-executed, out of Total
+	Verified int
+	Total    int
+	// Divergence is the lowest tipset whose execution doesn't match the chain, set once done
+	Divergence *StateDivergence
+	Done       bool
+	Err        string
+}
+
+// StateDivergence is a tipset whose execution doesn't match the state root or the receipts recorded
+// in its child
+type StateDivergence struct {
+	TipSet            TipSetKey
+	Height            abi.ChainEpoch
+	Child             TipSetKey
+	ComputedStateRoot cid.Cid
+	ClaimedStateRoot  cid.Cid
+	ComputedReceipts  cid.Cid
+	ClaimedReceipts   cid.Cid
+	// Error is the error of the execution, the computed roots are undefined if it is set
+	Error string
+	// ReceiptDiffs are the messages whose computed receipts differ from the recorded ones
+	ReceiptDiffs []ReceiptDiff
+}
+
+type ReceiptDiff struct {
+	Index   int
+	Message cid.Cid
+	// Computed or Claimed is nil when the receipt is missing
+	Computed *MessageReceipt
+	Claimed  *MessageReceipt
+}
+
+// MinedBlock is a block recorded by the slash filter when it was mined
+type MinedBlock struct {
+	Miner       address.Address
+	Epoch       abi.ChainEpoch
+	Cid         cid.Cid
+	ParentKey   TipSetKey
+	ParentEpoch abi.ChainEpoch
+}
+
+// ReorgEvent is a reorg of the chain: a head change to a tipset which doesn't descend from the previous head
+type ReorgEvent struct {
+	Time time.Time
+	// Depth is the number of epochs from the common ancestor to the old head
+	Depth abi.ChainEpoch
+
+	OldHead              TipSetKey
+	OldHeight            abi.ChainEpoch
+	NewHead              TipSetKey
+	NewHeight            abi.ChainEpoch
+	CommonAncestor       TipSetKey
+	CommonAncestorHeight abi.ChainEpoch
+
+	// Dropped are the tipsets reverted from the old head down, Added the tipsets applied up to the new head
+	Dropped []TipSetKey
+	Added   []TipSetKey
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
 )
 
@@ -22,6 +24,7 @@ const (
 	CheckStatusMessageNonce
 	CheckStatusMessageGetStateBalance
 	CheckStatusMessageBalance
+	CheckStatusMessagePolicy
 )
 
 type CheckStatus struct {
@@ -46,9 +49,86 @@ type MpoolChange int
 const (
 	MpoolAdd MpoolChange = iota
 	MpoolRemove
+	// MpoolBump is the replacement of a stuck local message by a message paying a bumped premium
+	MpoolBump
 )
 
 type MpoolUpdate struct {
 	Type    MpoolChange
 	Message *SignedMessage
 }
+
+// NonceGapReport compares the nonces of an address in the state, the message pool and the local messages.
+type NonceGapReport struct {
+	Address address.Address
+	// StateNonce is the nonce of the actor in the state of the head
+	StateNonce uint64
+	// PoolNonce is the nonce following the pending messages without gap from the state nonce
+	PoolNonce uint64
+	// SignerNonce is the nonce the local message signer assigns to the next message
+	SignerNonce uint64
+	// Pending are the nonces of the pending messages
+	Pending []uint64
+	// Local are the nonces of the local messages persisted by the message pool
+	Local []uint64
+	// Gaps are the missing nonces below the highest pending nonce or the signer nonce
+	Gaps []uint64
+	// Stranded are the pending messages behind a gap, they can't be included until the gap is filled
+	Stranded []cid.Cid
+	// Stale are the local messages below the state nonce, or replaced by another pending message
+	Stale []cid.Cid
+}
+
+// NonceGapFix is the result of the repair of the nonce gaps of an address.
+type NonceGapFix struct {
+	// Report is the diagnosis before the repair
+	Report *NonceGapReport
+	// Filled are the self-sends pushed to fill the gaps
+	Filled []cid.Cid
+	// Dropped are the stranded and stale messages dropped from the pool and the local messages
+	Dropped []cid.Cid
+}
+
+// MpoolSelectExplain explains the selection of the messages for a block on a tipset with a ticket quality.
+type MpoolSelectExplain struct {
+	BaseFee       BigInt
+	TicketQuality float64
+	// Greedy is set if the ticket quality is high enough for the messages to be selected greedily
+	Greedy   bool
+	Messages []*MsgSelectExplain
+}
+
+// MsgSelectExplain explains whether a pending message was selected.
+type MsgSelectExplain struct {
+	Cid      cid.Cid
+	From     address.Address
+	Nonce    uint64
+	Selected bool
+	// Reason is why the message wasn't selected
+	Reason string
+	// Chain is the position of the chain of the message among the chains of its sender, -1 if the
+	// message didn't make it into a chain
+	Chain int
+	// GasPerf and EffPerf are the gas performance and the effective performance of the chain
+	GasPerf float64
+	EffPerf float64
+}
+
+// GasBacktestResult reports how the premiums estimated by a gas premium estimator on the recent tipsets
+// would have fared in the tipsets which followed.
+type GasBacktestResult struct {
+	Estimator string
+	// Samples is the number of tipsets a premium was estimated on
+	Samples int
+	// Included is the number of premiums a later tipset included a premium as low as within MaxWait epochs
+	Included int
+	// InTarget is the number of premiums included within the targeted number of epochs
+	InTarget int
+	MaxWait  abi.ChainEpoch
+	// MeanLatency is the mean number of epochs the included premiums waited
+	MeanLatency float64
+	MeanPremium BigInt
+	// MeanOverpay is the mean of what the included premiums paid above the lowest premium of the tipset
+	// including them
+	MeanOverpay BigInt
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 )
 
 type MpoolConfig struct {
@@ -13,4 +14,50 @@ type MpoolConfig struct {
 	ReplaceByFeeRatio      Percent
 	PruneCooldown          time.Duration
 	GasLimitOverestimation float64
+	Policy                 MpoolPolicy
+	FeeBump                MpoolFeeBump
+	// GasPremiumEstimator is the estimator of the gas premiums, like "percentile:75", the median by default
+	GasPremiumEstimator string
+}
+
+// MpoolFeeBump replaces the local messages stuck in the message pool with messages paying a bumped premium
+type MpoolFeeBump struct {
+	// StuckEpochs is the number of epochs a local message stays pending before its fee is bumped, 0 disables the bumping
+	StuckEpochs abi.ChainEpoch
+	// MaxFee caps the fee of a bumped message like MessageSendSpec.MaxFee, the default max fee applies if it is zero
+	MaxFee abi.TokenAmount
+}
+
+// MpoolPolicy filters the messages added to the message pool, both the messages received from the network
+// and the messages pushed locally. The addresses of the lists match the addresses of the messages as they
+// are written, and the key address of the sender.
+type MpoolPolicy struct {
+	// SenderBlocklist rejects the messages from these addresses
+	SenderBlocklist []address.Address
+	// SenderAllowlist only admits the messages from these addresses if it isn't empty
+	SenderAllowlist []address.Address
+	// RecipientBlocklist rejects the messages to these addresses
+	RecipientBlocklist []address.Address
+	// RecipientAllowlist only admits the messages to these addresses if it isn't empty
+	RecipientAllowlist []address.Address
+	// Rules apply to the messages by actor code of the recipient and method, the first matching rule applies
+	Rules []MpoolPolicyRule
+	// SenderRate limits the messages admitted from each sender with a token bucket refilled by SenderRate
+	// messages per second and holding up to SenderBurst messages, 0 means no limit
+	SenderRate  float64
+	SenderBurst int
+}
+
+// MpoolPolicyRule matches messages by the actor code of the recipient and the method, and rejects them or
+// limits their admission rate per sender
+type MpoolPolicyRule struct {
+	// ActorCode is the name of the actor code of the recipient, like "multisig" or "evm", empty matches any actor
+	ActorCode string
+	// Methods are the method numbers matched, empty matches any method
+	Methods []abi.MethodNum
+	// Reject rejects the matched messages
+	Reject bool
+	// Rate and Burst limit the matched messages of each sender, like SenderRate and SenderBurst
+	Rate  float64
+	Burst int
 }